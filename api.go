@@ -1,9 +1,17 @@
 package slack
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/blendlabs/go-exception"
+	"github.com/blendlabs/go-request"
 )
 
 //--------------------------------------------------------------------------------
@@ -15,8 +23,8 @@ func (rtm *Client) AuthTest() (*AuthTestResponse, error) {
 	res := AuthTestResponse{}
 	err := NewExternalRequest().
 		AsPost().
-		WithScheme(APIScheme).
-		WithHost(APIEndpoint).
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
 		WithPath("api/auth.test").
 		WithPostData("token", rtm.Token).
 		JSON(&res)
@@ -36,8 +44,78 @@ func (rtm *Client) AuthTest() (*AuthTestResponse, error) {
 	return &res, nil
 }
 
+// BotsInfo returns a bot user.
+func (rtm *Client) BotsInfo(botID string) (*Bot, error) {
+	res := botsInfoResponse{}
+	err := NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/bots.info").
+		WithPostData("token", rtm.Token).
+		WithPostData("bot", botID).
+		JSON(&res)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsEmpty(res.Error) {
+		return nil, exception.New(res.Error)
+	}
+
+	if !res.OK {
+		return nil, exception.New("slack response `ok` is false.")
+	}
+
+	return res.Bot, nil
+}
+
+// ChannelsArchive archives a channel, removing it from ActiveChannels.
+func (rtm *Client) ChannelsArchive(channelID string) error {
+	if err := rtm.conversationUpdate("api/channels.archive", channelID); err != nil {
+		return err
+	}
+	rtm.removeActiveChannel(channelID)
+	return nil
+}
+
+// ChannelsCreate creates a channel, adding it to ActiveChannels (the caller joins the channels it creates).
+func (rtm *Client) ChannelsCreate(name string) (*Channel, error) {
+	res := channelsInfoResponse{}
+	err := NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/channels.create").
+		WithPostData("token", rtm.Token).
+		WithPostData("name", name).
+		JSON(&res)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsEmpty(res.Error) {
+		return nil, exception.New(res.Error)
+	}
+
+	if !res.OK {
+		return nil, exception.New("slack response `ok` is false.")
+	}
+
+	rtm.state.SetChannel(*res.Channel)
+	rtm.addActiveChannel(res.Channel.ID)
+	return res.Channel, nil
+}
+
 // ChannelsHistory returns the messages in a channel.
 func (rtm *Client) ChannelsHistory(channelID string, latest, oldest *time.Time, count int, unreads bool) (*ChannelsHistoryResponse, error) {
+	return rtm.history("api/channels.history", channelID, latest, oldest, count, unreads)
+}
+
+// history calls a channels, groups, im or mpim history method.
+func (rtm *Client) history(path, channelID string, latest, oldest *time.Time, count int, unreads bool) (*ChannelsHistoryResponse, error) {
 	unreadsValue := "0"
 	if unreads {
 		unreadsValue = "1"
@@ -54,20 +132,20 @@ func (rtm *Client) ChannelsHistory(channelID string, latest, oldest *time.Time,
 	res := ChannelsHistoryResponse{}
 	req := NewExternalRequest().
 		AsPost().
-		WithScheme(APIScheme).
-		WithHost(APIEndpoint).
-		WithPath("api/channels.history").
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath(path).
 		WithPostData("token", rtm.Token).
 		WithPostData("channel", channelID).
-		WithPostData("count", string(count)).
+		WithPostData("count", strconv.Itoa(count)).
 		WithPostData("unreads", unreadsValue)
 
 	if latest != nil {
-		req = req.WithPostData("latest", Timestamp{time: *latest}.String())
+		req = req.WithPostData("latest", historyBound(*latest))
 	}
 
 	if oldest != nil {
-		req = req.WithPostData("oldest", Timestamp{time: *latest}.String())
+		req = req.WithPostData("oldest", historyBound(*oldest))
 	}
 
 	err := req.JSON(&res)
@@ -86,13 +164,22 @@ func (rtm *Client) ChannelsHistory(channelID string, latest, oldest *time.Time,
 	return &res, nil
 }
 
+// historyBound formats a history call's `latest` or `oldest` bound, keeping microseconds so
+// a bound can fall between messages posted in the same second.
+func historyBound(t time.Time) string {
+	if t.Nanosecond() == 0 {
+		return Timestamp{time: t}.String()
+	}
+	return fmt.Sprintf("%d.%06d", t.Unix(), t.Nanosecond()/int(time.Microsecond))
+}
+
 // ChannelsInfo returns information about a given channelID.
 func (rtm *Client) ChannelsInfo(channelID string) (*Channel, error) {
 	res := channelsInfoResponse{}
 	err := NewExternalRequest().
 		AsPost().
-		WithScheme(APIScheme).
-		WithHost(APIEndpoint).
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
 		WithPath("api/channels.info").
 		WithPostData("token", rtm.Token).
 		WithPostData("channel", channelID).
@@ -113,13 +200,56 @@ func (rtm *Client) ChannelsInfo(channelID string) (*Channel, error) {
 	return res.Channel, nil
 }
 
+// ChannelsJoin joins a channel by name, creating it if it doesn't exist, and adds it to ActiveChannels.
+func (rtm *Client) ChannelsJoin(name string) (*Channel, error) {
+	res := channelsInfoResponse{}
+	err := NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/channels.join").
+		WithPostData("token", rtm.Token).
+		WithPostData("name", name).
+		JSON(&res)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsEmpty(res.Error) {
+		return nil, exception.New(res.Error)
+	}
+
+	if !res.OK {
+		return nil, exception.New("slack response `ok` is false.")
+	}
+
+	rtm.state.SetChannel(*res.Channel)
+	rtm.addActiveChannel(res.Channel.ID)
+	return res.Channel, nil
+}
+
+// ChannelsKick removes a user from a channel.
+func (rtm *Client) ChannelsKick(channelID, userID string) error {
+	return rtm.conversationUpdate("api/channels.kick", channelID, "user", userID)
+}
+
+// ChannelsLeave leaves a channel, removing it from ActiveChannels.
+func (rtm *Client) ChannelsLeave(channelID string) error {
+	if err := rtm.conversationUpdate("api/channels.leave", channelID); err != nil {
+		return err
+	}
+	rtm.removeActiveChannel(channelID)
+	return nil
+}
+
 // ChannelsList returns the list of channels available to the bot.
 func (rtm *Client) ChannelsList(excludeArchived bool) ([]Channel, error) {
-	res := channelsListResponse{}
+	res := ChannelsListResponse{}
 	req := NewExternalRequest().
 		AsPost().
-		WithScheme(APIScheme).
-		WithHost(APIEndpoint).
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
 		WithPath("api/channels.list").
 		WithPostData("token", rtm.Token)
 
@@ -144,14 +274,50 @@ func (rtm *Client) ChannelsList(excludeArchived bool) ([]Channel, error) {
 	return res.Channels, nil
 }
 
+// ChannelsListPage returns a page of the channels available to the bot; `cursor` is the `NextCursor` from a previous page.
+func (rtm *Client) ChannelsListPage(excludeArchived bool, limit int, cursor string) (*ChannelsListResponse, error) {
+	res := ChannelsListResponse{}
+	req := NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/channels.list").
+		WithPostData("token", rtm.Token)
+
+	if excludeArchived {
+		req = req.WithPostData("exclude_archived", "1")
+	}
+	if limit > 0 {
+		req = req.WithPostData("limit", strconv.Itoa(limit))
+	}
+	if !IsEmpty(cursor) {
+		req = req.WithPostData("cursor", cursor)
+	}
+
+	err := req.JSON(&res)
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsEmpty(res.Error) {
+		return nil, exception.New(res.Error)
+	}
+
+	if !res.OK {
+		return nil, exception.New("slack response `ok` is false.")
+	}
+
+	return &res, nil
+}
+
 // ChannelsMark marks a message.
 func (rtm *Client) ChannelsMark(channelID string, ts Timestamp) error {
 	res := basicResponse{}
 	err := NewExternalRequest().
 		AsPost().
-		WithScheme(APIScheme).
-		WithHost(APIEndpoint).
-		WithPath("api/chat.mark").
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/channels.mark").
 		WithPostData("token", rtm.Token).
 		WithPostData("channel", channelID).
 		WithPostData("ts", ts.String()).
@@ -171,13 +337,74 @@ func (rtm *Client) ChannelsMark(channelID string, ts Timestamp) error {
 	return nil
 }
 
+// ChannelsRename renames a channel; the returned channel only has its id, name and created time.
+func (rtm *Client) ChannelsRename(channelID, name string) (*Channel, error) {
+	res := channelsInfoResponse{}
+	err := NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/channels.rename").
+		WithPostData("token", rtm.Token).
+		WithPostData("channel", channelID).
+		WithPostData("name", name).
+		JSON(&res)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsEmpty(res.Error) {
+		return nil, exception.New(res.Error)
+	}
+
+	if !res.OK {
+		return nil, exception.New("slack response `ok` is false.")
+	}
+
+	return res.Channel, nil
+}
+
+// ChannelsReplies returns a thread, the parent message followed by its replies.
+func (rtm *Client) ChannelsReplies(channelID string, threadTS Timestamp) ([]Message, error) {
+	return rtm.replies("api/channels.replies", channelID, threadTS)
+}
+
+// replies calls a channels, groups, im or mpim replies method.
+func (rtm *Client) replies(path, channelID string, threadTS Timestamp) ([]Message, error) {
+	res := repliesResponse{}
+	err := NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath(path).
+		WithPostData("token", rtm.Token).
+		WithPostData("channel", channelID).
+		WithPostData("thread_ts", threadTS.String()).
+		JSON(&res)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsEmpty(res.Error) {
+		return nil, exception.New(res.Error)
+	}
+
+	if !res.OK {
+		return nil, exception.New("slack response `ok` is false.")
+	}
+
+	return res.Messages, nil
+}
+
 // ChannelsSetPurpose sets the purpose for a given Slack channel.
 func (rtm *Client) ChannelsSetPurpose(channelID, purpose string) error {
 	res := basicResponse{}
 	err := NewExternalRequest().
 		AsPost().
-		WithScheme(APIScheme).
-		WithHost(APIEndpoint).
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
 		WithPath("api/channels.setPurpose").
 		WithPostData("token", rtm.Token).
 		WithPostData("channel", channelID).
@@ -204,8 +431,8 @@ func (rtm *Client) ChannelsSetTopic(channelID, topic string) error {
 	res := basicResponse{}
 	err := NewExternalRequest().
 		AsPost().
-		WithScheme(APIScheme).
-		WithHost(APIEndpoint).
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
 		WithPath("api/channels.setTopic").
 		WithPostData("token", rtm.Token).
 		WithPostData("channel", channelID).
@@ -227,13 +454,22 @@ func (rtm *Client) ChannelsSetTopic(channelID, topic string) error {
 	return nil
 }
 
+// ChannelsUnarchive unarchives a channel, adding it to ActiveChannels (the caller is a member of channels it unarchives).
+func (rtm *Client) ChannelsUnarchive(channelID string) error {
+	if err := rtm.conversationUpdate("api/channels.unarchive", channelID); err != nil {
+		return err
+	}
+	rtm.addActiveChannel(channelID)
+	return nil
+}
+
 // ChatDelete deletes a message.
 func (rtm *Client) ChatDelete(channelID string, ts Timestamp) error {
 	res := basicResponse{}
 	err := NewExternalRequest().
 		AsPost().
-		WithScheme(APIScheme).
-		WithHost(APIEndpoint).
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
 		WithPath("api/chat.delete").
 		WithPostData("token", rtm.Token).
 		WithPostData("channel", channelID).
@@ -254,44 +490,72 @@ func (rtm *Client) ChatDelete(channelID string, ts Timestamp) error {
 	return nil
 }
 
-// ChatPostMessage posts a message to Slack using the chat api.
-func (rtm *Client) ChatPostMessage(m *ChatMessage) (*ChatMessageResponse, error) { //the response version of the message is returned for verification
-	res := ChatMessageResponse{}
+// ChatDeleteScheduledMessage deletes a pending scheduled message.
+func (rtm *Client) ChatDeleteScheduledMessage(channelID, scheduledMessageID string) error {
+	res := basicResponse{}
 	err := NewExternalRequest().
 		AsPost().
-		WithScheme(APIScheme).
-		WithHost(APIEndpoint).
-		WithPath("api/chat.postMessage").
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/chat.deleteScheduledMessage").
 		WithPostData("token", rtm.Token).
-		WithPostDataFromObject(m).
+		WithPostData("channel", channelID).
+		WithPostData("scheduled_message_id", scheduledMessageID).
 		JSON(&res)
 
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	if !IsEmpty(res.Error) {
-		return nil, exception.New(res.Error)
+		return exception.New(res.Error)
+	}
+	if !res.OK {
+		return exception.New("slack response `ok` is false.")
+	}
+
+	return nil
+}
+
+// ChatGetPermalink returns the permalink url for a message.
+func (rtm *Client) ChatGetPermalink(channelID string, ts Timestamp) (string, error) {
+	res := chatGetPermalinkResponse{}
+	err := NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/chat.getPermalink").
+		WithPostData("token", rtm.Token).
+		WithPostData("channel", channelID).
+		WithPostData("message_ts", ts.String()).
+		JSON(&res)
+
+	if err != nil {
+		return "", err
+	}
+
+	if !IsEmpty(res.Error) {
+		return "", exception.New(res.Error)
 	}
 
 	if !res.OK {
-		return nil, exception.New("slack response `ok` is false.")
+		return "", exception.New("slack response `ok` is false.")
 	}
 
-	return &res, nil
+	return res.Permalink, nil
 }
 
-// ChatUpdate updates a chat message.
-func (rtm *Client) ChatUpdate(ts Timestamp, m *ChatMessage) (*ChatMessageResponse, error) { //the response version of the message is returned for verification
+// ChatMeMessage posts a `/me` message to a channel.
+func (rtm *Client) ChatMeMessage(channelID, text string) (*ChatMessageResponse, error) {
 	res := ChatMessageResponse{}
 	err := NewExternalRequest().
 		AsPost().
-		WithScheme(APIScheme).
-		WithHost(APIEndpoint).
-		WithPath("api/chat.update").
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/chat.meMessage").
 		WithPostData("token", rtm.Token).
-		WithPostData("ts", ts.String()).
-		WithPostDataFromObject(m).
+		WithPostData("channel", channelID).
+		WithPostData("text", text).
 		JSON(&res)
 
 	if err != nil {
@@ -309,15 +573,18 @@ func (rtm *Client) ChatUpdate(ts Timestamp, m *ChatMessage) (*ChatMessageRespons
 	return &res, nil
 }
 
-// EmojiList returns a list of current emoji's for a slack.
-func (rtm *Client) EmojiList() (map[string]string, error) {
-	res := emojiResponse{}
+// ChatPostEphemeral posts a message only visible to a given user in a channel.
+// The message's `Channel`, `Text`, `Blocks`, `Attachments` and `ThreadTimestamp` are used.
+func (rtm *Client) ChatPostEphemeral(userID string, m *ChatMessage) (*ChatPostEphemeralResponse, error) {
+	res := ChatPostEphemeralResponse{}
 	err := NewExternalRequest().
 		AsPost().
-		WithScheme(APIScheme).
-		WithHost(APIEndpoint).
-		WithPath("api/emoji.list").
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/chat.postEphemeral").
 		WithPostData("token", rtm.Token).
+		WithPostData("user", userID).
+		WithPostDataFromObject(m).
 		JSON(&res)
 
 	if err != nil {
@@ -331,69 +598,49 @@ func (rtm *Client) EmojiList() (map[string]string, error) {
 	if !res.OK {
 		return nil, exception.New("slack response `ok` is false.")
 	}
-	return res.Emoji, nil
+
+	return &res, nil
 }
 
-// ReactionsAdd adds a reaction.
-func (rtm *Client) ReactionsAdd(name string, fileID, fileCommentID, channelID *string, ts *Timestamp) error {
-	res := basicResponse{}
-	req := NewExternalRequest().
+// ChatPostMessage posts a message to Slack using the chat api.
+func (rtm *Client) ChatPostMessage(m *ChatMessage) (*ChatMessageResponse, error) { //the response version of the message is returned for verification
+	res := ChatMessageResponse{}
+	err := NewExternalRequest().
 		AsPost().
-		WithScheme(APIScheme).
-		WithHost(APIEndpoint).
-		WithPath("api/reactions.add").
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/chat.postMessage").
 		WithPostData("token", rtm.Token).
-		WithPostData("name", name)
-
-	if fileID != nil {
-		req = req.WithPostData("file", *fileID)
-	} else if fileCommentID != nil {
-		req = req.WithPostData("file_comment", *fileCommentID)
-	} else if channelID != nil && ts != nil {
-		req = req.WithPostData("channel", *channelID)
-		req = req.WithPostData("timestamp", ts.String())
-	} else {
-		return exception.New("`fileId` or `fileCommentID` or (`channelID` and `ts`) must be not be nil.")
-	}
-
-	err := req.JSON(&res)
+		WithPostDataFromObject(m).
+		JSON(&res)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if !IsEmpty(res.Error) {
-		return exception.New(res.Error)
+		return nil, exception.New(res.Error)
 	}
 
 	if !res.OK {
-		return exception.New("slack response `ok` is false.")
+		return nil, exception.New("slack response `ok` is false.")
 	}
-	return nil
+
+	return &res, nil
 }
 
-// ReactionsGet gets reactions.
-func (rtm *Client) ReactionsGet(fileID, fileCommentID, channelID *string, ts *Timestamp) (*ChatMessageResponse, error) {
-	res := ChatMessageResponse{}
-	req := NewExternalRequest().
+// ChatScheduleMessage schedules a message to be posted by Slack at a given time.
+func (rtm *Client) ChatScheduleMessage(postAt time.Time, m *ChatMessage) (*ChatScheduleMessageResponse, error) {
+	res := ChatScheduleMessageResponse{}
+	err := NewExternalRequest().
 		AsPost().
-		WithScheme(APIScheme).
-		WithHost(APIEndpoint).
-		WithPath("api/reactions.get").
-		WithPostData("token", rtm.Token)
-
-	if fileID != nil {
-		req = req.WithPostData("file", *fileID)
-	} else if fileCommentID != nil {
-		req = req.WithPostData("file_comment", *fileCommentID)
-	} else if channelID != nil && ts != nil {
-		req = req.WithPostData("channel", *channelID)
-		req = req.WithPostData("timestamp", ts.String())
-	} else {
-		return nil, exception.New("`fileId` or `fileCommentID` or (`channelID` and `ts`) must be not be nil.")
-	}
-
-	err := req.JSON(&res)
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/chat.scheduleMessage").
+		WithPostData("token", rtm.Token).
+		WithPostData("post_at", NewTimestamp(postAt).String()).
+		WithPostDataFromObject(m).
+		JSON(&res)
 
 	if err != nil {
 		return nil, err
@@ -406,54 +653,1498 @@ func (rtm *Client) ReactionsGet(fileID, fileCommentID, channelID *string, ts *Ti
 	if !res.OK {
 		return nil, exception.New("slack response `ok` is false.")
 	}
+
 	return &res, nil
 }
 
-// ReactionsRemove removes a reaction.
-func (rtm *Client) ReactionsRemove(name string, fileID, fileCommentID, channelID *string, ts *Timestamp) error {
-	res := basicResponse{}
+// ChatScheduledMessagesList returns the pending scheduled messages.
+// `channelID`, `latest` and `oldest` are optional filters, and `cursor` is the `NextCursor` from a previous page.
+func (rtm *Client) ChatScheduledMessagesList(channelID string, latest, oldest *time.Time, limit int, cursor string) (*ChatScheduledMessagesListResponse, error) {
+	res := ChatScheduledMessagesListResponse{}
 	req := NewExternalRequest().
 		AsPost().
-		WithScheme(APIScheme).
-		WithHost(APIEndpoint).
-		WithPath("api/reactions.remove").
-		WithPostData("token", rtm.Token).
-		WithPostData("name", name)
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/chat.scheduledMessages.list").
+		WithPostData("token", rtm.Token)
 
-	if fileID != nil {
-		req = req.WithPostData("file", *fileID)
-	} else if fileCommentID != nil {
-		req = req.WithPostData("file_comment", *fileCommentID)
-	} else if channelID != nil && ts != nil {
-		req = req.WithPostData("channel", *channelID)
-		req = req.WithPostData("timestamp", ts.String())
-	} else {
-		return exception.New("`fileId` or `fileCommentID` or (`channelID` and `ts`) must be not be nil.")
+	if !IsEmpty(channelID) {
+		req = req.WithPostData("channel", channelID)
+	}
+	if latest != nil {
+		req = req.WithPostData("latest", NewTimestamp(*latest).String())
+	}
+	if oldest != nil {
+		req = req.WithPostData("oldest", NewTimestamp(*oldest).String())
+	}
+	if limit > 0 {
+		req = req.WithPostData("limit", strconv.Itoa(limit))
+	}
+	if !IsEmpty(cursor) {
+		req = req.WithPostData("cursor", cursor)
 	}
 
 	err := req.JSON(&res)
-
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if !IsEmpty(res.Error) {
-		return exception.New(res.Error)
+		return nil, exception.New(res.Error)
 	}
 
 	if !res.OK {
-		return exception.New("slack response `ok` is false.")
+		return nil, exception.New("slack response `ok` is false.")
+	}
+
+	return &res, nil
+}
+
+// ChatUnfurl provides custom unfurls for the urls in a message, keyed by url.
+func (rtm *Client) ChatUnfurl(channelID string, ts Timestamp, unfurls map[string]ChatMessageAttachment) error {
+	unfurlsJSON, err := json.Marshal(unfurls)
+	if err != nil {
+		return exception.Wrap(err)
+	}
+
+	res := basicResponse{}
+	err = NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/chat.unfurl").
+		WithPostData("token", rtm.Token).
+		WithPostData("channel", channelID).
+		WithPostData("ts", ts.String()).
+		WithPostData("unfurls", string(unfurlsJSON)).
+		JSON(&res)
+
+	if err != nil {
+		return err
+	}
+
+	if !IsEmpty(res.Error) {
+		return exception.New(res.Error)
+	}
+	if !res.OK {
+		return exception.New("slack response `ok` is false.")
+	}
+
+	return nil
+}
+
+// ChatUpdate updates a chat message.
+func (rtm *Client) ChatUpdate(ts Timestamp, m *ChatMessage) (*ChatMessageResponse, error) { //the response version of the message is returned for verification
+	res := ChatMessageResponse{}
+	err := NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/chat.update").
+		WithPostData("token", rtm.Token).
+		WithPostData("ts", ts.String()).
+		WithPostDataFromObject(m).
+		JSON(&res)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsEmpty(res.Error) {
+		return nil, exception.New(res.Error)
+	}
+
+	if !res.OK {
+		return nil, exception.New("slack response `ok` is false.")
+	}
+
+	return &res, nil
+}
+
+// DndEndDnd ends the calling user's current do not disturb session.
+func (rtm *Client) DndEndDnd() error {
+	res := basicResponse{}
+	err := NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/dnd.endDnd").
+		WithPostData("token", rtm.Token).
+		JSON(&res)
+
+	if err != nil {
+		return err
+	}
+
+	if !IsEmpty(res.Error) {
+		return exception.New(res.Error)
+	}
+
+	if !res.OK {
+		return exception.New("slack response `ok` is false.")
+	}
+	return nil
+}
+
+// DndEndSnooze ends the calling user's snooze.
+func (rtm *Client) DndEndSnooze() (*DNDStatus, error) {
+	status, err := rtm.dndCall(NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/dnd.endSnooze").
+		WithPostData("token", rtm.Token))
+	if err != nil {
+		return nil, err
+	}
+
+	rtm.state.setSelfDND(func(cached *DNDStatus) {
+		*cached = *status
+	})
+	return status, nil
+}
+
+// DndInfo returns a user's (the calling user's if `userID` is empty) do not disturb status.
+func (rtm *Client) DndInfo(userID string) (*DNDStatus, error) {
+	req := NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/dnd.info").
+		WithPostData("token", rtm.Token)
+
+	if !IsEmpty(userID) {
+		req = req.WithPostData("user", userID)
+	}
+
+	status, err := rtm.dndCall(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if IsEmpty(userID) {
+		rtm.state.setSelfDND(func(cached *DNDStatus) {
+			*cached = *status
+		})
+	} else {
+		rtm.state.SetDND(userID, *status)
+	}
+	return status, nil
+}
+
+// DndSetSnooze pauses the calling user's notifications for a number of minutes.
+func (rtm *Client) DndSetSnooze(minutes int) (*DNDStatus, error) {
+	status, err := rtm.dndCall(NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/dnd.setSnooze").
+		WithPostData("token", rtm.Token).
+		WithPostData("num_minutes", strconv.Itoa(minutes)))
+	if err != nil {
+		return nil, err
+	}
+
+	// the response only has the snooze fields.
+	rtm.state.setSelfDND(func(cached *DNDStatus) {
+		cached.SnoozeEnabled = status.SnoozeEnabled
+		cached.SnoozeEndTime = status.SnoozeEndTime
+		cached.SnoozeRemaining = status.SnoozeRemaining
+	})
+	return status, nil
+}
+
+// DndTeamInfo returns the do not disturb status of users, keyed by user id.
+func (rtm *Client) DndTeamInfo(userIDs []string) (map[string]DNDStatus, error) {
+	res := dndTeamInfoResponse{}
+	err := NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/dnd.teamInfo").
+		WithPostData("token", rtm.Token).
+		WithPostData("users", strings.Join(userIDs, ",")).
+		JSON(&res)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsEmpty(res.Error) {
+		return nil, exception.New(res.Error)
+	}
+
+	if !res.OK {
+		return nil, exception.New("slack response `ok` is false.")
+	}
+
+	for userID, status := range res.Users {
+		rtm.state.SetDND(userID, status)
+	}
+	return res.Users, nil
+}
+
+func (rtm *Client) dndCall(req *request.Request) (*DNDStatus, error) {
+	res := dndStatusResponse{}
+	err := req.JSON(&res)
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsEmpty(res.Error) {
+		return nil, exception.New(res.Error)
+	}
+
+	if !res.OK {
+		return nil, exception.New("slack response `ok` is false.")
+	}
+
+	return &res.DNDStatus, nil
+}
+
+// EmojiList returns a list of current emoji's for a slack.
+func (rtm *Client) EmojiList() (map[string]string, error) {
+	res := emojiResponse{}
+	err := NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/emoji.list").
+		WithPostData("token", rtm.Token).
+		JSON(&res)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsEmpty(res.Error) {
+		return nil, exception.New(res.Error)
+	}
+
+	if !res.OK {
+		return nil, exception.New("slack response `ok` is false.")
+	}
+	return res.Emoji, nil
+}
+
+// FilesUpload uploads a file, sharing it to the upload's channels (if any).
+func (rtm *Client) FilesUpload(upload *FileUpload, contents io.Reader) (*File, error) {
+	body := bytes.NewBuffer(nil)
+	form := multipart.NewWriter(body)
+	fields := map[string]string{
+		"token":           rtm.Token,
+		"channels":        strings.Join(upload.Channels, ","),
+		"filename":        upload.Filename,
+		"filetype":        upload.FileType,
+		"title":           upload.Title,
+		"initial_comment": upload.InitialComment,
+	}
+	if upload.ThreadTimestamp != nil {
+		fields["thread_ts"] = upload.ThreadTimestamp.String()
+	}
+	for name, value := range fields {
+		if len(value) == 0 {
+			continue
+		}
+		if err := form.WriteField(name, value); err != nil {
+			return nil, exception.Wrap(err)
+		}
+	}
+	part, err := form.CreateFormFile("file", upload.Filename)
+	if err != nil {
+		return nil, exception.Wrap(err)
+	}
+	if _, err = io.Copy(part, contents); err != nil {
+		return nil, exception.Wrap(err)
+	}
+	if err = form.Close(); err != nil {
+		return nil, exception.Wrap(err)
+	}
+
+	res := fileResponse{}
+	err = NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/files.upload").
+		WithHeader("Content-Type", form.FormDataContentType()).
+		WithPostBody(body.Bytes()).
+		JSON(&res)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsEmpty(res.Error) {
+		return nil, exception.New(res.Error)
+	}
+
+	if !res.OK {
+		return nil, exception.New("slack response `ok` is false.")
+	}
+
+	return res.File, nil
+}
+
+// GroupsArchive archives a private channel.
+func (rtm *Client) GroupsArchive(channelID string) error {
+	return rtm.conversationUpdate("api/groups.archive", channelID)
+}
+
+// GroupsClose closes (hides) a private channel without leaving it.
+func (rtm *Client) GroupsClose(channelID string) error {
+	return rtm.conversationUpdate("api/groups.close", channelID)
+}
+
+// GroupsCreate creates a private channel.
+func (rtm *Client) GroupsCreate(name string) (*Group, error) {
+	res := groupResponse{}
+	err := NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/groups.create").
+		WithPostData("token", rtm.Token).
+		WithPostData("name", name).
+		JSON(&res)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsEmpty(res.Error) {
+		return nil, exception.New(res.Error)
+	}
+
+	if !res.OK {
+		return nil, exception.New("slack response `ok` is false.")
+	}
+
+	return res.Group, nil
+}
+
+// GroupsHistory returns the messages in a private channel (see ChannelsHistory).
+func (rtm *Client) GroupsHistory(channelID string, latest, oldest *time.Time, count int, unreads bool) (*ChannelsHistoryResponse, error) {
+	return rtm.history("api/groups.history", channelID, latest, oldest, count, unreads)
+}
+
+// GroupsInfo returns a private channel.
+func (rtm *Client) GroupsInfo(channelID string) (*Group, error) {
+	res := groupResponse{}
+	err := NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/groups.info").
+		WithPostData("token", rtm.Token).
+		WithPostData("channel", channelID).
+		JSON(&res)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsEmpty(res.Error) {
+		return nil, exception.New(res.Error)
+	}
+
+	if !res.OK {
+		return nil, exception.New("slack response `ok` is false.")
+	}
+
+	return res.Group, nil
+}
+
+// GroupsInvite invites a user to a private channel.
+func (rtm *Client) GroupsInvite(channelID, userID string) (*Group, error) {
+	res := groupResponse{}
+	err := NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/groups.invite").
+		WithPostData("token", rtm.Token).
+		WithPostData("channel", channelID).
+		WithPostData("user", userID).
+		JSON(&res)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsEmpty(res.Error) {
+		return nil, exception.New(res.Error)
+	}
+
+	if !res.OK {
+		return nil, exception.New("slack response `ok` is false.")
+	}
+
+	return res.Group, nil
+}
+
+// GroupsKick removes a user from a private channel.
+func (rtm *Client) GroupsKick(channelID, userID string) error {
+	return rtm.conversationUpdate("api/groups.kick", channelID, "user", userID)
+}
+
+// GroupsLeave leaves a private channel.
+func (rtm *Client) GroupsLeave(channelID string) error {
+	return rtm.conversationUpdate("api/groups.leave", channelID)
+}
+
+// GroupsList returns the private channels the calling user is a member of.
+func (rtm *Client) GroupsList(excludeArchived bool) ([]Group, error) {
+	res := GroupsListResponse{}
+	req := NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/groups.list").
+		WithPostData("token", rtm.Token)
+
+	if excludeArchived {
+		req = req.WithPostData("exclude_archived", "1")
+	}
+
+	err := req.JSON(&res)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsEmpty(res.Error) {
+		return nil, exception.New(res.Error)
+	}
+
+	if !res.OK {
+		return nil, exception.New("slack response `ok` is false.")
+	}
+
+	return res.Groups, nil
+}
+
+// GroupsListPage returns a page of the private channels the calling user is a member of;
+// `cursor` is the `NextCursor` from a previous page.
+func (rtm *Client) GroupsListPage(excludeArchived bool, limit int, cursor string) (*GroupsListResponse, error) {
+	res := GroupsListResponse{}
+	req := NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/groups.list").
+		WithPostData("token", rtm.Token)
+
+	if excludeArchived {
+		req = req.WithPostData("exclude_archived", "1")
+	}
+	if limit > 0 {
+		req = req.WithPostData("limit", strconv.Itoa(limit))
+	}
+	if !IsEmpty(cursor) {
+		req = req.WithPostData("cursor", cursor)
+	}
+
+	err := req.JSON(&res)
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsEmpty(res.Error) {
+		return nil, exception.New(res.Error)
+	}
+
+	if !res.OK {
+		return nil, exception.New("slack response `ok` is false.")
+	}
+
+	return &res, nil
+}
+
+// GroupsMark moves the read cursor in a private channel.
+func (rtm *Client) GroupsMark(channelID string, ts Timestamp) error {
+	return rtm.conversationUpdate("api/groups.mark", channelID, "ts", ts.String())
+}
+
+// GroupsOpen opens (un-hides) a private channel.
+func (rtm *Client) GroupsOpen(channelID string) error {
+	return rtm.conversationUpdate("api/groups.open", channelID)
+}
+
+// GroupsRename renames a private channel.
+func (rtm *Client) GroupsRename(channelID, name string) error {
+	return rtm.conversationUpdate("api/groups.rename", channelID, "name", name)
+}
+
+// GroupsReplies returns a thread in a private channel (see ChannelsReplies).
+func (rtm *Client) GroupsReplies(channelID string, threadTS Timestamp) ([]Message, error) {
+	return rtm.replies("api/groups.replies", channelID, threadTS)
+}
+
+// GroupsSetPurpose sets the purpose of a private channel.
+func (rtm *Client) GroupsSetPurpose(channelID, purpose string) error {
+	return rtm.conversationUpdate("api/groups.setPurpose", channelID, "purpose", purpose)
+}
+
+// GroupsSetTopic sets the topic of a private channel.
+func (rtm *Client) GroupsSetTopic(channelID, topic string) error {
+	return rtm.conversationUpdate("api/groups.setTopic", channelID, "topic", topic)
+}
+
+// GroupsUnarchive unarchives a private channel.
+func (rtm *Client) GroupsUnarchive(channelID string) error {
+	return rtm.conversationUpdate("api/groups.unarchive", channelID)
+}
+
+// ImClose closes (hides) a direct message channel.
+func (rtm *Client) ImClose(channelID string) error {
+	return rtm.conversationUpdate("api/im.close", channelID)
+}
+
+// ImHistory returns the messages in a direct message channel (see ChannelsHistory).
+func (rtm *Client) ImHistory(channelID string, latest, oldest *time.Time, count int, unreads bool) (*ChannelsHistoryResponse, error) {
+	return rtm.history("api/im.history", channelID, latest, oldest, count, unreads)
+}
+
+// ImList returns the calling user's direct message channels.
+func (rtm *Client) ImList() ([]InstantMessage, error) {
+	res := imListResponse{}
+	err := NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/im.list").
+		WithPostData("token", rtm.Token).
+		JSON(&res)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsEmpty(res.Error) {
+		return nil, exception.New(res.Error)
+	}
+
+	if !res.OK {
+		return nil, exception.New("slack response `ok` is false.")
+	}
+
+	return res.IMs, nil
+}
+
+// ImMark moves the read cursor in a direct message channel.
+func (rtm *Client) ImMark(channelID string, ts Timestamp) error {
+	return rtm.conversationUpdate("api/im.mark", channelID, "ts", ts.String())
+}
+
+// ImOpen opens a direct message channel with a user, returning the existing channel if there is one.
+func (rtm *Client) ImOpen(userID string) (*InstantMessage, error) {
+	res := imOpenResponse{}
+	err := NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/im.open").
+		WithPostData("token", rtm.Token).
+		WithPostData("user", userID).
+		WithPostData("return_im", "true").
+		JSON(&res)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsEmpty(res.Error) {
+		return nil, exception.New(res.Error)
+	}
+
+	if !res.OK {
+		return nil, exception.New("slack response `ok` is false.")
+	}
+
+	if IsEmpty(res.Channel.User) {
+		res.Channel.User = userID
+	}
+	res.Channel.IsIM = true
+	rtm.state.SetIM(res.Channel)
+	return &res.Channel, nil
+}
+
+// ImReplies returns a thread in a direct message channel (see ChannelsReplies).
+func (rtm *Client) ImReplies(channelID string, threadTS Timestamp) ([]Message, error) {
+	return rtm.replies("api/im.replies", channelID, threadTS)
+}
+
+// MpimClose closes (hides) a multiparty direct message channel.
+func (rtm *Client) MpimClose(channelID string) error {
+	return rtm.conversationUpdate("api/mpim.close", channelID)
+}
+
+// MpimHistory returns the messages in a multiparty direct message channel (see ChannelsHistory).
+func (rtm *Client) MpimHistory(channelID string, latest, oldest *time.Time, count int, unreads bool) (*ChannelsHistoryResponse, error) {
+	return rtm.history("api/mpim.history", channelID, latest, oldest, count, unreads)
+}
+
+// MpimList returns the calling user's multiparty direct message channels.
+func (rtm *Client) MpimList() ([]Group, error) {
+	res := GroupsListResponse{}
+	err := NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/mpim.list").
+		WithPostData("token", rtm.Token).
+		JSON(&res)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsEmpty(res.Error) {
+		return nil, exception.New(res.Error)
+	}
+
+	if !res.OK {
+		return nil, exception.New("slack response `ok` is false.")
+	}
+
+	return res.Groups, nil
+}
+
+// MpimMark moves the read cursor in a multiparty direct message channel.
+func (rtm *Client) MpimMark(channelID string, ts Timestamp) error {
+	return rtm.conversationUpdate("api/mpim.mark", channelID, "ts", ts.String())
+}
+
+// MpimOpen opens a multiparty direct message channel with users (the calling user is included),
+// returning the existing channel if there is one.
+func (rtm *Client) MpimOpen(userIDs []string) (*Group, error) {
+	res := groupResponse{}
+	err := NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/mpim.open").
+		WithPostData("token", rtm.Token).
+		WithPostData("users", strings.Join(userIDs, ",")).
+		JSON(&res)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsEmpty(res.Error) {
+		return nil, exception.New(res.Error)
+	}
+
+	if !res.OK {
+		return nil, exception.New("slack response `ok` is false.")
+	}
+
+	return res.Group, nil
+}
+
+// MpimReplies returns a thread in a multiparty direct message channel (see ChannelsReplies).
+func (rtm *Client) MpimReplies(channelID string, threadTS Timestamp) ([]Message, error) {
+	return rtm.replies("api/mpim.replies", channelID, threadTS)
+}
+
+// conversationUpdate calls a channels, groups, im or mpim method that changes a channel, with extra form values as key / value pairs.
+func (rtm *Client) conversationUpdate(path, channelID string, postData ...string) error {
+	req := NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath(path).
+		WithPostData("token", rtm.Token).
+		WithPostData("channel", channelID)
+
+	for index := 0; index+1 < len(postData); index += 2 {
+		req = req.WithPostData(postData[index], postData[index+1])
+	}
+
+	res := basicResponse{}
+	err := req.JSON(&res)
+
+	if err != nil {
+		return err
+	}
+
+	if !IsEmpty(res.Error) {
+		return exception.New(res.Error)
+	}
+
+	if !res.OK {
+		return exception.New("slack response `ok` is false.")
+	}
+	return nil
+}
+
+// PinsAdd pins a message, file or file comment to a channel.
+func (rtm *Client) PinsAdd(channelID string, item ItemRef) error {
+	return rtm.itemUpdate("api/pins.add", item, "channel", channelID)
+}
+
+// PinsList returns the items pinned to a channel.
+func (rtm *Client) PinsList(channelID string) ([]Item, error) {
+	res := pinsListResponse{}
+	err := NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/pins.list").
+		WithPostData("token", rtm.Token).
+		WithPostData("channel", channelID).
+		JSON(&res)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsEmpty(res.Error) {
+		return nil, exception.New(res.Error)
+	}
+
+	if !res.OK {
+		return nil, exception.New("slack response `ok` is false.")
+	}
+
+	return res.Items, nil
+}
+
+// PinsRemove un-pins a message, file or file comment from a channel.
+func (rtm *Client) PinsRemove(channelID string, item ItemRef) error {
+	return rtm.itemUpdate("api/pins.remove", item, "channel", channelID)
+}
+
+// ReactionsAdd adds a reaction (an emoji name, i.e. `thumbsup`) to a message, file or file comment.
+func (rtm *Client) ReactionsAdd(name string, item ItemRef) error {
+	return rtm.itemUpdate("api/reactions.add", item, "name", name)
+}
+
+// ReactionsEnsure adds a reaction if the calling user hasn't already reacted with it.
+func (rtm *Client) ReactionsEnsure(name string, item ItemRef) error {
+	err := rtm.ReactionsAdd(name, item)
+	if IsAPIError(err, ErrorAlreadyReacted) {
+		return nil
+	}
+	return err
+}
+
+// ReactionsGet returns an item with its reactions; `full` returns every reacting user instead of a sample.
+func (rtm *Client) ReactionsGet(item ItemRef, full bool) (*ReactionsGetResponse, error) {
+	res := ReactionsGetResponse{}
+	req, err := item.withPostData(NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/reactions.get").
+		WithPostData("token", rtm.Token).
+		WithPostData("full", strconv.FormatBool(full)))
+	if err != nil {
+		return nil, err
+	}
+
+	err = req.JSON(&res)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsEmpty(res.Error) {
+		return nil, exception.New(res.Error)
+	}
+
+	if !res.OK {
+		return nil, exception.New("slack response `ok` is false.")
+	}
+	return &res, nil
+}
+
+// ReactionsList returns the items a user (the calling user if `userID` is empty) has reacted to.
+// `cursor` is the `NextCursor` from a previous page.
+func (rtm *Client) ReactionsList(userID string, full bool, limit int, cursor string) (*ReactionsListResponse, error) {
+	res := ReactionsListResponse{}
+	req := NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/reactions.list").
+		WithPostData("token", rtm.Token).
+		WithPostData("full", strconv.FormatBool(full))
+
+	if !IsEmpty(userID) {
+		req = req.WithPostData("user", userID)
+	}
+	if limit > 0 {
+		req = req.WithPostData("limit", strconv.Itoa(limit))
+	}
+	if !IsEmpty(cursor) {
+		req = req.WithPostData("cursor", cursor)
+	}
+
+	err := req.JSON(&res)
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsEmpty(res.Error) {
+		return nil, exception.New(res.Error)
+	}
+
+	if !res.OK {
+		return nil, exception.New("slack response `ok` is false.")
+	}
+
+	return &res, nil
+}
+
+// ReactionsRemove removes a reaction from a message, file or file comment.
+func (rtm *Client) ReactionsRemove(name string, item ItemRef) error {
+	return rtm.itemUpdate("api/reactions.remove", item, "name", name)
+}
+
+// ReactionsToggle adds a reaction, or removes it if the calling user had already reacted with it.
+// It returns if the reaction was added.
+func (rtm *Client) ReactionsToggle(name string, item ItemRef) (bool, error) {
+	err := rtm.ReactionsAdd(name, item)
+	if IsAPIError(err, ErrorAlreadyReacted) {
+		return false, rtm.ReactionsRemove(name, item)
+	}
+	return err == nil, err
+}
+
+// RemindersAdd creates a reminder for a user (the calling user if `userID` is empty).
+// `when` is natural language (i.e. `in 15 minutes` or `every weekday at 9am`), a unix timestamp,
+// or a number of seconds from now. `recurrence` is optional.
+func (rtm *Client) RemindersAdd(text, when, userID string, recurrence *ReminderRecurrence) (*Reminder, error) {
+	req := NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/reminders.add").
+		WithPostData("token", rtm.Token).
+		WithPostData("text", text).
+		WithPostData("time", when)
+
+	if !IsEmpty(userID) {
+		req = req.WithPostData("user", userID)
+	}
+	if recurrence != nil {
+		recurrenceJSON, err := json.Marshal(recurrence)
+		if err != nil {
+			return nil, err
+		}
+		req = req.WithPostData("recurrence", string(recurrenceJSON))
+	}
+
+	res := reminderResponse{}
+	err := req.JSON(&res)
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsEmpty(res.Error) {
+		return nil, exception.New(res.Error)
+	}
+
+	if !res.OK {
+		return nil, exception.New("slack response `ok` is false.")
+	}
+
+	return res.Reminder, nil
+}
+
+// RemindersAddAt creates a reminder that fires at a given time (see RemindersAdd).
+func (rtm *Client) RemindersAddAt(text string, at time.Time, userID string, recurrence *ReminderRecurrence) (*Reminder, error) {
+	return rtm.RemindersAdd(text, NewTimestamp(at).String(), userID, recurrence)
+}
+
+// RemindersComplete marks a one-off reminder as complete.
+func (rtm *Client) RemindersComplete(reminderID string) error {
+	return rtm.reminderUpdate("api/reminders.complete", reminderID)
+}
+
+// RemindersDelete deletes a reminder.
+func (rtm *Client) RemindersDelete(reminderID string) error {
+	return rtm.reminderUpdate("api/reminders.delete", reminderID)
+}
+
+// RemindersInfo returns a reminder.
+func (rtm *Client) RemindersInfo(reminderID string) (*Reminder, error) {
+	res := reminderResponse{}
+	err := NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/reminders.info").
+		WithPostData("token", rtm.Token).
+		WithPostData("reminder", reminderID).
+		JSON(&res)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsEmpty(res.Error) {
+		return nil, exception.New(res.Error)
+	}
+
+	if !res.OK {
+		return nil, exception.New("slack response `ok` is false.")
+	}
+
+	return res.Reminder, nil
+}
+
+// RemindersList returns the reminders created by or for the calling user.
+func (rtm *Client) RemindersList() ([]Reminder, error) {
+	res := remindersListResponse{}
+	err := NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/reminders.list").
+		WithPostData("token", rtm.Token).
+		JSON(&res)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsEmpty(res.Error) {
+		return nil, exception.New(res.Error)
+	}
+
+	if !res.OK {
+		return nil, exception.New("slack response `ok` is false.")
+	}
+
+	return res.Reminders, nil
+}
+
+func (rtm *Client) reminderUpdate(path, reminderID string) error {
+	res := basicResponse{}
+	err := NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath(path).
+		WithPostData("token", rtm.Token).
+		WithPostData("reminder", reminderID).
+		JSON(&res)
+
+	if err != nil {
+		return err
+	}
+
+	if !IsEmpty(res.Error) {
+		return exception.New(res.Error)
+	}
+
+	if !res.OK {
+		return exception.New("slack response `ok` is false.")
+	}
+	return nil
+}
+
+// SearchAll searches messages and files (see NewSearchQuery); `params` is optional.
+func (rtm *Client) SearchAll(query string, params *SearchParameters) (*SearchAllResponse, error) {
+	res := SearchAllResponse{}
+	err := rtm.search("api/search.all", query, params, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsEmpty(res.Error) {
+		return nil, exception.New(res.Error)
+	}
+
+	if !res.OK {
+		return nil, exception.New("slack response `ok` is false.")
+	}
+
+	return &res, nil
+}
+
+// SearchFiles searches files (see NewSearchQuery); `params` is optional.
+func (rtm *Client) SearchFiles(query string, params *SearchParameters) (*SearchFilesResponse, error) {
+	res := SearchFilesResponse{}
+	err := rtm.search("api/search.files", query, params, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsEmpty(res.Error) {
+		return nil, exception.New(res.Error)
+	}
+
+	if !res.OK {
+		return nil, exception.New("slack response `ok` is false.")
+	}
+
+	return &res, nil
+}
+
+// SearchMessages searches messages (see NewSearchQuery); `params` is optional.
+// Search requires a user token.
+func (rtm *Client) SearchMessages(query string, params *SearchParameters) (*SearchMessagesResponse, error) {
+	res := SearchMessagesResponse{}
+	err := rtm.search("api/search.messages", query, params, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsEmpty(res.Error) {
+		return nil, exception.New(res.Error)
+	}
+
+	if !res.OK {
+		return nil, exception.New("slack response `ok` is false.")
+	}
+
+	return &res, nil
+}
+
+func (rtm *Client) search(path, query string, params *SearchParameters, res interface{}) error {
+	req := NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath(path).
+		WithPostData("token", rtm.Token).
+		WithPostData("query", query)
+
+	if params != nil {
+		if !IsEmpty(params.Sort) {
+			req = req.WithPostData("sort", params.Sort)
+		}
+		if !IsEmpty(params.SortDir) {
+			req = req.WithPostData("sort_dir", params.SortDir)
+		}
+		if params.Highlight {
+			req = req.WithPostData("highlight", "true")
+		}
+		if params.Count > 0 {
+			req = req.WithPostData("count", strconv.Itoa(params.Count))
+		}
+		if params.Page > 0 {
+			req = req.WithPostData("page", strconv.Itoa(params.Page))
+		}
+	}
+	return req.JSON(res)
+}
+
+// StarsAdd stars a message, file, file comment or channel for the calling user.
+func (rtm *Client) StarsAdd(item ItemRef) error {
+	return rtm.itemUpdate("api/stars.add", item)
+}
+
+// StarsList returns the calling user's starred items; `cursor` is the `NextCursor` from a previous page.
+func (rtm *Client) StarsList(limit int, cursor string) (*StarsListResponse, error) {
+	res := StarsListResponse{}
+	req := NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/stars.list").
+		WithPostData("token", rtm.Token)
+
+	if limit > 0 {
+		req = req.WithPostData("limit", strconv.Itoa(limit))
+	}
+	if !IsEmpty(cursor) {
+		req = req.WithPostData("cursor", cursor)
+	}
+
+	err := req.JSON(&res)
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsEmpty(res.Error) {
+		return nil, exception.New(res.Error)
+	}
+
+	if !res.OK {
+		return nil, exception.New("slack response `ok` is false.")
+	}
+
+	return &res, nil
+}
+
+// StarsRemove un-stars a message, file, file comment or channel for the calling user.
+func (rtm *Client) StarsRemove(item ItemRef) error {
+	return rtm.itemUpdate("api/stars.remove", item)
+}
+
+// itemUpdate calls a pins, reactions or stars method that changes an item, with extra form values as key / value pairs.
+func (rtm *Client) itemUpdate(path string, item ItemRef, postData ...string) error {
+	req := NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath(path).
+		WithPostData("token", rtm.Token)
+
+	for index := 0; index+1 < len(postData); index += 2 {
+		req = req.WithPostData(postData[index], postData[index+1])
+	}
+
+	req, err := item.withPostData(req)
+	if err != nil {
+		return err
+	}
+
+	res := basicResponse{}
+	err = req.JSON(&res)
+
+	if err != nil {
+		return err
+	}
+
+	if !IsEmpty(res.Error) {
+		return exception.New(res.Error)
+	}
+
+	if !res.OK {
+		return exception.New("slack response `ok` is false.")
 	}
 	return nil
 }
 
+// TeamAccessLogs returns the team's logins, newest first (paid teams only).
+// `before` is optional, and `page` starts at 1.
+func (rtm *Client) TeamAccessLogs(before *time.Time, count, page int) (*TeamAccessLogsResponse, error) {
+	res := TeamAccessLogsResponse{}
+	req := NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/team.accessLogs").
+		WithPostData("token", rtm.Token)
+
+	if before != nil {
+		req = req.WithPostData("before", NewTimestamp(*before).String())
+	}
+	if count > 0 {
+		req = req.WithPostData("count", strconv.Itoa(count))
+	}
+	if page > 0 {
+		req = req.WithPostData("page", strconv.Itoa(page))
+	}
+
+	err := req.JSON(&res)
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsEmpty(res.Error) {
+		return nil, exception.New(res.Error)
+	}
+
+	if !res.OK {
+		return nil, exception.New("slack response `ok` is false.")
+	}
+
+	return &res, nil
+}
+
+// TeamBillableInfo returns whether users are billed for, keyed by user id.
+// `userID` is optional and limits the result to one user.
+func (rtm *Client) TeamBillableInfo(userID string) (map[string]BillableInfo, error) {
+	res := teamBillableInfoResponse{}
+	req := NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/team.billableInfo").
+		WithPostData("token", rtm.Token)
+
+	if !IsEmpty(userID) {
+		req = req.WithPostData("user", userID)
+	}
+
+	err := req.JSON(&res)
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsEmpty(res.Error) {
+		return nil, exception.New(res.Error)
+	}
+
+	if !res.OK {
+		return nil, exception.New("slack response `ok` is false.")
+	}
+
+	return res.BillableInfo, nil
+}
+
+// TeamInfo returns the team the client is authenticated against.
+func (rtm *Client) TeamInfo() (*Team, error) {
+	res := teamInfoResponse{}
+	err := NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/team.info").
+		WithPostData("token", rtm.Token).
+		JSON(&res)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsEmpty(res.Error) {
+		return nil, exception.New(res.Error)
+	}
+
+	if !res.OK {
+		return nil, exception.New("slack response `ok` is false.")
+	}
+
+	return res.Team, nil
+}
+
+// TeamProfileGet returns the team's custom profile fields.
+// `visibility` is optional; `all`, `visible` or `hidden`.
+func (rtm *Client) TeamProfileGet(visibility string) (*TeamProfile, error) {
+	res := teamProfileGetResponse{}
+	req := NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/team.profile.get").
+		WithPostData("token", rtm.Token)
+
+	if !IsEmpty(visibility) {
+		req = req.WithPostData("visibility", visibility)
+	}
+
+	err := req.JSON(&res)
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsEmpty(res.Error) {
+		return nil, exception.New(res.Error)
+	}
+
+	if !res.OK {
+		return nil, exception.New("slack response `ok` is false.")
+	}
+
+	return res.Profile, nil
+}
+
+// UsergroupsCreate creates a user group; `handle` is what it's mentioned with (i.e. `oncall` for `@oncall`).
+// `handle`, `description` and the default `channelIDs` are optional.
+func (rtm *Client) UsergroupsCreate(name, handle, description string, channelIDs []string) (*UserGroup, error) {
+	req := NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/usergroups.create").
+		WithPostData("token", rtm.Token).
+		WithPostData("name", name)
+
+	if !IsEmpty(handle) {
+		req = req.WithPostData("handle", handle)
+	}
+	if !IsEmpty(description) {
+		req = req.WithPostData("description", description)
+	}
+	if len(channelIDs) > 0 {
+		req = req.WithPostData("channels", strings.Join(channelIDs, ","))
+	}
+
+	res := userGroupResponse{}
+	err := req.JSON(&res)
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsEmpty(res.Error) {
+		return nil, exception.New(res.Error)
+	}
+
+	if !res.OK {
+		return nil, exception.New("slack response `ok` is false.")
+	}
+
+	return res.UserGroup, nil
+}
+
+// UsergroupsDisable disables a user group; it can't be mentioned until it's enabled again.
+func (rtm *Client) UsergroupsDisable(userGroupID string) (*UserGroup, error) {
+	return rtm.userGroupUpdate("api/usergroups.disable", userGroupID)
+}
+
+// UsergroupsEnable enables a disabled user group.
+func (rtm *Client) UsergroupsEnable(userGroupID string) (*UserGroup, error) {
+	return rtm.userGroupUpdate("api/usergroups.enable", userGroupID)
+}
+
+// UsergroupsList returns the team's user groups.
+// `includeCount` sets `UserCount` and `includeUsers` sets `Users` on each group.
+func (rtm *Client) UsergroupsList(includeDisabled, includeCount, includeUsers bool) ([]UserGroup, error) {
+	res := userGroupsListResponse{}
+	err := NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/usergroups.list").
+		WithPostData("token", rtm.Token).
+		WithPostData("include_disabled", strconv.FormatBool(includeDisabled)).
+		WithPostData("include_count", strconv.FormatBool(includeCount)).
+		WithPostData("include_users", strconv.FormatBool(includeUsers)).
+		JSON(&res)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsEmpty(res.Error) {
+		return nil, exception.New(res.Error)
+	}
+
+	if !res.OK {
+		return nil, exception.New("slack response `ok` is false.")
+	}
+
+	return res.UserGroups, nil
+}
+
+// UsergroupsUpdate updates a user group; nil values (and nil `channelIDs`) are left unchanged.
+func (rtm *Client) UsergroupsUpdate(userGroupID string, name, handle, description *string, channelIDs []string) (*UserGroup, error) {
+	req := NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/usergroups.update").
+		WithPostData("token", rtm.Token).
+		WithPostData("usergroup", userGroupID)
+
+	if name != nil {
+		req = req.WithPostData("name", *name)
+	}
+	if handle != nil {
+		req = req.WithPostData("handle", *handle)
+	}
+	if description != nil {
+		req = req.WithPostData("description", *description)
+	}
+	if channelIDs != nil {
+		req = req.WithPostData("channels", strings.Join(channelIDs, ","))
+	}
+
+	res := userGroupResponse{}
+	err := req.JSON(&res)
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsEmpty(res.Error) {
+		return nil, exception.New(res.Error)
+	}
+
+	if !res.OK {
+		return nil, exception.New("slack response `ok` is false.")
+	}
+
+	return res.UserGroup, nil
+}
+
+// UsergroupsUsersList returns the ids of the members of a user group.
+func (rtm *Client) UsergroupsUsersList(userGroupID string, includeDisabled bool) ([]string, error) {
+	res := userGroupsUsersListResponse{}
+	err := NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/usergroups.users.list").
+		WithPostData("token", rtm.Token).
+		WithPostData("usergroup", userGroupID).
+		WithPostData("include_disabled", strconv.FormatBool(includeDisabled)).
+		JSON(&res)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsEmpty(res.Error) {
+		return nil, exception.New(res.Error)
+	}
+
+	if !res.OK {
+		return nil, exception.New("slack response `ok` is false.")
+	}
+
+	return res.Users, nil
+}
+
+// UsergroupsUsersUpdate replaces the members of a user group, i.e. to hand off an on-call rotation.
+func (rtm *Client) UsergroupsUsersUpdate(userGroupID string, userIDs []string) (*UserGroup, error) {
+	res := userGroupResponse{}
+	err := NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/usergroups.users.update").
+		WithPostData("token", rtm.Token).
+		WithPostData("usergroup", userGroupID).
+		WithPostData("users", strings.Join(userIDs, ",")).
+		JSON(&res)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsEmpty(res.Error) {
+		return nil, exception.New(res.Error)
+	}
+
+	if !res.OK {
+		return nil, exception.New("slack response `ok` is false.")
+	}
+
+	return res.UserGroup, nil
+}
+
+func (rtm *Client) userGroupUpdate(path, userGroupID string) (*UserGroup, error) {
+	res := userGroupResponse{}
+	err := NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath(path).
+		WithPostData("token", rtm.Token).
+		WithPostData("usergroup", userGroupID).
+		JSON(&res)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsEmpty(res.Error) {
+		return nil, exception.New(res.Error)
+	}
+
+	if !res.OK {
+		return nil, exception.New("slack response `ok` is false.")
+	}
+
+	return res.UserGroup, nil
+}
+
 // UsersList returns all users for a given Slack organization.
 func (rtm *Client) UsersList() ([]User, error) {
-	res := usersListResponse{}
+	res := UsersListResponse{}
 	err := NewExternalRequest().
 		AsPost().
-		WithScheme(APIScheme).
-		WithHost(APIEndpoint).
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
 		WithPath("api/users.list").
 		WithPostData("token", rtm.Token).
 		JSON(&res)
@@ -469,13 +2160,46 @@ func (rtm *Client) UsersList() ([]User, error) {
 	return res.Users, nil
 }
 
+// UsersListPage returns a page of the users in a Slack organization; `cursor` is the `NextCursor` from a previous page.
+func (rtm *Client) UsersListPage(limit int, cursor string) (*UsersListResponse, error) {
+	res := UsersListResponse{}
+	req := NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/users.list").
+		WithPostData("token", rtm.Token)
+
+	if limit > 0 {
+		req = req.WithPostData("limit", strconv.Itoa(limit))
+	}
+	if !IsEmpty(cursor) {
+		req = req.WithPostData("cursor", cursor)
+	}
+
+	err := req.JSON(&res)
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsEmpty(res.Error) {
+		return nil, exception.New(res.Error)
+	}
+
+	if !res.OK {
+		return nil, exception.New("slack response `ok` is false.")
+	}
+
+	return &res, nil
+}
+
 // UsersInfo returns an User object for a given userID.
 func (rtm *Client) UsersInfo(userID string) (*User, error) {
 	res := usersInfoResponse{}
 	err := NewExternalRequest().
 		AsPost().
-		WithScheme(APIScheme).
-		WithHost(APIEndpoint).
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
 		WithPath("api/users.info").
 		WithPostData("token", rtm.Token).
 		WithPostData("user", userID).
@@ -492,13 +2216,280 @@ func (rtm *Client) UsersInfo(userID string) (*User, error) {
 	return res.User, nil
 }
 
+// UsersConversations returns the conversations a user is a member of (the calling user if `userID` is empty).
+// `types` filters by conversation type (`public_channel`, `private_channel`, `mpim`, `im`), and `cursor` is the `NextCursor` from a previous page.
+func (rtm *Client) UsersConversations(userID string, types []string, excludeArchived bool, limit int, cursor string) (*UsersConversationsResponse, error) {
+	res := UsersConversationsResponse{}
+	req := NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/users.conversations").
+		WithPostData("token", rtm.Token).
+		WithPostData("exclude_archived", strconv.FormatBool(excludeArchived))
+
+	if !IsEmpty(userID) {
+		req = req.WithPostData("user", userID)
+	}
+	if len(types) > 0 {
+		req = req.WithPostData("types", strings.Join(types, ","))
+	}
+	if limit > 0 {
+		req = req.WithPostData("limit", strconv.Itoa(limit))
+	}
+	if !IsEmpty(cursor) {
+		req = req.WithPostData("cursor", cursor)
+	}
+
+	err := req.JSON(&res)
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsEmpty(res.Error) {
+		return nil, exception.New(res.Error)
+	}
+
+	if !res.OK {
+		return nil, exception.New("slack response `ok` is false.")
+	}
+
+	return &res, nil
+}
+
+// UsersGetPresence returns a user's presence.
+func (rtm *Client) UsersGetPresence(userID string) (*UserPresence, error) {
+	res := usersGetPresenceResponse{}
+	err := NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/users.getPresence").
+		WithPostData("token", rtm.Token).
+		WithPostData("user", userID).
+		JSON(&res)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsEmpty(res.Error) {
+		return nil, exception.New(res.Error)
+	}
+
+	if !res.OK {
+		return nil, exception.New("slack response `ok` is false.")
+	}
+
+	return &res.UserPresence, nil
+}
+
+// UsersIdentity returns the user and team for a "Sign in with Slack" token.
+func (rtm *Client) UsersIdentity() (*UserIdentity, error) {
+	res := usersIdentityResponse{}
+	err := NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/users.identity").
+		WithPostData("token", rtm.Token).
+		JSON(&res)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsEmpty(res.Error) {
+		return nil, exception.New(res.Error)
+	}
+
+	if !res.OK {
+		return nil, exception.New("slack response `ok` is false.")
+	}
+
+	return &res.UserIdentity, nil
+}
+
+// UsersLookupByEmail returns the user with a given email address.
+func (rtm *Client) UsersLookupByEmail(email string) (*User, error) {
+	res := usersInfoResponse{}
+	err := NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/users.lookupByEmail").
+		WithPostData("token", rtm.Token).
+		WithPostData("email", email).
+		JSON(&res)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsEmpty(res.Error) {
+		return nil, exception.New(res.Error)
+	}
+
+	if !res.OK {
+		return nil, exception.New("slack response `ok` is false.")
+	}
+
+	return res.User, nil
+}
+
+// UsersProfileGet returns a user's profile, including custom fields (the calling user's if `userID` is empty).
+func (rtm *Client) UsersProfileGet(userID string, includeLabels bool) (*UserProfile, error) {
+	res := usersProfileResponse{}
+	req := NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/users.profile.get").
+		WithPostData("token", rtm.Token).
+		WithPostData("include_labels", strconv.FormatBool(includeLabels))
+
+	if !IsEmpty(userID) {
+		req = req.WithPostData("user", userID)
+	}
+
+	err := req.JSON(&res)
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsEmpty(res.Error) {
+		return nil, exception.New(res.Error)
+	}
+
+	if !res.OK {
+		return nil, exception.New("slack response `ok` is false.")
+	}
+
+	return res.Profile, nil
+}
+
+// UsersProfileSet changes fields on a user's profile (the calling user's if `userID` is empty; other users require an admin token).
+// `values` are standard fields by name (i.e. `"status_text": "lunch"`, `"status_expiration": 1532627506`),
+// and `fields` are custom fields keyed by field id; only the given fields are changed.
+func (rtm *Client) UsersProfileSet(userID string, values map[string]interface{}, fields map[string]UserProfileField) (*UserProfile, error) {
+	profile := map[string]interface{}{}
+	for name, value := range values {
+		profile[name] = value
+	}
+	if len(fields) > 0 {
+		profile["fields"] = fields
+	}
+	profileJSON, err := json.Marshal(profile)
+	if err != nil {
+		return nil, exception.Wrap(err)
+	}
+
+	res := usersProfileResponse{}
+	req := NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/users.profile.set").
+		WithPostData("token", rtm.Token).
+		WithPostData("profile", string(profileJSON))
+
+	if !IsEmpty(userID) {
+		req = req.WithPostData("user", userID)
+	}
+
+	err = req.JSON(&res)
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsEmpty(res.Error) {
+		return nil, exception.New(res.Error)
+	}
+
+	if !res.OK {
+		return nil, exception.New("slack response `ok` is false.")
+	}
+
+	return res.Profile, nil
+}
+
+// UsersSetPhoto uploads a new profile photo for the calling user.
+func (rtm *Client) UsersSetPhoto(fileName string, image io.Reader) error {
+	body := bytes.NewBuffer(nil)
+	form := multipart.NewWriter(body)
+	if err := form.WriteField("token", rtm.Token); err != nil {
+		return exception.Wrap(err)
+	}
+	part, err := form.CreateFormFile("image", fileName)
+	if err != nil {
+		return exception.Wrap(err)
+	}
+	if _, err = io.Copy(part, image); err != nil {
+		return exception.Wrap(err)
+	}
+	if err = form.Close(); err != nil {
+		return exception.Wrap(err)
+	}
+
+	res := basicResponse{}
+	err = NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/users.setPhoto").
+		WithHeader("Content-Type", form.FormDataContentType()).
+		WithPostBody(body.Bytes()).
+		JSON(&res)
+
+	if err != nil {
+		return err
+	}
+
+	if !IsEmpty(res.Error) {
+		return exception.New(res.Error)
+	}
+
+	if !res.OK {
+		return exception.New("slack response `ok` is false.")
+	}
+
+	return nil
+}
+
+// UsersSetPresence sets the calling user's presence to `PresenceAuto` or `PresenceAway`.
+func (rtm *Client) UsersSetPresence(presence string) error {
+	res := basicResponse{}
+	err := NewExternalRequest().
+		AsPost().
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
+		WithPath("api/users.setPresence").
+		WithPostData("token", rtm.Token).
+		WithPostData("presence", presence).
+		JSON(&res)
+
+	if err != nil {
+		return err
+	}
+
+	if !IsEmpty(res.Error) {
+		return exception.New(res.Error)
+	}
+
+	if !res.OK {
+		return exception.New("slack response `ok` is false.")
+	}
+
+	return nil
+}
+
 // InviteUser invites a user to a channel.
 func (rtm *Client) InviteUser(channelID, userID string) (*Channel, error) {
 	res := channelsInfoResponse{}
 	err := NewExternalRequest().
 		AsPost().
-		WithScheme(APIScheme).
-		WithHost(APIEndpoint).
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
 		WithPath("api/channels.invite").
 		WithPostData("token", rtm.Token).
 		WithPostData("channel", channelID).
@@ -515,3 +2506,31 @@ func (rtm *Client) InviteUser(channelID, userID string) (*Channel, error) {
 
 	return res.Channel, nil
 }
+
+// InviteUsers invites users to a channel, skipping the calling user and users who are already members.
+// It stops at the first user that can't be invited, returning the channel as of the last invite and the error.
+func (rtm *Client) InviteUsers(channelID string, userIDs ...string) (*Channel, error) {
+	var channel *Channel
+	for _, userID := range userIDs {
+		if self := rtm.state.Self(); self != nil && self.ID == userID {
+			continue
+		}
+		if channel != nil && containsString(channel.Members, userID) {
+			continue
+		}
+
+		invited, err := rtm.InviteUser(channelID, userID)
+		if IsAPIError(err, ErrorAlreadyInChannel) || IsAPIError(err, ErrorCantInviteSelf) {
+			continue
+		}
+		if err != nil {
+			return channel, err
+		}
+		channel = invited
+	}
+
+	if channel == nil {
+		return rtm.ChannelsInfo(channelID)
+	}
+	return channel, nil
+}
@@ -1,8 +1,13 @@
 package slack
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/blendlabs/go-assert"
 	request "github.com/blendlabs/go-request"
@@ -49,6 +54,21 @@ func TestClientChannelsHistory(t *testing.T) {
 	a.NotEmpty(history.Messages)
 }
 
+func TestClientChannelsReplies(t *testing.T) {
+	a := assert.New(t)
+	defer ClearMockedResponses()
+	MockResponseFromFile("POST", "https://slack.com/api/channels.replies", 200, "testdata/channels.replies.json")
+
+	var threadTS Timestamp
+	threadTS.UnmarshalJSON([]byte("1503435956.000247"))
+	messages, err := NewClient(getSlackToken(a)).ChannelsReplies("CTESTCHANNEL", threadTS)
+	a.Nil(err)
+	a.Len(messages, 2)
+	a.Equal(1, messages[0].ReplyCount)
+	a.Equal("UTESTUSER", messages[1].ParentUserID)
+	a.Equal(threadTS.String(), messages[1].ThreadTimestamp.String())
+}
+
 func TestClientChannelsInfo(t *testing.T) {
 	a := assert.New(t)
 	defer ClearMockedResponses()
@@ -62,3 +82,558 @@ func TestClientChannelsInfo(t *testing.T) {
 	a.NotNil(info.Purpose)
 	a.NotNil(info.Topic)
 }
+
+func TestClientChannelsAdmin(t *testing.T) {
+	a := assert.New(t)
+	defer ClearMockedResponses()
+	MockResponseFromFile("POST", "https://slack.com/api/channels.create", 200, "testdata/channels.create.json")
+	MockResponseFromFile("POST", "https://slack.com/api/channels.join", 200, "testdata/channels.create.json")
+	MockResponseFromFile("POST", "https://slack.com/api/channels.invite", 200, "testdata/channels.invite.json")
+	MockResponseFromFile("POST", "https://slack.com/api/channels.rename", 200, "testdata/channels.rename.json")
+	MockResponseFromFile("POST", "https://slack.com/api/channels.kick", 200, "testdata/ok.json")
+	MockResponseFromFile("POST", "https://slack.com/api/channels.archive", 200, "testdata/ok.json")
+	MockResponseFromFile("POST", "https://slack.com/api/channels.unarchive", 200, "testdata/ok.json")
+	MockResponseFromFile("POST", "https://slack.com/api/channels.leave", 200, "testdata/ok.json")
+
+	c := NewClient(getSlackToken(a))
+	channel, err := c.ChannelsCreate("inc-1234")
+	a.Nil(err)
+	a.Equal("CINC1234", channel.ID)
+	a.Equal([]string{"CINC1234"}, c.ActiveChannelIDs())
+	a.Equal("inc-1234", c.State().ChannelName("CINC1234"))
+
+	channel, err = c.ChannelsJoin("inc-1234")
+	a.Nil(err)
+	a.Equal([]string{"CINC1234"}, c.ActiveChannelIDs())
+
+	channel, err = c.InviteUsers(channel.ID, "UTESTUSER", "UOTHERUSER")
+	a.Nil(err)
+	a.Equal([]string{"UTESTUSER", "UOTHERUSER"}, channel.Members)
+
+	channel, err = c.ChannelsRename(channel.ID, "inc-1234-resolved")
+	a.Nil(err)
+	a.Equal("inc-1234-resolved", channel.Name)
+	a.Nil(c.ChannelsKick(channel.ID, "UOTHERUSER"))
+
+	a.Nil(c.ChannelsArchive(channel.ID))
+	a.Empty(c.ActiveChannelIDs())
+	a.Nil(c.ChannelsUnarchive(channel.ID))
+	a.Equal([]string{"CINC1234"}, c.ActiveChannelIDs())
+	a.Nil(c.ChannelsLeave(channel.ID))
+	a.Empty(c.ActiveChannelIDs())
+}
+
+func TestClientChatScheduleMessage(t *testing.T) {
+	a := assert.New(t)
+	defer ClearMockedResponses()
+	MockResponseFromFile("POST", "https://slack.com/api/chat.scheduleMessage", 200, "testdata/chat.scheduleMessage.json")
+
+	c := NewClient(getSlackToken(a))
+	res, err := c.ChatScheduleMessage(time.Now().Add(time.Hour), NewChatMessage("CTESTCHANNEL", "standup time!"))
+	a.Nil(err)
+	a.Equal("Q1298393284", res.ScheduledMessageID)
+	a.Equal(int64(1562180400), res.PostAt.Time().Unix())
+}
+
+func TestClientChatScheduledMessagesList(t *testing.T) {
+	a := assert.New(t)
+	defer ClearMockedResponses()
+	MockResponseFromFile("POST", "https://slack.com/api/chat.scheduledMessages.list", 200, "testdata/chat.scheduledMessages.list.json")
+
+	c := NewClient(getSlackToken(a))
+	res, err := c.ChatScheduledMessagesList("CTESTCHANNEL", nil, nil, 0, "")
+	a.Nil(err)
+	a.Len(res.ScheduledMessages, 1)
+	a.Equal("CTESTCHANNEL", res.ScheduledMessages[0].ChannelID)
+	a.Equal(int64(1562180400), res.ScheduledMessages[0].PostAt.Time().Unix())
+	a.Empty(res.ResponseMetadata.NextCursor)
+}
+
+func TestClientChatPostEphemeral(t *testing.T) {
+	a := assert.New(t)
+	defer ClearMockedResponses()
+	MockResponseFromFile("POST", "https://slack.com/api/chat.postEphemeral", 200, "testdata/chat.postEphemeral.json")
+
+	c := NewClient(getSlackToken(a))
+	res, err := c.ChatPostEphemeral("UTESTUSER", NewChatMessage("CTESTCHANNEL", "only you can see this"))
+	a.Nil(err)
+	a.Equal("1502210682.580145", res.MessageTimestamp.String())
+
+	var ts Timestamp
+	a.Nil(ts.UnmarshalJSON([]byte(`"1456528852.000005"`)))
+	a.Nil(c.ReplyEphemeral(&Message{Channel: "CTESTCHANNEL", User: "UTESTUSER", ThreadTimestamp: &ts}, "only you can see this"))
+}
+
+func TestClientChatGetPermalink(t *testing.T) {
+	a := assert.New(t)
+	defer ClearMockedResponses()
+	MockResponseFromFile("POST", "https://slack.com/api/chat.getPermalink", 200, "testdata/chat.getPermalink.json")
+
+	c := NewClient(getSlackToken(a))
+	var ts Timestamp
+	a.Nil(ts.UnmarshalJSON([]byte(`"1456528852.000005"`)))
+	permalink, err := c.ChatGetPermalink("CTESTCHANNEL", ts)
+	a.Nil(err)
+	a.Equal("https://test.slack.com/archives/CTESTCHANNEL/p1456528852000005", permalink)
+}
+
+func TestClientUsersList(t *testing.T) {
+	a := assert.New(t)
+	defer ClearMockedResponses()
+	MockResponseFromFile("POST", "https://slack.com/api/users.list", 200, "testdata/users.list.json")
+
+	c := NewClient(getSlackToken(a))
+	users, err := c.UsersList()
+	a.Nil(err)
+	a.Len(users, 2)
+	a.Equal("Test User", users[0].Profile.RealName)
+	a.True(users[1].IsBot)
+}
+func TestClientUsersInfo(t *testing.T) {
+	a := assert.New(t)
+	defer ClearMockedResponses()
+	MockResponseFromFile("POST", "https://slack.com/api/users.info", 200, "testdata/users.info.json")
+
+	c := NewClient(getSlackToken(a))
+	user, err := c.UsersInfo("UTESTUSER")
+	a.Nil(err)
+	a.NotNil(user)
+	a.Equal("test", user.Name)
+	a.Equal("America/Los_Angeles", user.TZ)
+	a.Equal(-25200, user.TZOffset)
+	a.Equal("tester", user.Profile.DisplayName)
+	a.Equal(":mountain_railway:", user.Profile.StatusEmoji)
+	a.Equal(int64(1532627506), user.Profile.StatusExpiration)
+	a.NotNil(user.Profile.Fields)
+	a.Empty(user.Profile.Fields)
+	a.Equal(int64(1502138686), user.Updated.Time().Unix())
+}
+
+func TestUserProfileUnmarshalFields(t *testing.T) {
+	a := assert.New(t)
+	var profile UserProfile
+	a.Nil(json.Unmarshal([]byte(`{"real_name":"Test User","fields":null}`), &profile))
+	a.Equal("Test User", profile.RealName)
+	a.Empty(profile.Fields)
+	a.Nil(json.Unmarshal([]byte(`{"fields":{"Xf06054AAA":{"value":"Engineering"}}}`), &profile))
+	a.Equal("Engineering", profile.Fields["Xf06054AAA"].Value)
+	a.NotNil(json.Unmarshal([]byte(`{"fields":"none"}`), &profile))
+}
+
+func TestClientUsersLookupByEmail(t *testing.T) {
+	a := assert.New(t)
+	defer ClearMockedResponses()
+	MockResponseFromFile("POST", "https://slack.com/api/users.lookupByEmail", 200, "testdata/users.lookupByEmail.json")
+
+	c := NewClient(getSlackToken(a))
+	user, err := c.UsersLookupByEmail("test@example.com")
+	a.Nil(err)
+	a.Equal("UTESTUSER", user.ID)
+	a.Equal("TTESTTEAM", user.TeamID)
+}
+
+func TestClientUsersPresence(t *testing.T) {
+	a := assert.New(t)
+	defer ClearMockedResponses()
+	MockResponseFromFile("POST", "https://slack.com/api/users.getPresence", 200, "testdata/users.getPresence.json")
+	MockResponseFromFile("POST", "https://slack.com/api/users.setPresence", 200, "testdata/ok.json")
+
+	c := NewClient(getSlackToken(a))
+	presence, err := c.UsersGetPresence("UTESTUSER")
+	a.Nil(err)
+	a.Equal(PresenceActive, presence.Presence)
+	a.True(presence.Online)
+	a.Equal(1, presence.ConnectionCount)
+	a.Nil(c.UsersSetPresence(PresenceAway))
+}
+
+func TestClientUsersProfile(t *testing.T) {
+	a := assert.New(t)
+	defer ClearMockedResponses()
+	MockResponseFromFile("POST", "https://slack.com/api/users.profile.get", 200, "testdata/users.profile.get.json")
+	MockResponseFromFile("POST", "https://slack.com/api/users.profile.set", 200, "testdata/users.profile.set.json")
+
+	c := NewClient(getSlackToken(a))
+	profile, err := c.UsersProfileGet("", false)
+	a.Nil(err)
+	a.Equal("Engineering", profile.Fields["Xf06054AAA"].Value)
+	a.Equal("test", profile.Fields["Xf06054BBB"].Alt)
+
+	profile, err = c.UsersProfileSet("", map[string]interface{}{"status_text": "lunch", "status_emoji": ":taco:"}, map[string]UserProfileField{"Xf06054AAA": {Value: "Platform"}})
+	a.Nil(err)
+	a.Equal("lunch", profile.StatusText)
+	a.Equal("Platform", profile.Fields["Xf06054AAA"].Value)
+}
+
+func TestClientUsersConversations(t *testing.T) {
+	a := assert.New(t)
+	defer ClearMockedResponses()
+	MockResponseFromFile("POST", "https://slack.com/api/users.conversations", 200, "testdata/users.conversations.json")
+
+	c := NewClient(getSlackToken(a))
+	res, err := c.UsersConversations("", []string{"public_channel", "private_channel"}, true, 100, "")
+	a.Nil(err)
+	a.Len(res.Channels, 1)
+	a.Equal("general", res.Channels[0].Name)
+	a.Equal("aW5uZXJfY3Vyc29y", res.ResponseMetadata.NextCursor)
+}
+
+func TestClientUsersIdentity(t *testing.T) {
+	a := assert.New(t)
+	defer ClearMockedResponses()
+	MockResponseFromFile("POST", "https://slack.com/api/users.identity", 200, "testdata/users.identity.json")
+
+	c := NewClient(getSlackToken(a))
+	identity, err := c.UsersIdentity()
+	a.Nil(err)
+	a.Equal("UTESTUSER", identity.User.ID)
+	a.Equal("test@example.com", identity.User.Email)
+	a.Equal("test", identity.Team.Domain)
+}
+
+func TestClientUsersSetPhoto(t *testing.T) {
+	a := assert.New(t)
+	defer ClearMockedResponses()
+	MockResponseFromFile("POST", "https://slack.com/api/users.setPhoto", 200, "testdata/ok.json")
+
+	c := NewClient(getSlackToken(a))
+	a.Nil(c.UsersSetPhoto("avatar.png", strings.NewReader("not really a png")))
+}
+
+func TestClientUsersSetPhotoToken(t *testing.T) {
+	a := assert.New(t)
+	var query, token string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		r.ParseMultipartForm(1 << 20)
+		token = r.PostFormValue("token")
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	c := NewClient("xoxb-secret")
+	c.SetAPIEndpoint("http", strings.TrimPrefix(server.URL, "http://"))
+	a.Nil(c.UsersSetPhoto("avatar.png", strings.NewReader("not really a png")))
+	a.Empty(query)
+	a.Equal("xoxb-secret", token)
+}
+
+func TestClientFilesUpload(t *testing.T) {
+	a := assert.New(t)
+	defer ClearMockedResponses()
+	MockResponseFromFile("POST", "https://slack.com/api/files.upload", 200, "testdata/files.upload.json")
+
+	c := NewClient(getSlackToken(a))
+	file, err := c.FilesUpload(&FileUpload{Channels: []string{"CTESTCHANNEL"}, Filename: "report.csv"}, strings.NewReader("a,b\n1,2\n"))
+	a.Nil(err)
+	a.Equal("FTESTFILE", file.ID)
+	a.Equal([]string{"CTESTCHANNEL"}, file.Channels)
+}
+
+func TestClientFilesUploadToken(t *testing.T) {
+	a := assert.New(t)
+	var query, token string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		r.ParseMultipartForm(1 << 20)
+		token = r.PostFormValue("token")
+		w.Write([]byte(`{"ok":true,"file":{"id":"FTESTFILE"}}`))
+	}))
+	defer server.Close()
+
+	c := NewClient("xoxb-secret")
+	c.SetAPIEndpoint("http", strings.TrimPrefix(server.URL, "http://"))
+	_, err := c.FilesUpload(&FileUpload{Filename: "report.csv"}, strings.NewReader("a,b\n"))
+	a.Nil(err)
+	a.Empty(query)
+	a.Equal("xoxb-secret", token)
+}
+
+func TestClientPins(t *testing.T) {
+	a := assert.New(t)
+	defer ClearMockedResponses()
+	MockResponseFromFile("POST", "https://slack.com/api/pins.list", 200, "testdata/pins.list.json")
+	MockResponseFromFile("POST", "https://slack.com/api/pins.add", 200, "testdata/ok.json")
+	MockResponseFromFile("POST", "https://slack.com/api/pins.remove", 200, "testdata/ok.json")
+
+	c := NewClient(getSlackToken(a))
+	items, err := c.PinsList("CTESTCHANNEL")
+	a.Nil(err)
+	a.Len(items, 2)
+	a.Equal(ItemTypeMessage, items[0].Type)
+	a.Equal("the runbook is here", items[0].Message.Text)
+	a.Equal("UTESTUSER", items[0].CreatedBy)
+	a.Equal(ItemTypeFile, items[1].Type)
+	a.Equal("FTESTFILE", items[1].File.ID)
+
+	a.Nil(c.PinsAdd("CTESTCHANNEL", items[0].Ref()))
+	a.Nil(c.PinsRemove("CTESTCHANNEL", FileRef("FTESTFILE")))
+	a.NotNil(c.PinsAdd("CTESTCHANNEL", ItemRef{}))
+}
+
+func TestClientStars(t *testing.T) {
+	a := assert.New(t)
+	defer ClearMockedResponses()
+	MockResponseFromFile("POST", "https://slack.com/api/stars.list", 200, "testdata/stars.list.json")
+	MockResponseFromFile("POST", "https://slack.com/api/stars.add", 200, "testdata/ok.json")
+	MockResponseFromFile("POST", "https://slack.com/api/stars.remove", 200, "testdata/ok.json")
+
+	c := NewClient(getSlackToken(a))
+	res, err := c.StarsList(100, "")
+	a.Nil(err)
+	a.Len(res.Items, 3)
+	a.Equal(ItemTypeChannel, res.Items[1].Type)
+	a.Equal("CTESTCHANNEL", res.Items[1].Channel)
+	a.Equal(ItemTypeFileComment, res.Items[2].Type)
+	a.Equal("nice", res.Items[2].Comment.Comment)
+	a.Equal(3, res.Paging.Total)
+
+	a.Nil(c.StarsAdd(ChannelRef("CTESTCHANNEL")))
+	a.Nil(c.StarsRemove(FileCommentRef("FcTESTCOMMENT")))
+	a.NotNil(c.StarsAdd(ItemRef{}))
+}
+
+func TestClientReactions(t *testing.T) {
+	a := assert.New(t)
+	defer ClearMockedResponses()
+	MockResponseFromFile("POST", "https://slack.com/api/reactions.get", 200, "testdata/reactions.get.json")
+	MockResponseFromFile("POST", "https://slack.com/api/reactions.list", 200, "testdata/reactions.list.json")
+	MockResponseFromFile("POST", "https://slack.com/api/reactions.remove", 200, "testdata/ok.json")
+
+	c := NewClient(getSlackToken(a))
+	res, err := c.ReactionsGet(MessageRef("CTESTCHANNEL", NewTimestamp(time.Unix(1503435956, 0))), true)
+	a.Nil(err)
+	a.Equal(ItemTypeMessage, res.Type)
+	a.Equal("CTESTCHANNEL", res.Channel)
+	a.Len(res.Reactions(), 1)
+	a.Equal("thumbsup", res.Reactions()[0].Name)
+	a.Equal(2, res.Reactions()[0].Count)
+
+	list, err := c.ReactionsList("", false, 2, "")
+	a.Nil(err)
+	a.Len(list.Items, 2)
+	a.Equal(ItemTypeFile, list.Items[1].Type)
+	a.Equal("FTESTFILE", list.Items[1].Ref().File)
+	a.Equal("dGVhbTpDMDYxRkE1UEI=", list.ResponseMetadata.NextCursor)
+
+	a.Nil(c.ReactionsRemove("thumbsup", FileRef("FTESTFILE")))
+	a.NotNil(c.ReactionsRemove("thumbsup", ItemRef{}))
+}
+
+func TestClientReminders(t *testing.T) {
+	a := assert.New(t)
+	defer ClearMockedResponses()
+	MockResponseFromFile("POST", "https://slack.com/api/reminders.add", 200, "testdata/reminders.add.json")
+	MockResponseFromFile("POST", "https://slack.com/api/reminders.info", 200, "testdata/reminders.info.json")
+	MockResponseFromFile("POST", "https://slack.com/api/reminders.list", 200, "testdata/reminders.list.json")
+	MockResponseFromFile("POST", "https://slack.com/api/reminders.complete", 200, "testdata/ok.json")
+
+	c := NewClient(getSlackToken(a))
+	reminder, err := c.RemindersAddAt("hand off on-call", time.Unix(1602288000, 0), "", nil)
+	a.Nil(err)
+	a.Equal("RmTESTREMINDER", reminder.ID)
+	a.Equal(time.Unix(1602288000, 0), reminder.Time.Time())
+	a.False(reminder.IsComplete())
+
+	a.Nil(c.RemindersComplete(reminder.ID))
+	reminder, err = c.RemindersInfo(reminder.ID)
+	a.Nil(err)
+	a.True(reminder.IsComplete())
+
+	reminders, err := c.RemindersList()
+	a.Nil(err)
+	a.Len(reminders, 2)
+	a.True(reminders[1].Recurring)
+	a.Nil(reminders[1].Time)
+	a.Equal(RecurrenceWeekly, reminders[1].Recurrence.Frequency)
+	a.Equal([]string{"monday"}, reminders[1].Recurrence.Weekdays)
+}
+
+func TestClientUsergroups(t *testing.T) {
+	a := assert.New(t)
+	defer ClearMockedResponses()
+	MockResponseFromFile("POST", "https://slack.com/api/usergroups.create", 200, "testdata/usergroups.create.json")
+	MockResponseFromFile("POST", "https://slack.com/api/usergroups.list", 200, "testdata/usergroups.list.json")
+	MockResponseFromFile("POST", "https://slack.com/api/usergroups.users.list", 200, "testdata/usergroups.users.list.json")
+	MockResponseFromFile("POST", "https://slack.com/api/usergroups.disable", 200, "testdata/usergroups.create.json")
+
+	c := NewClient(getSlackToken(a))
+	userGroup, err := c.UsergroupsCreate("On-call", "oncall", "Whoever is on call this week", []string{"CTESTCHANNEL"})
+	a.Nil(err)
+	a.Equal("STESTONCALL", userGroup.ID)
+	a.Equal([]string{"CTESTCHANNEL"}, userGroup.Prefs.Channels)
+
+	userGroups, err := c.UsergroupsList(true, true, true)
+	a.Nil(err)
+	a.Len(userGroups, 2)
+	a.True(userGroups[0].IsEnabled())
+	a.Equal("1", userGroups[0].UserCount.String())
+	a.Equal([]string{"UTESTUSER"}, userGroups[0].Users)
+	a.False(userGroups[1].IsEnabled())
+	a.Equal("0", userGroups[1].UserCount.String())
+
+	users, err := c.UsergroupsUsersList("STESTONCALL", false)
+	a.Nil(err)
+	a.Equal([]string{"UTESTUSER", "UOTHERUSER"}, users)
+
+	_, err = c.UsergroupsDisable("STESTONCALL")
+	a.Nil(err)
+}
+
+func TestClientTeam(t *testing.T) {
+	a := assert.New(t)
+	defer ClearMockedResponses()
+	MockResponseFromFile("POST", "https://slack.com/api/team.info", 200, "testdata/team.info.json")
+	MockResponseFromFile("POST", "https://slack.com/api/team.accessLogs", 200, "testdata/team.accessLogs.json")
+	MockResponseFromFile("POST", "https://slack.com/api/team.billableInfo", 200, "testdata/team.billableInfo.json")
+	MockResponseFromFile("POST", "https://slack.com/api/team.profile.get", 200, "testdata/team.profile.get.json")
+	MockResponseFromFile("POST", "https://slack.com/api/bots.info", 200, "testdata/bots.info.json")
+
+	c := NewClient(getSlackToken(a))
+	team, err := c.TeamInfo()
+	a.Nil(err)
+	a.Equal("testteam", team.Domain)
+	a.Equal("https://example.com/team_34.png", team.Icon.Image34)
+
+	logs, err := c.TeamAccessLogs(nil, 100, 1)
+	a.Nil(err)
+	a.Len(logs.Logins, 2)
+	a.Equal("SlackDesktop", logs.Logins[1].UserAgent)
+	a.Equal(time.Unix(1422922864, 0), logs.Logins[0].DateFirst.Time())
+	a.Equal(2, logs.Paging.Total)
+
+	billable, err := c.TeamBillableInfo("")
+	a.Nil(err)
+	a.True(billable["UTESTUSER"].BillingActive)
+	a.False(billable["UOTHERUSER"].BillingActive)
+
+	profile, err := c.TeamProfileGet("all")
+	a.Nil(err)
+	a.Len(profile.Fields, 2)
+	a.Equal([]string{"Platform", "Growth"}, profile.Fields[1].PossibleValues)
+	a.True(profile.Fields[1].IsHidden)
+
+	bot, err := c.BotsInfo("BTESTBOT")
+	a.Nil(err)
+	a.Equal("deploybot", bot.Name)
+	a.Equal("UTESTBOT", bot.UserID)
+	a.Equal("https://example.com/bot_48.png", bot.Icons.Image48)
+}
+
+func TestClientDnd(t *testing.T) {
+	a := assert.New(t)
+	defer ClearMockedResponses()
+	MockResponseFromFile("POST", "https://slack.com/api/dnd.info", 200, "testdata/dnd.info.json")
+	MockResponseFromFile("POST", "https://slack.com/api/dnd.setSnooze", 200, "testdata/dnd.setSnooze.json")
+	MockResponseFromFile("POST", "https://slack.com/api/dnd.teamInfo", 200, "testdata/dnd.teamInfo.json")
+	MockResponseFromFile("POST", "https://slack.com/api/dnd.endDnd", 200, "testdata/ok.json")
+
+	c := NewClient(getSlackToken(a))
+	_, hasDND := c.State().SelfDND()
+	a.False(hasDND)
+
+	status, err := c.DndInfo("")
+	a.Nil(err)
+	a.True(status.DNDEnabled)
+	a.Equal(1196, status.SnoozeRemaining)
+	a.True(status.IsActive(time.Unix(1450373000, 0)))
+	a.False(status.IsActive(time.Unix(1450400000, 0)))
+	a.True(status.IsActive(time.Unix(1450420000, 0)))
+
+	status, err = c.DndSetSnooze(1)
+	a.Nil(err)
+	a.Equal(60, status.SnoozeRemaining)
+	cached, hasDND := c.State().SelfDND()
+	a.True(hasDND)
+	a.True(cached.DNDEnabled)
+	a.Equal(60, cached.SnoozeRemaining)
+
+	users, err := c.DndTeamInfo([]string{"UTESTUSER", "UOTHERUSER"})
+	a.Nil(err)
+	a.Len(users, 2)
+	a.True(users["UTESTUSER"].DNDEnabled)
+	cached, hasDND = c.State().DND("UOTHERUSER")
+	a.True(hasDND)
+	a.False(cached.DNDEnabled)
+
+	a.Nil(c.DndEndDnd())
+}
+
+func TestClientConversations(t *testing.T) {
+	a := assert.New(t)
+	defer ClearMockedResponses()
+	MockResponseFromFile("POST", "https://slack.com/api/im.open", 200, "testdata/im.open.json")
+	MockResponseFromFile("POST", "https://slack.com/api/im.list", 200, "testdata/im.list.json")
+	MockResponseFromFile("POST", "https://slack.com/api/im.history", 200, "testdata/channels.history.json")
+	MockResponseFromFile("POST", "https://slack.com/api/chat.postMessage", 200, "testdata/chat.postMessage.json")
+	MockResponseFromFile("POST", "https://slack.com/api/groups.create", 200, "testdata/groups.create.json")
+	MockResponseFromFile("POST", "https://slack.com/api/groups.list", 200, "testdata/groups.list.json")
+	MockResponseFromFile("POST", "https://slack.com/api/groups.kick", 200, "testdata/ok.json")
+	MockResponseFromFile("POST", "https://slack.com/api/mpim.open", 200, "testdata/mpim.open.json")
+
+	c := NewClient(getSlackToken(a))
+	im, err := c.ImOpen("UTESTUSER")
+	a.Nil(err)
+	a.Equal("D069C7QFK", im.ID)
+	a.True(im.IsOpen)
+	cached, hasIM := c.State().IMForUser("UTESTUSER")
+	a.True(hasIM)
+	a.Equal(im.ID, cached.ID)
+	a.Nil(c.DirectMessage("UTESTUSER", "standup time!"))
+	a.NotNil(c.DirectMessage(""))
+
+	ims, err := c.ImList()
+	a.Nil(err)
+	a.Len(ims, 2)
+	a.Equal("USLACKBOT", ims[0].User)
+
+	history, err := c.ImHistory(im.ID, nil, nil, 10, false)
+	a.Nil(err)
+	a.NotEmpty(history.Messages)
+
+	group, err := c.GroupsCreate("secretplans")
+	a.Nil(err)
+	a.Equal("G024BE91L", group.ID)
+	a.True(group.IsGroup)
+	groups, err := c.GroupsList(true)
+	a.Nil(err)
+	a.Len(groups, 1)
+	a.Equal("Secret plans on hold", groups[0].Topic.Value)
+	a.Nil(c.GroupsKick(group.ID, "UOTHERUSER"))
+
+	mpim, err := c.MpimOpen([]string{"UTESTUSER", "UOTHERUSER"})
+	a.Nil(err)
+	a.True(mpim.IsMPIM)
+	a.Len(mpim.Members, 3)
+}
+
+func TestClientSearch(t *testing.T) {
+	a := assert.New(t)
+	defer ClearMockedResponses()
+	MockResponseFromFile("POST", "https://slack.com/api/search.messages", 200, "testdata/search.messages.json")
+	MockResponseFromFile("POST", "https://slack.com/api/search.files", 200, "testdata/search.files.json")
+
+	c := NewClient(getSlackToken(a))
+	res, err := c.SearchMessages(NewSearchQuery("outage").In("incidents").String(), &SearchParameters{Sort: SearchSortTimestamp})
+	a.Nil(err)
+	a.Equal(1, res.Messages.Total)
+	match := res.Messages.Matches[0]
+	a.Equal("incidents", match.Channel.Name)
+	a.Equal("https://testteam.slack.com/archives/CTESTCHANNEL/p1503435956000247", match.Permalink)
+	a.Len(match.ContextBefore(), 2)
+	a.Equal("pages are firing", match.ContextBefore()[0].Text)
+	a.Len(match.ContextAfter(), 1)
+	a.Equal("thanks", match.ContextAfter()[0].Text)
+
+	var matches []SearchMessage
+	a.Nil(c.SearchMessagesEach("outage", nil, func(m SearchMessage) bool {
+		matches = append(matches, m)
+		return true
+	}))
+	a.Len(matches, 1)
+
+	files, err := c.SearchFiles("runbook", nil)
+	a.Nil(err)
+	a.Len(files.Files.Matches, 1)
+	a.Equal("DB runbook", files.Files.Matches[0].Title)
+}
@@ -0,0 +1,244 @@
+package bot
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	exception "github.com/blendlabs/go-exception"
+)
+
+// ParameterType is the type of a command template parameter.
+type ParameterType string
+
+// Parameter types
+const (
+	// ParameterString matches a single word (or a quoted phrase).
+	ParameterString ParameterType = "string"
+	// ParameterInt matches a single integer.
+	ParameterInt ParameterType = "int"
+	// ParameterFloat matches a single floating point number.
+	ParameterFloat ParameterType = "float"
+	// ParameterBool matches `true`, `false`, `yes`, `no`, `on`, `off`, `1` or `0`.
+	ParameterBool ParameterType = "bool"
+	// ParameterUser matches a user mention, i.e. `<@U0KMCE0MC>`, and yields the user id.
+	ParameterUser ParameterType = "user"
+	// ParameterChannel matches a channel mention, i.e. `<#C0KMCE0MC|general>`, and yields the channel id.
+	ParameterChannel ParameterType = "channel"
+	// ParameterRest matches the remainder of the message; it must be the last token in a template.
+	ParameterRest ParameterType = "rest"
+)
+
+var (
+	userMentionExpr    = regexp.MustCompile(`^<@([A-Z0-9]+)(\|[^>]*)?>$`)
+	channelMentionExpr = regexp.MustCompile(`^<#([A-Z0-9]+)(\|[^>]*)?>$`)
+)
+
+// Handler is a function that handles a command.
+type Handler func(ctx *Context) error
+
+// Command is a registered command.
+type Command struct {
+	// Usage is how the command is displayed in help output.
+	Usage string
+	// Description is what the command does.
+	Description string
+	// Handler is the function called when the command matches.
+	Handler Handler
+
+	tokens []token
+	expr   *regexp.Regexp
+}
+
+// token is a component of a command template; either a literal word or a typed parameter.
+type token struct {
+	literal       string
+	name          string
+	parameterType ParameterType
+}
+
+func (t token) isParameter() bool {
+	return len(t.name) > 0
+}
+
+// parseTemplate parses a template like `deploy <service> to <env>` or `scale <service> <count:int>`.
+func parseTemplate(template string) ([]token, error) {
+	words := strings.Fields(template)
+	if len(words) == 0 {
+		return nil, exception.New("command template cannot be empty.")
+	}
+
+	var tokens []token
+	for index, word := range words {
+		if !strings.HasPrefix(word, "<") || !strings.HasSuffix(word, ">") {
+			tokens = append(tokens, token{literal: strings.ToLower(word)})
+			continue
+		}
+
+		name := strings.TrimSuffix(strings.TrimPrefix(word, "<"), ">")
+		parameterType := ParameterString
+		if pieces := strings.SplitN(name, ":", 2); len(pieces) == 2 {
+			name = pieces[0]
+			parameterType = ParameterType(pieces[1])
+		}
+		if len(name) == 0 {
+			return nil, exception.Newf("invalid parameter `%s` in template `%s`.", word, template)
+		}
+
+		switch parameterType {
+		case ParameterString, ParameterInt, ParameterFloat, ParameterBool, ParameterUser, ParameterChannel:
+		case ParameterRest:
+			if index != len(words)-1 {
+				return nil, exception.Newf("`rest` parameter `%s` must be last in template `%s`.", name, template)
+			}
+		default:
+			return nil, exception.Newf("unknown parameter type `%s` in template `%s`.", parameterType, template)
+		}
+		tokens = append(tokens, token{name: name, parameterType: parameterType})
+	}
+	return tokens, nil
+}
+
+// match tests the command against the (already de-addressed) message text.
+func (c *Command) match(text string) (Args, bool) {
+	if c.expr != nil {
+		return c.matchExpr(text)
+	}
+	return c.matchTemplate(text)
+}
+
+func (c *Command) matchExpr(text string) (Args, bool) {
+	submatches := c.expr.FindStringSubmatch(text)
+	if submatches == nil {
+		return nil, false
+	}
+
+	args := Args{}
+	for index, name := range c.expr.SubexpNames() {
+		if index == 0 {
+			continue
+		}
+		if len(name) == 0 {
+			name = strconv.Itoa(index)
+		}
+		args[name] = submatches[index]
+	}
+	return args, true
+}
+
+func (c *Command) matchTemplate(text string) (Args, bool) {
+	words, ends := splitWords(text)
+	args := Args{}
+
+	for index, t := range c.tokens {
+		if t.parameterType == ParameterRest {
+			rest := remainder(text, ends, index)
+			if len(rest) == 0 {
+				return nil, false
+			}
+			args[t.name] = rest
+			return args, true
+		}
+
+		if index >= len(words) {
+			return nil, false
+		}
+		word := words[index]
+
+		if !t.isParameter() {
+			if !strings.EqualFold(word, t.literal) {
+				return nil, false
+			}
+			continue
+		}
+
+		value, err := parseParameter(t.parameterType, word)
+		if err != nil {
+			return nil, false
+		}
+		args[t.name] = value
+	}
+
+	if len(words) != len(c.tokens) {
+		return nil, false
+	}
+	return args, true
+}
+
+func parseParameter(parameterType ParameterType, word string) (interface{}, error) {
+	switch parameterType {
+	case ParameterInt:
+		return strconv.Atoi(word)
+	case ParameterFloat:
+		return strconv.ParseFloat(word, 64)
+	case ParameterBool:
+		switch strings.ToLower(word) {
+		case "true", "yes", "on", "1":
+			return true, nil
+		case "false", "no", "off", "0":
+			return false, nil
+		}
+		return nil, exception.Newf("`%s` is not a bool.", word)
+	case ParameterUser:
+		if submatches := userMentionExpr.FindStringSubmatch(word); submatches != nil {
+			return submatches[1], nil
+		}
+		return nil, exception.Newf("`%s` is not a user mention.", word)
+	case ParameterChannel:
+		if submatches := channelMentionExpr.FindStringSubmatch(word); submatches != nil {
+			return submatches[1], nil
+		}
+		return nil, exception.Newf("`%s` is not a channel mention.", word)
+	default:
+		return word, nil
+	}
+}
+
+// splitWords splits text on whitespace, keeping double quoted phrases together.
+// It also returns the offset in text just past each word.
+func splitWords(text string) (words []string, ends []int) {
+	var current []rune
+	inQuotes := false
+	hasWord := false
+	for offset, r := range text {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			hasWord = true
+		case !inQuotes && (r == ' ' || r == '\t' || r == '\n'):
+			if hasWord {
+				words = append(words, string(current))
+				ends = append(ends, offset)
+			}
+			current = nil
+			hasWord = false
+		default:
+			current = append(current, r)
+			hasWord = true
+		}
+	}
+	if hasWord {
+		words = append(words, string(current))
+		ends = append(ends, len(text))
+	}
+	return words, ends
+}
+
+// remainder returns the text after the first skip words, using the word ends from splitWords.
+func remainder(text string, ends []int, skip int) string {
+	if skip == 0 {
+		return strings.TrimSpace(text)
+	}
+	if skip > len(ends) {
+		return ""
+	}
+	return strings.TrimSpace(text[ends[skip-1]:])
+}
+
+func (c *Command) String() string {
+	if len(c.Description) == 0 {
+		return fmt.Sprintf("`%s`", c.Usage)
+	}
+	return fmt.Sprintf("`%s` - %s", c.Usage, c.Description)
+}
@@ -0,0 +1,88 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/blendlabs/go-assert"
+)
+
+func TestParseTemplate(t *testing.T) {
+	a := assert.New(t)
+
+	tokens, err := parseTemplate("deploy <service> to <env>")
+	a.Nil(err)
+	a.Len(tokens, 4)
+	a.Equal("deploy", tokens[0].literal)
+	a.Equal("service", tokens[1].name)
+	a.Equal(ParameterString, tokens[1].parameterType)
+
+	_, err = parseTemplate("")
+	a.NotNil(err)
+	_, err = parseTemplate("scale <count:number>")
+	a.NotNil(err)
+	_, err = parseTemplate("echo <text:rest> please")
+	a.NotNil(err)
+}
+
+func TestCommandMatchTemplate(t *testing.T) {
+	a := assert.New(t)
+
+	tokens, err := parseTemplate("scale <service> to <count:int> <canary:bool>")
+	a.Nil(err)
+	c := &Command{tokens: tokens}
+
+	args, matched := c.match("Scale api to 3 yes")
+	a.True(matched)
+	a.Equal("api", args.String("service"))
+	a.Equal(3, args.Int("count"))
+	a.True(args.Bool("canary"))
+
+	_, matched = c.match("scale api to three yes")
+	a.False(matched)
+	_, matched = c.match("scale api to 3 yes please")
+	a.False(matched)
+	_, matched = c.match("scale api to 3")
+	a.False(matched)
+}
+
+func TestCommandMatchTypes(t *testing.T) {
+	a := assert.New(t)
+
+	tokens, err := parseTemplate("page <who:user> in <where:channel> <message:rest>")
+	a.Nil(err)
+	c := &Command{tokens: tokens}
+
+	args, matched := c.match(`page <@U0KMCE0MC> in <#C024BE7LR|general> the site is "down"`)
+	a.True(matched)
+	a.Equal("U0KMCE0MC", args.String("who"))
+	a.Equal("C024BE7LR", args.String("where"))
+	a.Equal(`the site is "down"`, args.String("message"))
+
+	_, matched = c.match("page will in general the site is down")
+	a.False(matched)
+}
+
+func TestSplitWords(t *testing.T) {
+	a := assert.New(t)
+	words, ends := splitWords(`echo "hello world"  again`)
+	a.Equal([]string{"echo", "hello world", "again"}, words)
+	a.Equal([]int{4, 18, 25}, ends)
+	words, _ = splitWords(`echo ""`)
+	a.Equal([]string{"echo", ""}, words)
+}
+
+func TestCommandMatchQuotedBeforeRest(t *testing.T) {
+	a := assert.New(t)
+
+	tokens, err := parseTemplate("say <who> <msg:rest>")
+	a.Nil(err)
+	c := &Command{tokens: tokens}
+
+	args, matched := c.match(`say "john doe" hi`)
+	a.True(matched)
+	a.Equal("john doe", args.String("who"))
+	a.Equal("hi", args.String("msg"))
+
+	_, matched = c.match(`say "john doe"`)
+	a.False(matched)
+}
@@ -0,0 +1,172 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	slack "github.com/wcharczuk/go-slack"
+)
+
+// Args are the parsed parameters of a matched command, keyed by parameter name.
+// Values are typed according to the template, i.e. `<count:int>` yields an `int`.
+type Args map[string]interface{}
+
+// String returns a parameter as a string.
+func (a Args) String(name string) string {
+	if value, hasValue := a[name]; hasValue {
+		if typed, isTyped := value.(string); isTyped {
+			return typed
+		}
+		return fmt.Sprint(value)
+	}
+	return ""
+}
+
+// Int returns an `int` parameter.
+func (a Args) Int(name string) int {
+	if typed, isTyped := a[name].(int); isTyped {
+		return typed
+	}
+	return 0
+}
+
+// Float returns a `float` parameter.
+func (a Args) Float(name string) float64 {
+	if typed, isTyped := a[name].(float64); isTyped {
+		return typed
+	}
+	return 0
+}
+
+// Bool returns a `bool` parameter.
+func (a Args) Bool(name string) bool {
+	if typed, isTyped := a[name].(bool); isTyped {
+		return typed
+	}
+	return false
+}
+
+// Has returns if a parameter was matched.
+func (a Args) Has(name string) bool {
+	_, hasValue := a[name]
+	return hasValue
+}
+
+// Context is what a command handler receives.
+type Context struct {
+	// Client is the client the message was received on.
+	Client *slack.Client
+	// Router is the router that matched the command.
+	Router *Router
+	// Command is the matched command.
+	Command *Command
+	// Message is the original message.
+	Message *slack.Message
+	// Text is the message text with the mention or prefix removed.
+	Text string
+	// Args are the parsed command parameters.
+	Args Args
+}
+
+// Reply sends a message to the channel (and thread) the command was received on.
+func (ctx *Context) Reply(messageComponents ...interface{}) error {
+	return ctx.send(fmt.Sprint(messageComponents...))
+}
+
+// Replyf is an overload that uses Printf style replacements for Reply.
+func (ctx *Context) Replyf(format string, messageComponents ...interface{}) error {
+	return ctx.send(fmt.Sprintf(format, messageComponents...))
+}
+
+// Mention replies and mentions the user that sent the command, i.e. `@will: done`.
+func (ctx *Context) Mention(messageComponents ...interface{}) error {
+	return ctx.send(fmt.Sprintf("<@%s>: %s", ctx.Message.User, fmt.Sprint(messageComponents...)))
+}
+
+// ReplyEphemeral replies with a message only the user that sent the command can see.
+func (ctx *Context) ReplyEphemeral(messageComponents ...interface{}) error {
+	return ctx.Client.ReplyEphemeral(ctx.Message, messageComponents...)
+}
+
+// ReplyInThread replies in a thread on the original message, starting one if needed.
+func (ctx *Context) ReplyInThread(messageComponents ...interface{}) error {
+	threadTS := ctx.Message.ThreadTimestamp
+	if threadTS == nil {
+		threadTS = ctx.Message.Timestamp
+	}
+	return ctx.Client.SendMessage(&slack.Message{
+		Type:            slack.EventMessage,
+		Channel:         ctx.Message.Channel,
+		ThreadTimestamp: threadTS,
+		Text:            fmt.Sprint(messageComponents...),
+	})
+}
+
+func (ctx *Context) send(text string) error {
+	return ctx.Client.SendMessage(&slack.Message{
+		Type:            slack.EventMessage,
+		Channel:         ctx.Message.Channel,
+		ThreadTimestamp: ctx.Message.ThreadTimestamp,
+		Text:            text,
+	})
+}
+
+// Ask sends a question and blocks until the user that sent the command replies in the same channel (and thread).
+// It returns ErrConversationTimeout if the user doesn't reply in time and
+// ErrConversationCanceled if they reply with a cancel keyword.
+func (ctx *Context) Ask(messageComponents ...interface{}) (*slack.Message, error) {
+	return ctx.AskWithTimeout(0, messageComponents...)
+}
+
+// AskWithTimeout is an overload of Ask that waits a given time for the reply.
+func (ctx *Context) AskWithTimeout(timeout time.Duration, messageComponents ...interface{}) (*slack.Message, error) {
+	return ctx.Router.Conversations().Ask(ctx.SessionKey(), func() error {
+		return ctx.Reply(messageComponents...)
+	}, timeout)
+}
+
+// Confirm asks a yes or no question, repeating it until the reply is one or the other.
+func (ctx *Context) Confirm(messageComponents ...interface{}) (bool, error) {
+	for {
+		reply, err := ctx.Ask(messageComponents...)
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(strings.TrimSpace(reply.Text)) {
+		case "y", "yes", "ok", "sure", "confirm":
+			return true, nil
+		case "n", "no", "nope":
+			return false, nil
+		}
+		messageComponents = []interface{}{"please answer `yes` or `no`."}
+	}
+}
+
+// SessionKey returns the key for the conversation the command is part of.
+func (ctx *Context) SessionKey() SessionKey {
+	return KeyForMessage(ctx.Message)
+}
+
+// Session returns the conversation session for the user, creating it if it doesn't exist.
+func (ctx *Context) Session() (*Session, error) {
+	key := ctx.SessionKey()
+	session, err := ctx.Router.Conversations().Store().Load(key)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil {
+		session = NewSession(key)
+	}
+	return session, nil
+}
+
+// SaveSession saves the conversation session.
+func (ctx *Context) SaveSession(session *Session) error {
+	return ctx.Router.Conversations().Store().Save(session)
+}
+
+// EndSession removes the conversation session.
+func (ctx *Context) EndSession() error {
+	return ctx.Router.Conversations().Store().Delete(ctx.SessionKey())
+}
@@ -0,0 +1,190 @@
+package bot
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	exception "github.com/blendlabs/go-exception"
+	slack "github.com/wcharczuk/go-slack"
+)
+
+const (
+	// DefaultConversationTimeout is how long Ask waits for a reply by default.
+	DefaultConversationTimeout = 5 * time.Minute
+)
+
+var (
+	// ErrConversationTimeout is returned by Ask when the user doesn't reply in time.
+	ErrConversationTimeout = exception.New("conversation timed out waiting for a reply.")
+
+	// ErrConversationCanceled is returned by Ask when the user replies with a cancel keyword,
+	// or when a newer question to the same user replaces it.
+	ErrConversationCanceled = exception.New("conversation canceled.")
+
+	// DefaultCancelKeywords are the replies that cancel a conversation.
+	DefaultCancelKeywords = []string{"cancel", "stop", "nevermind", "never mind"}
+)
+
+// NewConversations returns a new conversation tracker with an in memory session store.
+func NewConversations() *Conversations {
+	return &Conversations{
+		store:          NewMemorySessionStore(),
+		timeout:        DefaultConversationTimeout,
+		cancelKeywords: DefaultCancelKeywords,
+		waiters:        map[SessionKey]chan *slack.Message{},
+	}
+}
+
+// Conversations tracks questions that are waiting on a reply from a user.
+// Replies are delivered to the waiting question instead of being handled as new messages.
+type Conversations struct {
+	store          SessionStore
+	timeout        time.Duration
+	cancelKeywords []string
+
+	waitersLock sync.Mutex
+	waiters     map[SessionKey]chan *slack.Message
+}
+
+// SetStore sets the session store.
+func (c *Conversations) SetStore(store SessionStore) {
+	c.store = store
+}
+
+// Store returns the session store.
+func (c *Conversations) Store() SessionStore {
+	return c.store
+}
+
+// SetTimeout sets the default time to wait for a reply.
+func (c *Conversations) SetTimeout(timeout time.Duration) {
+	c.timeout = timeout
+}
+
+// SetCancelKeywords sets the replies (case insensitive) that cancel a conversation.
+func (c *Conversations) SetCancelKeywords(keywords ...string) {
+	c.cancelKeywords = keywords
+}
+
+// Listen delivers a client's `message` events to waiting questions.
+// It is only needed when the conversations aren't used through a Router.
+func (c *Conversations) Listen(client *slack.Client) {
+	client.AddEventListener(slack.EventMessage, func(client *slack.Client, m *slack.Message) {
+		c.Deliver(m)
+	})
+}
+
+// Deliver hands a message to the question waiting on it, if any.
+// It returns true if the message was consumed as a reply; a question that has timed out doesn't consume it.
+func (c *Conversations) Deliver(m *slack.Message) bool {
+	if m == nil || len(m.SubType) > 0 || len(m.User) == 0 {
+		return false
+	}
+
+	key := KeyForMessage(m)
+	c.waitersLock.Lock()
+	defer c.waitersLock.Unlock()
+
+	// waiters are buffered, and removed under the same lock, so the send never blocks and is never lost.
+	waiter, hasWaiter := c.waiters[key]
+	if !hasWaiter {
+		return false
+	}
+	delete(c.waiters, key)
+	waiter <- m
+	return true
+}
+
+// Ask sends a question with a given send function and blocks until the user replies.
+// A timeout of zero uses the default timeout.
+func (c *Conversations) Ask(key SessionKey, send func() error, timeout time.Duration) (*slack.Message, error) {
+	if timeout <= 0 {
+		timeout = c.timeout
+	}
+
+	// register before sending so a quick reply isn't missed.
+	waiter := c.expect(key)
+	if err := send(); err != nil {
+		c.forget(key, waiter)
+		return nil, err
+	}
+
+	select {
+	case reply := <-waiter:
+		return c.answer(key, reply)
+	case <-time.After(timeout):
+		if c.forget(key, waiter) {
+			return nil, ErrConversationTimeout
+		}
+		// a reply (or a newer question) was delivered as the question timed out.
+		return c.answer(key, <-waiter)
+	}
+}
+
+// Waiting returns if there is a question waiting on a reply for a key.
+func (c *Conversations) Waiting(key SessionKey) bool {
+	c.waitersLock.Lock()
+	defer c.waitersLock.Unlock()
+	_, hasWaiter := c.waiters[key]
+	return hasWaiter
+}
+
+// KeyForMessage returns the session key for a message.
+func KeyForMessage(m *slack.Message) SessionKey {
+	key := SessionKey{Channel: m.Channel, User: m.User}
+	if m.ThreadTimestamp != nil {
+		key.Thread = m.ThreadTimestamp.String()
+	}
+	return key
+}
+
+// --------------------------------------------------------------------------------
+// INTERNAL METHODS
+// --------------------------------------------------------------------------------
+
+func (c *Conversations) expect(key SessionKey) chan *slack.Message {
+	c.waitersLock.Lock()
+	defer c.waitersLock.Unlock()
+
+	// a new question replaces one that is already waiting.
+	if existing, hasExisting := c.waiters[key]; hasExisting {
+		existing <- nil
+	}
+	waiter := make(chan *slack.Message, 1)
+	c.waiters[key] = waiter
+	return waiter
+}
+
+// forget removes a waiter, and returns false if it was already removed by a delivery.
+func (c *Conversations) forget(key SessionKey, waiter chan *slack.Message) bool {
+	c.waitersLock.Lock()
+	defer c.waitersLock.Unlock()
+	if current, hasCurrent := c.waiters[key]; hasCurrent && current == waiter {
+		delete(c.waiters, key)
+		return true
+	}
+	return false
+}
+
+// answer returns the result of a question for what was delivered to its waiter.
+func (c *Conversations) answer(key SessionKey, reply *slack.Message) (*slack.Message, error) {
+	if reply == nil {
+		return nil, ErrConversationCanceled
+	}
+	if c.isCancel(reply.Text) {
+		c.store.Delete(key)
+		return reply, ErrConversationCanceled
+	}
+	return reply, nil
+}
+
+func (c *Conversations) isCancel(text string) bool {
+	text = strings.TrimSpace(text)
+	for _, keyword := range c.cancelKeywords {
+		if strings.EqualFold(text, keyword) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package bot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blendlabs/go-assert"
+	slack "github.com/wcharczuk/go-slack"
+)
+
+func TestConversationsAsk(t *testing.T) {
+	a := assert.New(t)
+	c := NewConversations()
+	key := SessionKey{Channel: "C1", User: "U1"}
+
+	sent := false
+	go func() {
+		for !c.Waiting(key) {
+			time.Sleep(time.Millisecond)
+		}
+		a.False(c.Deliver(&slack.Message{Channel: "C1", User: "U2", Text: "prod"}))
+		a.True(c.Deliver(&slack.Message{Channel: "C1", User: "U1", Text: "prod"}))
+	}()
+
+	reply, err := c.Ask(key, func() error { sent = true; return nil }, time.Second)
+	a.Nil(err)
+	a.True(sent)
+	a.Equal("prod", reply.Text)
+	a.False(c.Waiting(key))
+}
+
+func TestConversationsAskTimeout(t *testing.T) {
+	a := assert.New(t)
+	c := NewConversations()
+	key := SessionKey{Channel: "C1", User: "U1"}
+
+	_, err := c.Ask(key, func() error { return nil }, 10*time.Millisecond)
+	a.Equal(ErrConversationTimeout, err)
+	a.False(c.Waiting(key))
+}
+
+func TestConversationsDeliverAtTimeout(t *testing.T) {
+	a := assert.New(t)
+	c := NewConversations()
+	key := SessionKey{Channel: "C1", User: "U1"}
+
+	// a reply racing the timeout is either the answer, or isn't consumed and is routed as a new message.
+	for x := 0; x < 100; x++ {
+		delivered := make(chan bool, 1)
+		go func() {
+			time.Sleep(time.Millisecond)
+			delivered <- c.Deliver(&slack.Message{Channel: "C1", User: "U1", Text: "prod"})
+		}()
+
+		reply, err := c.Ask(key, func() error { return nil }, time.Millisecond)
+		if <-delivered {
+			a.Nil(err)
+			a.Equal("prod", reply.Text)
+		} else {
+			a.Equal(ErrConversationTimeout, err)
+		}
+		a.False(c.Waiting(key))
+	}
+}
+
+func TestConversationsAskCancel(t *testing.T) {
+	a := assert.New(t)
+	c := NewConversations()
+	key := SessionKey{Channel: "C1", User: "U1"}
+	a.Nil(c.Store().Save(NewSession(key)))
+
+	go func() {
+		for !c.Waiting(key) {
+			time.Sleep(time.Millisecond)
+		}
+		c.Deliver(&slack.Message{Channel: "C1", User: "U1", Text: " Never Mind "})
+	}()
+
+	_, err := c.Ask(key, func() error { return nil }, time.Second)
+	a.Equal(ErrConversationCanceled, err)
+
+	session, err := c.Store().Load(key)
+	a.Nil(err)
+	a.Nil(session)
+}
+
+func TestKeyForMessage(t *testing.T) {
+	a := assert.New(t)
+	var ts slack.Timestamp
+	a.Nil(ts.UnmarshalJSON([]byte(`"1456540738.000017"`)))
+
+	key := KeyForMessage(&slack.Message{Channel: "C1", User: "U1", ThreadTimestamp: &ts})
+	a.Equal(SessionKey{Channel: "C1", User: "U1", Thread: "1456540738.000017"}, key)
+}
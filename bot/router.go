@@ -0,0 +1,278 @@
+// Package bot is a command router for bots built on go-slack.
+//
+// A trivial example is:
+//
+//	client := slack.NewClient(os.Getenv("SLACK_TOKEN"))
+//	router := bot.NewRouter(client)
+//	router.SetPrefix("!")
+//	router.Add("deploy <service> to <env>", "deploys a service", func(ctx *bot.Context) error {
+//	    return ctx.Replyf("deploying %s to %s", ctx.Args.String("service"), ctx.Args.String("env"))
+//	})
+//	router.Listen()
+//	client.Connect()
+//
+// Commands match when the bot is mentioned (`@bot deploy api to prod`), in direct messages
+// (`deploy api to prod`) or when the message starts with the router prefix (`!deploy api to prod`).
+package bot
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"sync"
+
+	exception "github.com/blendlabs/go-exception"
+	slack "github.com/wcharczuk/go-slack"
+)
+
+// ErrorHandler is called when a command handler returns an error.
+type ErrorHandler func(ctx *Context, err error)
+
+// NewRouter returns a new router for a client.
+func NewRouter(client *slack.Client) *Router {
+	r := &Router{
+		client:        client,
+		helpPrefix:    "commands:",
+		conversations: NewConversations(),
+	}
+	r.errorHandler = defaultErrorHandler
+	r.notFoundHandler = defaultNotFoundHandler
+	r.Add("help", "shows this message", r.handleHelp)
+	return r
+}
+
+// Router dispatches messages addressed to a bot to registered commands.
+type Router struct {
+	client *slack.Client
+
+	botIDLock sync.Mutex
+	botID     string
+
+	prefix     string
+	helpPrefix string
+
+	commandsLock sync.RWMutex
+	commands     []*Command
+
+	conversations *Conversations
+
+	errorHandler    ErrorHandler
+	notFoundHandler Handler
+}
+
+// SetPrefix sets a prefix (i.e. `!`) that addresses messages to the bot without a mention.
+func (r *Router) SetPrefix(prefix string) {
+	r.prefix = prefix
+}
+
+// SetBotID sets the bot's user id, used to detect mentions.
+// If it isn't set it is fetched with `AuthTest` on the first message.
+func (r *Router) SetBotID(botID string) {
+	r.botIDLock.Lock()
+	defer r.botIDLock.Unlock()
+	r.botID = botID
+}
+
+// SetHelpPrefix sets the line printed before the command list in help output.
+func (r *Router) SetHelpPrefix(helpPrefix string) {
+	r.helpPrefix = helpPrefix
+}
+
+// SetErrorHandler sets the function called when a command handler returns an error.
+// The default replies with the error.
+func (r *Router) SetErrorHandler(handler ErrorHandler) {
+	r.errorHandler = handler
+}
+
+// SetNotFoundHandler sets the handler called when a message is addressed to the bot but matches no command.
+// The default suggests `help`; set it to nil to ignore these messages.
+func (r *Router) SetNotFoundHandler(handler Handler) {
+	r.notFoundHandler = handler
+}
+
+// Conversations returns the conversations tracker used by `Context.Ask`.
+func (r *Router) Conversations() *Conversations {
+	return r.conversations
+}
+
+// Add registers a command from a template.
+// Templates are words and parameters, i.e. `deploy <service> to <env>` or `scale <service> <count:int>`.
+// Parameter types are `string` (the default), `int`, `float`, `bool`, `user`, `channel` and `rest`.
+func (r *Router) Add(template, description string, handler Handler) error {
+	tokens, err := parseTemplate(template)
+	if err != nil {
+		return err
+	}
+	r.addCommand(&Command{
+		Usage:       strings.Join(strings.Fields(template), " "),
+		Description: description,
+		Handler:     handler,
+		tokens:      tokens,
+	})
+	return nil
+}
+
+// AddRegex registers a command matched by a regular expression.
+// Named groups become args; unnamed groups are keyed by their index.
+func (r *Router) AddRegex(expr, description string, handler Handler) error {
+	compiled, err := regexp.Compile(expr)
+	if err != nil {
+		return exception.Wrap(err)
+	}
+	r.addCommand(&Command{
+		Usage:       expr,
+		Description: description,
+		Handler:     handler,
+		expr:        compiled,
+	})
+	return nil
+}
+
+// Commands returns the registered commands.
+func (r *Router) Commands() []*Command {
+	r.commandsLock.RLock()
+	defer r.commandsLock.RUnlock()
+	commands := make([]*Command, len(r.commands))
+	copy(commands, r.commands)
+	return commands
+}
+
+// Help returns the help text listing the registered commands.
+func (r *Router) Help() string {
+	buffer := bytes.NewBuffer(nil)
+	buffer.WriteString(r.helpPrefix)
+	for _, command := range r.Commands() {
+		buffer.WriteString("\n")
+		buffer.WriteString(command.String())
+	}
+	return buffer.String()
+}
+
+// Listen attaches the router to the client's `message` events.
+func (r *Router) Listen() {
+	r.client.AddEventListener(slack.EventMessage, r.handleMessage)
+}
+
+// Route matches a message against the registered commands and calls the handler.
+// Replies to a pending `Context.Ask` are delivered to the question instead.
+// It returns true if the message was addressed to the bot.
+func (r *Router) Route(client *slack.Client, m *slack.Message) bool {
+	if r.conversations.Deliver(m) {
+		return true
+	}
+
+	text, addressed := r.address(m)
+	if !addressed {
+		return false
+	}
+
+	command, args := r.match(text)
+	ctx := &Context{
+		Client:  client,
+		Router:  r,
+		Command: command,
+		Message: m,
+		Text:    text,
+		Args:    args,
+	}
+
+	handler := r.notFoundHandler
+	if command != nil {
+		handler = command.Handler
+	}
+	if handler == nil {
+		return true
+	}
+
+	if err := handler(ctx); err != nil && r.errorHandler != nil {
+		r.errorHandler(ctx, err)
+	}
+	return true
+}
+
+// --------------------------------------------------------------------------------
+// INTERNAL METHODS
+// --------------------------------------------------------------------------------
+
+func (r *Router) addCommand(command *Command) {
+	r.commandsLock.Lock()
+	defer r.commandsLock.Unlock()
+	r.commands = append(r.commands, command)
+}
+
+func (r *Router) handleMessage(client *slack.Client, m *slack.Message) {
+	r.Route(client, m)
+}
+
+func (r *Router) handleHelp(ctx *Context) error {
+	return ctx.Reply(r.Help())
+}
+
+// match returns the first command that matches the text.
+func (r *Router) match(text string) (*Command, Args) {
+	for _, command := range r.Commands() {
+		if args, matched := command.match(text); matched {
+			return command, args
+		}
+	}
+	return nil, nil
+}
+
+// address determines if a message is addressed to the bot, and if so returns the text without the address.
+func (r *Router) address(m *slack.Message) (string, bool) {
+	if m == nil || len(m.SubType) > 0 || m.Hidden || len(m.User) == 0 {
+		return "", false
+	}
+
+	botID := r.getBotID()
+	if len(botID) > 0 && m.User == botID {
+		return "", false
+	}
+
+	text := strings.TrimSpace(m.Text)
+	if len(botID) > 0 {
+		for _, mention := range []string{"<@" + botID + ">", "<@" + botID + "|"} {
+			if strings.HasPrefix(text, mention) {
+				text = text[len(mention):]
+				if closeIndex := strings.Index(text, ">"); strings.HasSuffix(mention, "|") && closeIndex >= 0 {
+					text = text[closeIndex+1:]
+				}
+				return strings.TrimSpace(strings.TrimLeft(text, ":, ")), true
+			}
+		}
+	}
+
+	if len(r.prefix) > 0 && strings.HasPrefix(text, r.prefix) {
+		return strings.TrimSpace(text[len(r.prefix):]), true
+	}
+
+	if IsDirectMessage(m.Channel) {
+		return text, true
+	}
+	return "", false
+}
+
+func (r *Router) getBotID() string {
+	r.botIDLock.Lock()
+	defer r.botIDLock.Unlock()
+
+	if len(r.botID) == 0 && r.client != nil {
+		if res, err := r.client.AuthTest(); err == nil {
+			r.botID = res.UserID
+		}
+	}
+	return r.botID
+}
+
+// IsDirectMessage returns if a channel id is a direct message (im) channel.
+func IsDirectMessage(channelID string) bool {
+	return strings.HasPrefix(channelID, "D")
+}
+
+func defaultErrorHandler(ctx *Context, err error) {
+	ctx.Replyf("error: %v", err)
+}
+
+func defaultNotFoundHandler(ctx *Context) error {
+	return ctx.Replyf("I don't know how to `%s`, try `help`.", ctx.Text)
+}
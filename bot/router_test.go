@@ -0,0 +1,90 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/blendlabs/go-assert"
+	slack "github.com/wcharczuk/go-slack"
+)
+
+func newTestRouter() *Router {
+	r := NewRouter(slack.NewClient("test-token"))
+	r.SetBotID("UBOT")
+	r.SetPrefix("!")
+	return r
+}
+
+func TestRouterAddress(t *testing.T) {
+	a := assert.New(t)
+	r := newTestRouter()
+
+	text, addressed := r.address(&slack.Message{User: "U1", Channel: "C1", Text: "<@UBOT>: deploy api"})
+	a.True(addressed)
+	a.Equal("deploy api", text)
+
+	text, addressed = r.address(&slack.Message{User: "U1", Channel: "C1", Text: "<@UBOT|bot> deploy api"})
+	a.True(addressed)
+	a.Equal("deploy api", text)
+
+	text, addressed = r.address(&slack.Message{User: "U1", Channel: "C1", Text: "!deploy api"})
+	a.True(addressed)
+	a.Equal("deploy api", text)
+
+	text, addressed = r.address(&slack.Message{User: "U1", Channel: "D1", Text: "deploy api"})
+	a.True(addressed)
+	a.Equal("deploy api", text)
+
+	_, addressed = r.address(&slack.Message{User: "U1", Channel: "C1", Text: "deploy api"})
+	a.False(addressed)
+	_, addressed = r.address(&slack.Message{User: "UBOT", Channel: "D1", Text: "deploy api"})
+	a.False(addressed)
+	_, addressed = r.address(&slack.Message{User: "U1", Channel: "D1", SubType: "bot_message", Text: "deploy api"})
+	a.False(addressed)
+}
+
+func TestRouterRoute(t *testing.T) {
+	a := assert.New(t)
+	r := newTestRouter()
+
+	var service, env string
+	a.Nil(r.Add("deploy <service> to <env>", "deploys a service", func(ctx *Context) error {
+		service = ctx.Args.String("service")
+		env = ctx.Args.String("env")
+		return nil
+	}))
+	var matchedID string
+	a.Nil(r.AddRegex(`^ticket (?P<id>[A-Z]+-\d+)$`, "looks up a ticket", func(ctx *Context) error {
+		matchedID = ctx.Args.String("id")
+		return nil
+	}))
+	notFound := false
+	r.SetNotFoundHandler(func(ctx *Context) error {
+		notFound = true
+		return nil
+	})
+
+	a.True(r.Route(r.client, &slack.Message{User: "U1", Channel: "C1", Text: "<@UBOT> deploy api to prod"}))
+	a.Equal("api", service)
+	a.Equal("prod", env)
+
+	a.True(r.Route(r.client, &slack.Message{User: "U1", Channel: "D1", Text: "ticket OPS-123"}))
+	a.Equal("OPS-123", matchedID)
+
+	a.False(notFound)
+	a.True(r.Route(r.client, &slack.Message{User: "U1", Channel: "D1", Text: "launch the rockets"}))
+	a.True(notFound)
+
+	a.False(r.Route(r.client, &slack.Message{User: "U1", Channel: "C1", Text: "deploy api to prod"}))
+}
+
+func TestRouterHelp(t *testing.T) {
+	a := assert.New(t)
+	r := newTestRouter()
+	a.Nil(r.Add("deploy  <service>   to <env>", "deploys a service", func(ctx *Context) error { return nil }))
+
+	help := r.Help()
+	a.True(strings.HasPrefix(help, "commands:"))
+	a.Contains("`help` - shows this message", help)
+	a.Contains("`deploy <service> to <env>` - deploys a service", help)
+}
@@ -0,0 +1,99 @@
+package bot
+
+import (
+	"sync"
+	"time"
+)
+
+// SessionKey identifies a conversation with a user in a channel (and optionally a thread).
+type SessionKey struct {
+	Channel string
+	Thread  string
+	User    string
+}
+
+// Session is the state kept across the messages of a conversation.
+type Session struct {
+	Key     SessionKey             `json:"key"`
+	Values  map[string]interface{} `json:"values"`
+	Created time.Time              `json:"created"`
+	Updated time.Time              `json:"updated"`
+}
+
+// NewSession returns a new session for a key.
+func NewSession(key SessionKey) *Session {
+	now := time.Now().UTC()
+	return &Session{
+		Key:     key,
+		Values:  map[string]interface{}{},
+		Created: now,
+		Updated: now,
+	}
+}
+
+// Get returns a session value.
+func (s *Session) Get(name string) interface{} {
+	return s.Values[name]
+}
+
+// GetString returns a session value as a string.
+func (s *Session) GetString(name string) string {
+	if typed, isTyped := s.Values[name].(string); isTyped {
+		return typed
+	}
+	return ""
+}
+
+// Set sets a session value.
+func (s *Session) Set(name string, value interface{}) {
+	if s.Values == nil {
+		s.Values = map[string]interface{}{}
+	}
+	s.Values[name] = value
+	s.Updated = time.Now().UTC()
+}
+
+// SessionStore persists conversation sessions.
+// Implement it to keep sessions somewhere that survives restarts (redis, a database etc.).
+type SessionStore interface {
+	// Load returns the session for a key, or nil if there isn't one.
+	Load(key SessionKey) (*Session, error)
+	// Save stores a session.
+	Save(session *Session) error
+	// Delete removes the session for a key.
+	Delete(key SessionKey) error
+}
+
+// NewMemorySessionStore returns a new in memory session store.
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{sessions: map[SessionKey]*Session{}}
+}
+
+// MemorySessionStore is the default, in memory SessionStore.
+type MemorySessionStore struct {
+	lock     sync.Mutex
+	sessions map[SessionKey]*Session
+}
+
+// Load implements SessionStore.
+func (mss *MemorySessionStore) Load(key SessionKey) (*Session, error) {
+	mss.lock.Lock()
+	defer mss.lock.Unlock()
+	return mss.sessions[key], nil
+}
+
+// Save implements SessionStore.
+func (mss *MemorySessionStore) Save(session *Session) error {
+	mss.lock.Lock()
+	defer mss.lock.Unlock()
+	mss.sessions[session.Key] = session
+	return nil
+}
+
+// Delete implements SessionStore.
+func (mss *MemorySessionStore) Delete(key SessionKey) error {
+	mss.lock.Lock()
+	defer mss.lock.Unlock()
+	delete(mss.sessions, key)
+	return nil
+}
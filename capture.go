@@ -0,0 +1,197 @@
+package slack
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"time"
+
+	exception "github.com/blendlabs/go-exception"
+)
+
+// CapturedFrame is a raw rtm frame and the time it was read, one per line in a capture file.
+type CapturedFrame struct {
+	Time  time.Time       `json:"time"`
+	Frame json.RawMessage `json:"frame"`
+}
+
+// SetCapture writes every raw rtm frame the client reads to a writer as JSONL (one CapturedFrame per line).
+// Pass nil to stop capturing. Captures can be fed back through the client's listeners with a Replayer.
+func (rtm *Client) SetCapture(w io.Writer) {
+	rtm.captureLock.Lock()
+	defer rtm.captureLock.Unlock()
+	rtm.captureWriter = w
+}
+
+func (rtm *Client) setReplaying(replaying bool) {
+	rtm.captureLock.Lock()
+	defer rtm.captureLock.Unlock()
+	rtm.replaying = replaying
+}
+
+// isReplaying returns if a Replayer is feeding frames through the client's listeners;
+// the built-in listeners that call the web api skip replayed frames.
+func (rtm *Client) isReplaying() bool {
+	rtm.captureLock.Lock()
+	defer rtm.captureLock.Unlock()
+	return rtm.replaying
+}
+
+// WaitForListeners blocks until every listener dispatched so far has returned.
+func (rtm *Client) WaitForListeners() {
+	rtm.waitForListeners(0)
+}
+
+// waitForListeners blocks until every listener in flight has returned, or for at most timeout if it's positive,
+// and returns if they did.
+func (rtm *Client) waitForListeners(timeout time.Duration) bool {
+	rtm.listenersLock.Lock()
+	defer rtm.listenersLock.Unlock()
+
+	var expired bool
+	if timeout > 0 {
+		timer := time.AfterFunc(timeout, func() {
+			rtm.listenersLock.Lock()
+			expired = true
+			rtm.listenersLock.Unlock()
+			rtm.listenersDone.Broadcast()
+		})
+		defer timer.Stop()
+	}
+	for rtm.listenersInFlight > 0 && !expired {
+		rtm.listenersDone.Wait()
+	}
+	return rtm.listenersInFlight == 0
+}
+
+func (rtm *Client) listenerStarted() {
+	rtm.listenersLock.Lock()
+	defer rtm.listenersLock.Unlock()
+	rtm.listenersInFlight++
+}
+
+func (rtm *Client) listenerFinished() {
+	rtm.listenersLock.Lock()
+	defer rtm.listenersLock.Unlock()
+	rtm.listenersInFlight--
+	if rtm.listenersInFlight == 0 {
+		rtm.listenersDone.Broadcast()
+	}
+}
+
+func (rtm *Client) capture(frame []byte) {
+	rtm.captureLock.Lock()
+	defer rtm.captureLock.Unlock()
+
+	if rtm.captureWriter == nil || !json.Valid(frame) {
+		return
+	}
+
+	line, err := json.Marshal(CapturedFrame{Time: time.Now().UTC(), Frame: json.RawMessage(frame)})
+	if err != nil {
+		rtm.logf("capture() :: error => %v", err)
+		return
+	}
+	if _, err = rtm.captureWriter.Write(append(line, '\n')); err != nil {
+		rtm.logf("capture() :: error => %v", err)
+	}
+}
+
+// DefaultReplayListenerTimeout is how long a Replayer waits for the listeners of a frame by default.
+const DefaultReplayListenerTimeout = time.Second
+
+// NewReplayer returns a Replayer that feeds a capture through a client's listeners.
+func NewReplayer(client *Client, r io.Reader) *Replayer {
+	return &Replayer{
+		client:           client,
+		reader:           r,
+		speed:            1,
+		waitForListeners: true,
+		listenerTimeout:  DefaultReplayListenerTimeout,
+		sleep:            time.Sleep,
+	}
+}
+
+// ReplayFile replays a capture file through a client's listeners at a given speed (see `Replayer.SetSpeed`).
+func ReplayFile(client *Client, path string, speed float64) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, exception.Wrap(err)
+	}
+	defer f.Close()
+
+	replayer := NewReplayer(client, f)
+	replayer.SetSpeed(speed)
+	return replayer.Replay()
+}
+
+// Replayer feeds captured rtm frames back through a client's listeners without connecting to slack.
+// It is useful for reproducing bugs in handlers from a production capture.
+type Replayer struct {
+	client           *Client
+	reader           io.Reader
+	speed            float64
+	waitForListeners bool
+	listenerTimeout  time.Duration
+	sleep            func(time.Duration)
+}
+
+// SetSpeed sets the replay speed relative to the original timing;
+// 1 replays in real time, 10 replays ten times faster and 0 replays as fast as possible.
+func (r *Replayer) SetSpeed(speed float64) {
+	r.speed = speed
+}
+
+// SetWaitForListeners sets if the replayer waits for the listeners of each frame to return
+// before dispatching the next one (the default), which keeps replays deterministic.
+func (r *Replayer) SetWaitForListeners(wait bool) {
+	r.waitForListeners = wait
+}
+
+// SetListenerTimeout sets how long the replayer waits for the listeners of a frame before dispatching
+// the next one anyway; it bounds the wait for listeners that wait on a later frame, i.e. a bot's `Ask`.
+func (r *Replayer) SetListenerTimeout(timeout time.Duration) {
+	r.listenerTimeout = timeout
+}
+
+// Replay dispatches every frame in the capture and returns how many were replayed.
+// It waits up to the listener timeout for the listeners of the last frame to return.
+func (r *Replayer) Replay() (int, error) {
+	scanner := bufio.NewScanner(r.reader)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	r.client.setReplaying(true)
+	defer r.client.setReplaying(false)
+
+	var previous time.Time
+	count := 0
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var frame CapturedFrame
+		if err := json.Unmarshal(line, &frame); err != nil {
+			return count, exception.Newf("line %d: %v", count+1, err)
+		}
+
+		if r.speed > 0 && !previous.IsZero() && frame.Time.After(previous) {
+			r.sleep(time.Duration(float64(frame.Time.Sub(previous)) / r.speed))
+		}
+		previous = frame.Time
+
+		r.client.handleFrame(frame.Frame)
+		if r.waitForListeners {
+			r.client.waitForListeners(r.listenerTimeout)
+		}
+		count++
+	}
+
+	if err := scanner.Err(); err != nil {
+		return count, exception.Wrap(err)
+	}
+	r.client.waitForListeners(r.listenerTimeout)
+	return count, nil
+}
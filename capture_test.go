@@ -0,0 +1,191 @@
+package slack
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/blendlabs/go-assert"
+)
+
+func TestClientCapture(t *testing.T) {
+	a := assert.New(t)
+
+	c := NewClient("test_token")
+	c.SetDebug(false)
+
+	buffer := bytes.NewBuffer(nil)
+	c.SetCapture(buffer)
+	c.capture([]byte(`{"type":"hello"}`))
+	c.capture([]byte(`not json`))
+	c.capture([]byte(`{"type":"message","text":"hello"}`))
+	c.SetCapture(nil)
+	c.capture([]byte(`{"type":"message","text":"not captured"}`))
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	a.Len(lines, 2)
+
+	var frame CapturedFrame
+	a.Nil(json.Unmarshal([]byte(lines[1]), &frame))
+	a.False(frame.Time.IsZero())
+	a.Equal(`{"type":"message","text":"hello"}`, string(frame.Frame))
+}
+
+func TestReplayer(t *testing.T) {
+	a := assert.New(t)
+
+	c := NewClient("test_token")
+	c.SetDebug(false)
+
+	var lock sync.Mutex
+	var texts []string
+	var acks int
+	c.AddEventListener(EventMessage, func(client *Client, m *Message) {
+		lock.Lock()
+		defer lock.Unlock()
+		texts = append(texts, m.Text)
+	})
+	c.AddEventListener(EventMessageACK, func(client *Client, m *Message) {
+		lock.Lock()
+		defer lock.Unlock()
+		acks++
+	})
+
+	buffer := bytes.NewBuffer(nil)
+	c.SetCapture(buffer)
+	c.capture([]byte(`{"type":"message","text":"first"}`))
+	c.capture([]byte(`{"type":"message","text":"second"}`))
+	c.SetCapture(nil)
+
+	replayer := NewReplayer(c, buffer)
+	replayer.SetSpeed(0)
+	count, err := replayer.Replay()
+	a.Nil(err)
+	a.Equal(2, count)
+	a.Equal([]string{"first", "second"}, texts)
+	a.Equal(0, acks)
+}
+
+func TestReplayerSkipsNetworkListeners(t *testing.T) {
+	a := assert.New(t)
+
+	var lock sync.Mutex
+	var calls []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		calls = append(calls, r.URL.Path)
+		lock.Unlock()
+		w.Write([]byte(`{"ok":true,"channel":{"id":"C0ARCHIVE","is_member":true}}`))
+	}))
+	defer server.Close()
+
+	c := NewClient("test_token")
+	c.SetDebug(false)
+	c.SetAPIEndpoint("http", strings.TrimPrefix(server.URL, "http://"))
+
+	replayer := NewReplayer(c, strings.NewReader(`{"time":"2017-03-01T12:00:00Z","frame":{"type":"channel_unarchive","channel":"C0ARCHIVE"}}`))
+	replayer.SetSpeed(0)
+	count, err := replayer.Replay()
+	a.Nil(err)
+	a.Equal(1, count)
+	lock.Lock()
+	defer lock.Unlock()
+	a.Empty(calls)
+	a.False(c.isReplaying())
+}
+
+func TestReplayerTiming(t *testing.T) {
+	a := assert.New(t)
+
+	c := NewClient("test_token")
+	c.SetDebug(false)
+
+	var texts []string
+	c.AddEventListener(EventMessage, func(client *Client, m *Message) {
+		texts = append(texts, m.Text)
+	})
+
+	var sleeps []time.Duration
+	f, err := os.Open("testdata/rtm.capture.jsonl")
+	a.Nil(err)
+	defer f.Close()
+
+	replayer := NewReplayer(c, f)
+	replayer.SetSpeed(2)
+	replayer.sleep = func(d time.Duration) { sleeps = append(sleeps, d) }
+
+	count, err := replayer.Replay()
+	a.Nil(err)
+	a.Equal(4, count)
+	a.Equal([]string{"hello", "are you there?"}, texts)
+	a.Equal([]time.Duration{500 * time.Millisecond, time.Second, 500 * time.Millisecond}, sleeps)
+}
+
+func TestReplayerListenerWaitsForLaterFrame(t *testing.T) {
+	a := assert.New(t)
+
+	c := NewClient("test_token")
+	c.SetDebug(false)
+
+	// like a bot's `Ask`, the question's listener waits for the answer, which is the next frame.
+	answers := make(chan string, 1)
+	var lock sync.Mutex
+	var answer string
+	c.AddEventListener(EventMessage, func(client *Client, m *Message) {
+		if m.Text != "question" {
+			answers <- m.Text
+			return
+		}
+		select {
+		case text := <-answers:
+			lock.Lock()
+			answer = text
+			lock.Unlock()
+		case <-time.After(time.Second):
+		}
+	})
+
+	replayer := NewReplayer(c, strings.NewReader(strings.Join([]string{
+		`{"time":"2017-03-01T12:00:00Z","frame":{"type":"message","text":"question"}}`,
+		`{"time":"2017-03-01T12:00:01Z","frame":{"type":"message","text":"answer"}}`,
+	}, "\n")))
+	replayer.SetSpeed(0)
+	replayer.SetListenerTimeout(10 * time.Millisecond)
+	count, err := replayer.Replay()
+	a.Nil(err)
+	a.Equal(2, count)
+	lock.Lock()
+	defer lock.Unlock()
+	a.Equal("answer", answer)
+}
+
+func TestReplayerInvalidLine(t *testing.T) {
+	a := assert.New(t)
+
+	c := NewClient("test_token")
+	c.SetDebug(false)
+
+	count, err := NewReplayer(c, strings.NewReader("{\"time\":\"2017-03-01T12:00:00Z\",\"frame\":{\"type\":\"hello\"}}\nnope\n")).Replay()
+	a.NotNil(err)
+	a.Equal(1, count)
+}
+
+func TestReplayFile(t *testing.T) {
+	a := assert.New(t)
+
+	c := NewClient("test_token")
+	c.SetDebug(false)
+
+	count, err := ReplayFile(c, "testdata/rtm.capture.jsonl", 0)
+	a.Nil(err)
+	a.Equal(4, count)
+
+	_, err = ReplayFile(c, "testdata/does_not_exist.jsonl", 0)
+	a.NotNil(err)
+}
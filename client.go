@@ -32,6 +32,7 @@ package slack
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"sync"
@@ -39,6 +40,7 @@ import (
 
 	"github.com/blendlabs/go-exception"
 	"github.com/gorilla/websocket"
+	"github.com/wcharczuk/go-slack/format"
 )
 
 // Client constants
@@ -54,6 +56,9 @@ const (
 
 	// DefaultPingMaxInFlight is the maximum number of pings in flight.
 	DefaultPingMaxInFlight = 5
+
+	// DefaultReconnectDelay is the wait between failed attempts to reconnect a dropped connection.
+	DefaultReconnectDelay = 5 * time.Second
 )
 
 // EventListener is a function that recieves messages from a client.
@@ -70,19 +75,28 @@ type EventListener func(client *Client, message *Message)
 func NewClient(token string) *Client {
 	c := &Client{
 		Token:           token,
+		apiScheme:       APIScheme,
+		apiHost:         APIEndpoint,
 		EventListeners:  map[Event][]EventListener{},
 		ActiveChannels:  []string{},
+		state:           NewState(),
 		isDebug:         true,
 		pingTimeout:     DefaultPingTimeout,
 		pingMaxInFlight: DefaultPingMaxInFlight,
 		pingMaxFails:    DefaultPingMaxFails,
 		pingInFlight:    map[int64]time.Time{},
 		pingInterval:    DefaultPingInterval,
+		reconnectDelay:  DefaultReconnectDelay,
 		connected:       false,
+
+		scheduledPosts:    map[string]*ScheduledPost{},
+		schedulerInterval: DefaultSchedulerInterval,
 	}
+	c.listenersDone = sync.NewCond(&c.listenersLock)
 	c.AddEventListener(EventChannelJoined, c.handleChannelJoined)
 	c.AddEventListener(EventChannelDeleted, c.handleChannelDeleted)
 	c.AddEventListener(EventChannelUnArchive, c.handleChannelUnarchive)
+	c.AddEventListener(EventChannelArchive, c.handleChannelArchive)
 	c.AddEventListener(EventChannelLeft, c.handleChannelLeft)
 	c.AddEventListener(EventPong, c.handlePong)
 	return c
@@ -93,13 +107,21 @@ type Client struct {
 	Token          string
 	EventListeners map[Event][]EventListener
 
+	apiScheme string
+	apiHost   string
+
 	activeLock     sync.Mutex
 	ActiveChannels []string
 
+	state *State
+
 	socketLock       sync.RWMutex
 	socketConnection *websocket.Conn
+	socketWriteLock  sync.Mutex
 
 	connected bool
+	// sessionDone is closed by Stop to end the ping and listen loops of the current session.
+	sessionDone chan struct{}
 
 	pingTimeout      time.Duration
 	pingMaxInFlight  int
@@ -109,6 +131,23 @@ type Client struct {
 	pingInFlightLock sync.Mutex
 	pingInterval     time.Duration
 
+	reconnectDelay time.Duration
+
+	listenersLock     sync.Mutex
+	listenersDone     *sync.Cond
+	listenersInFlight int
+
+	captureLock   sync.Mutex
+	captureWriter io.Writer
+	// replaying is set while a Replayer feeds frames through the listeners; guarded by captureLock.
+	replaying bool
+
+	schedulerLock     sync.Mutex
+	schedulerDone     chan struct{}
+	schedulerExited   chan struct{}
+	schedulerInterval time.Duration
+	scheduledPosts    map[string]*ScheduledPost
+
 	isDebug bool
 }
 
@@ -117,6 +156,28 @@ func (rtm *Client) SetDebug(value bool) {
 	rtm.isDebug = value
 }
 
+// SetPingInterval sets how often the client pings slack to check the connection.
+func (rtm *Client) SetPingInterval(interval time.Duration) {
+	rtm.pingInterval = interval
+}
+
+// SetPingTimeout sets how long a ping can be outstanding before it counts as a failure.
+func (rtm *Client) SetPingTimeout(timeout time.Duration) {
+	rtm.pingTimeout = timeout
+}
+
+// SetReconnectDelay sets how long the client waits between failed attempts to reconnect a dropped connection.
+func (rtm *Client) SetReconnectDelay(delay time.Duration) {
+	rtm.reconnectDelay = delay
+}
+
+// SetAPIEndpoint changes the scheme and host the web api (and `rtm.start`) are called on.
+// It defaults to `APIScheme` and `APIEndpoint`, and is useful for pointing the client at a test server.
+func (rtm *Client) SetAPIEndpoint(scheme, host string) {
+	rtm.apiScheme = scheme
+	rtm.apiHost = host
+}
+
 // AddEventListener attaches a new Listener to the given event.
 // There can be multiple listeners to an event.
 // If an event is already being listened for, calling Listen will add a new listener to that event.
@@ -129,6 +190,24 @@ func (rtm *Client) RemoveEventListeners(event Event) {
 	delete(rtm.EventListeners, event)
 }
 
+// State returns the cache of users and channels, loaded when the client connects and kept current from rtm events.
+func (rtm *Client) State() *State {
+	return rtm.state
+}
+
+// ActiveChannelIDs returns a copy of ActiveChannels, the channels the bot is a member of,
+// that is safe to read while events are being handled.
+func (rtm *Client) ActiveChannelIDs() []string {
+	rtm.activeLock.Lock()
+	defer rtm.activeLock.Unlock()
+	return append([]string{}, rtm.ActiveChannels...)
+}
+
+// PlainText renders message text as plain text, resolving mentions with the names in the state cache.
+func (rtm *Client) PlainText(text string) string {
+	return format.PlainText(text, rtm.state)
+}
+
 // Connect be4gins a session with Slack.
 func (rtm *Client) Connect() (*Session, error) {
 	rtm.socketLock.Lock()
@@ -137,8 +216,8 @@ func (rtm *Client) Connect() (*Session, error) {
 	res := Session{}
 	meta, err := NewExternalRequest().
 		AsPost().
-		WithScheme(APIScheme).
-		WithHost(APIEndpoint).
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
 		WithPath("api/rtm.start").
 		WithPostData("token", rtm.Token).
 		WithPostData("no_unreads", "false").
@@ -169,16 +248,26 @@ func (rtm *Client) Connect() (*Session, error) {
 		return nil, err
 	}
 
+	// end the loops of a previous session that was never stopped.
+	if rtm.sessionDone != nil {
+		close(rtm.sessionDone)
+	}
+	done := make(chan struct{})
+	rtm.sessionDone = done
 	rtm.connected = true
+	rtm.state.Load(&res)
 
 	// asynchronously fetch active channels.
 	go rtm.fetchActiveChannels()
 
 	// ping slack every N seconds to make sure the connection is still active.
-	go rtm.pingLoop()
+	go rtm.pingLoop(done)
 
 	// listen for messages.
-	go rtm.listenLoop()
+	go rtm.listenLoop(done)
+
+	// resume any scheduled posts if we were stopped.
+	rtm.startScheduler()
 
 	return &res, nil
 }
@@ -188,18 +277,26 @@ func (rtm *Client) Stop() error {
 	rtm.socketLock.Lock()
 	defer rtm.socketLock.Unlock()
 
+	rtm.stopScheduler()
+
+	// mark the client as disconnected first so the listen and ping loops don't try to reconnect.
+	rtm.connected = false
+	if rtm.sessionDone != nil {
+		close(rtm.sessionDone)
+		rtm.sessionDone = nil
+	}
+
 	if rtm.socketConnection == nil {
 		return nil
 	}
 
+	rtm.socketWriteLock.Lock()
 	closeErr := rtm.socketConnection.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
-	if closeErr != nil {
-		return closeErr
-	}
+	rtm.socketWriteLock.Unlock()
+
 	rtm.socketConnection.Close()
 	rtm.socketConnection = nil
-	rtm.connected = false
-	return nil
+	return closeErr
 }
 
 // SendMessage sends a basic message over the open web socket connection to slack.
@@ -211,33 +308,69 @@ func (rtm *Client) SendMessage(m *Message) error {
 		return exception.New("Connection is closed.")
 	}
 
-	return rtm.socketConnection.WriteJSON(m)
+	return rtm.writeJSON(m)
 }
 
 // Say sends a basic message to a given channelID.
 func (rtm *Client) Say(channelID string, messageComponents ...interface{}) error {
-	rtm.socketLock.RLock()
-	defer rtm.socketLock.RUnlock()
-
-	if rtm.socketConnection == nil {
-		return exception.New("Connection is closed.")
-	}
-
 	m := &Message{Type: "message", Text: fmt.Sprint(messageComponents...), Channel: channelID}
 	return rtm.SendMessage(m)
 }
 
 // Sayf is an overload that uses Printf style replacements for a basic message to a given channelID.
 func (rtm *Client) Sayf(channelID, format string, messageComponents ...interface{}) error {
-	rtm.socketLock.RLock()
-	defer rtm.socketLock.RUnlock()
+	m := &Message{Type: "message", Text: fmt.Sprintf(format, messageComponents...), Channel: channelID}
+	return rtm.SendMessage(m)
+}
 
-	if rtm.socketConnection == nil {
-		return exception.New("Connection is closed.")
+// DirectMessage posts a message to a user in the direct message channel with them,
+// opening the channel first if it isn't cached.
+func (rtm *Client) DirectMessage(userID string, messageComponents ...interface{}) error {
+	channelID, err := rtm.directMessageChannel(userID)
+	if err != nil {
+		return err
 	}
 
-	m := &Message{Type: "message", Text: fmt.Sprintf(format, messageComponents...), Channel: channelID}
-	return rtm.SendMessage(m)
+	_, err = rtm.ChatPostMessage(&ChatMessage{Channel: channelID, Text: fmt.Sprint(messageComponents...), AsUser: OptionalBool(true)})
+	return err
+}
+
+// DirectMessagef is an overload that uses Printf style replacements for DirectMessage.
+func (rtm *Client) DirectMessagef(userID, format string, messageComponents ...interface{}) error {
+	return rtm.DirectMessage(userID, fmt.Sprintf(format, messageComponents...))
+}
+
+// directMessageChannel returns the id of the direct message channel with a user.
+func (rtm *Client) directMessageChannel(userID string) (string, error) {
+	if IsEmpty(userID) {
+		return "", exception.New("`userID` cannot be empty.")
+	}
+	if im, hasIM := rtm.state.IMForUser(userID); hasIM {
+		return im.ID, nil
+	}
+	im, err := rtm.ImOpen(userID)
+	if err != nil {
+		return "", err
+	}
+	return im.ID, nil
+}
+
+// ReplyEphemeral replies to an incoming message with a message only the sender can see.
+// The reply is posted in the message's thread if it has one.
+// Ephemeral messages can't be sent over the socket, so this uses the chat api.
+func (rtm *Client) ReplyEphemeral(m *Message, messageComponents ...interface{}) error {
+	if m == nil {
+		return exception.New("`m` cannot be nil.")
+	}
+
+	reply := &ChatMessage{Channel: m.Channel, Text: fmt.Sprint(messageComponents...), ThreadTimestamp: m.ThreadTimestamp, AsUser: OptionalBool(true)}
+	_, err := rtm.ChatPostEphemeral(m.User, reply)
+	return err
+}
+
+// ReplyEphemeralf is an overload that uses Printf style replacements for ReplyEphemeral.
+func (rtm *Client) ReplyEphemeralf(m *Message, format string, messageComponents ...interface{}) error {
+	return rtm.ReplyEphemeral(m, fmt.Sprintf(format, messageComponents...))
 }
 
 // Ping sends a special type of "ping" message to Slack to remind it to keep the connection open.
@@ -252,18 +385,26 @@ func (rtm *Client) Ping() error {
 
 	p := &Message{ID: time.Now().UTC().UnixNano(), Type: "ping"}
 	rtm.dispatch(p)
+
+	rtm.pingInFlightLock.Lock()
 	rtm.pingInFlight[p.ID] = time.Now().UTC()
-	return rtm.socketConnection.WriteJSON(p)
+	rtm.pingInFlightLock.Unlock()
+
+	return rtm.writeJSON(p)
 }
 
 //--------------------------------------------------------------------------------
 // INTERNAL METHODS
 //--------------------------------------------------------------------------------
 
-func (rtm *Client) pingLoop() {
+func (rtm *Client) pingLoop(done chan struct{}) {
 	var err error
-	for rtm.connected {
-		time.Sleep(rtm.pingInterval)
+	for {
+		select {
+		case <-done:
+			return
+		case <-time.After(rtm.pingInterval):
+		}
 		err = rtm.doPing()
 		if err != nil {
 			rtm.logf("go-slack :: pingLook() :: error => %v", err)
@@ -272,11 +413,8 @@ func (rtm *Client) pingLoop() {
 }
 
 func (rtm *Client) doPing() error {
-	rtm.pingInFlightLock.Lock()
-	defer rtm.pingInFlightLock.Unlock()
-
 	var err error
-	if len(rtm.pingInFlight) < rtm.pingMaxInFlight {
+	if rtm.pingsInFlight() < rtm.pingMaxInFlight {
 		err = rtm.Ping()
 		if err != nil {
 			rtm.logf("doPing() :: ping() :: error => %v\n", err)
@@ -287,6 +425,27 @@ func (rtm *Client) doPing() error {
 		}
 	}
 
+	if rtm.expirePings() > rtm.pingMaxFails {
+		err = rtm.cycleConnection()
+		if err != nil {
+			rtm.logf("doPing() :: cycleConnection() :: error  => %v\n", err)
+		}
+	}
+
+	return nil
+}
+
+func (rtm *Client) pingsInFlight() int {
+	rtm.pingInFlightLock.Lock()
+	defer rtm.pingInFlightLock.Unlock()
+	return len(rtm.pingInFlight)
+}
+
+// expirePings removes pings that have timed out and returns how many there were.
+func (rtm *Client) expirePings() int {
+	rtm.pingInFlightLock.Lock()
+	defer rtm.pingInFlightLock.Unlock()
+
 	now := time.Now().UTC()
 	outstandingPings := 0
 	for k, v := range rtm.pingInFlight {
@@ -295,15 +454,7 @@ func (rtm *Client) doPing() error {
 			outstandingPings++
 		}
 	}
-
-	if outstandingPings > rtm.pingMaxFails {
-		err = rtm.cycleConnection()
-		if err != nil {
-			rtm.logf("doPing() :: cycleConnection() :: error  => %v\n", err)
-		}
-	}
-
-	return nil
+	return outstandingPings
 }
 
 func (rtm *Client) resetPingMetadata() {
@@ -323,11 +474,15 @@ func (rtm *Client) cycleConnection() error {
 	rtm.socketLock.Lock()
 	defer rtm.socketLock.Unlock()
 
+	if !rtm.connected {
+		return exception.New("Connection is closed.")
+	}
+
 	res := Session{}
 	meta, err := NewExternalRequest().
 		AsPost().
-		WithScheme(APIScheme).
-		WithHost(APIEndpoint).
+		WithScheme(rtm.apiScheme).
+		WithHost(rtm.apiHost).
 		WithPath("api/rtm.start").
 		WithPostData("token", rtm.Token).
 		WithPostData("no_unreads", "true").
@@ -338,54 +493,136 @@ func (rtm *Client) cycleConnection() error {
 		return err
 	}
 
+	if !IsEmpty(res.Error) {
+		return exception.New(res.Error)
+	}
+
 	if meta.StatusCode > http.StatusOK {
 		return exception.New("Non-200 Status from Slack, aborting.")
 	}
 
-	rtm.resetPingMetadata()
-
 	u, err := url.Parse(res.URL)
 	if err != nil {
 		return err
 	}
+
+	// the old connection is closed either way; if the dial fails the listen loop retries while it's nil.
+	if rtm.socketConnection != nil {
+		rtm.socketConnection.Close()
+	}
 	rtm.socketConnection, _, err = websocket.DefaultDialer.Dial(u.String(), nil)
-	return err
+	if err != nil {
+		rtm.socketConnection = nil
+		return err
+	}
+
+	rtm.resetPingMetadata()
+	rtm.state.Load(&res)
+	return nil
 }
 
-func (rtm *Client) listenLoop() {
-	var mt MessageType
+// writeJSON serializes writes to the socket; the websocket connection doesn't support concurrent writers.
+// The caller must hold the socket lock.
+func (rtm *Client) writeJSON(v interface{}) error {
+	rtm.socketWriteLock.Lock()
+	defer rtm.socketWriteLock.Unlock()
+	return rtm.socketConnection.WriteJSON(v)
+}
+
+// isDone returns if a session's done channel has been closed.
+func isDone(done chan struct{}) bool {
+	select {
+	case <-done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (rtm *Client) getSocketConnection() *websocket.Conn {
+	rtm.socketLock.RLock()
+	defer rtm.socketLock.RUnlock()
+	return rtm.socketConnection
+}
+
+func (rtm *Client) listenLoop(done chan struct{}) {
 	var messageBytes []byte
 	var err error
 
-	for rtm.connected {
-		_, messageBytes, err = rtm.socketConnection.ReadMessage()
+	for !isDone(done) {
+		conn := rtm.getSocketConnection()
+		if conn == nil {
+			// a reconnect failed; keep trying until it succeeds or the client is stopped.
+			select {
+			case <-done:
+				return
+			case <-time.After(rtm.reconnectDelay):
+			}
+			if rtm.getSocketConnection() == nil {
+				if err = rtm.cycleConnection(); err != nil {
+					rtm.logf("listenLoop() :: cycleConnection() :: error => %v", err)
+				}
+			}
+			continue
+		}
+
+		_, messageBytes, err = conn.ReadMessage()
 		if err != nil {
+			if isDone(done) {
+				return
+			}
 			rtm.logf("listenLoop() :: error => %v", err)
+
+			// the connection dropped; reconnect unless it has already been replaced (i.e. by the ping loop).
+			if rtm.getSocketConnection() == conn {
+				if err = rtm.cycleConnection(); err != nil {
+					rtm.logf("listenLoop() :: cycleConnection() :: error => %v", err)
+					select {
+					case <-done:
+						return
+					case <-time.After(rtm.reconnectDelay):
+					}
+				}
+			}
+			continue
+		}
+
+		rtm.capture(messageBytes)
+		rtm.handleFrame(messageBytes)
+	}
+}
+
+// handleFrame parses a raw rtm frame and dispatches it to the listeners.
+func (rtm *Client) handleFrame(messageBytes []byte) {
+	var mt MessageType
+	err := json.Unmarshal(messageBytes, &mt)
+	if err == nil {
+		if stateErr := rtm.state.update(mt.Type, messageBytes); stateErr != nil {
+			rtm.logf("handleFrame() :: state error => %v", stateErr)
 		}
 
-		err = json.Unmarshal(messageBytes, &mt)
+		m := Message{}
+		err = json.Unmarshal(messageBytes, &m)
 		if err == nil {
-			m := Message{}
-			err = json.Unmarshal(messageBytes, &m)
-			if err == nil {
-				if len(mt.Type) == 0 && m.OK != nil { //special situation where acks don't have types and we have to sniff.
-					rtm.dispatch(&Message{Type: EventMessageACK, ReplyTo: m.ReplyTo, Timestamp: m.Timestamp, Text: m.Text})
-				} else {
-					rtm.dispatch(&m)
-				}
+			if len(mt.Type) == 0 && m.OK != nil { //special situation where acks don't have types and we have to sniff.
+				rtm.dispatch(&Message{Type: EventMessageACK, ReplyTo: m.ReplyTo, Timestamp: m.Timestamp, Text: m.Text})
 			} else {
-				rtm.logf("listenLoop() :: error => %v", err)
+				rtm.dispatch(&m)
 			}
 		} else {
-			rtm.logf("listenLoop() :: error => %v", err)
+			rtm.logf("handleFrame() :: error => %v", err)
 		}
+	} else {
+		rtm.logf("handleFrame() :: error => %v", err)
 	}
 }
 
 func (rtm *Client) dispatch(m *Message) {
 	if listeners, hasListeners := rtm.EventListeners[m.Type]; hasListeners {
 		for index := range listeners {
+			rtm.listenerStarted()
 			go func(listener EventListener) {
+				defer rtm.listenerFinished()
 				defer func() {
 					if r := recover(); r != nil {
 						rtm.logf("go-slack: dispatch() fatal: %#v\n", r)
@@ -399,24 +636,27 @@ func (rtm *Client) dispatch(m *Message) {
 }
 
 func (rtm *Client) handleChannelJoined(client *Client, message *Message) {
-	rtm.activeLock.Lock()
-	defer rtm.activeLock.Unlock()
-	rtm.ActiveChannels = append(rtm.ActiveChannels, message.Channel)
+	rtm.addActiveChannel(message.Channel)
 }
 
 func (rtm *Client) handleChannelUnarchive(client *Client, message *Message) {
-	rtm.activeLock.Lock()
-	defer rtm.activeLock.Unlock()
-
+	// a replayed unarchive is from another time (and maybe another workspace); don't look the channel up.
+	if rtm.isReplaying() {
+		return
+	}
 	channel, err := rtm.ChannelsInfo(message.Channel)
 	if err != nil {
 		return
 	}
 	if channel.IsMember {
-		rtm.ActiveChannels = append(rtm.ActiveChannels, message.Channel)
+		rtm.addActiveChannel(message.Channel)
 	}
 }
 
+func (rtm *Client) handleChannelArchive(client *Client, message *Message) {
+	rtm.removeActiveChannel(message.Channel)
+}
+
 func (rtm *Client) handleChannelLeft(client *Client, message *Message) {
 	rtm.removeActiveChannel(message.Channel)
 }
@@ -436,12 +676,21 @@ func (rtm *Client) fetchActiveChannels() {
 
 	for x := 0; x < len(channels); x++ {
 		channel := channels[x]
-		if channel.IsMember && !channel.IsArchived {
+		if channel.IsMember && !channel.IsArchived && !containsString(rtm.ActiveChannels, channel.ID) {
 			rtm.ActiveChannels = append(rtm.ActiveChannels, channel.ID)
 		}
 	}
 }
 
+func (rtm *Client) addActiveChannel(channelID string) {
+	rtm.activeLock.Lock()
+	defer rtm.activeLock.Unlock()
+
+	if !containsString(rtm.ActiveChannels, channelID) {
+		rtm.ActiveChannels = append(rtm.ActiveChannels, channelID)
+	}
+}
+
 func (rtm *Client) removeActiveChannel(channelID string) {
 	rtm.activeLock.Lock()
 	defer rtm.activeLock.Unlock()
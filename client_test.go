@@ -22,3 +22,31 @@ func TestClientRemoveEventListener(t *testing.T) {
 	c.RemoveEventListeners(EventBotAdded)
 	assert.Empty(c.EventListeners[EventBotAdded])
 }
+
+func TestClientItemEvents(t *testing.T) {
+	assert := assert.New(t)
+	c := NewClient(UUIDv4().ToShortString())
+	c.SetDebug(false)
+
+	var items []*Item
+	listener := func(c *Client, m *Message) {
+		items = append(items, m.Item)
+	}
+	c.AddEventListener(EventPinAdded, listener)
+	c.AddEventListener(EventStarAdded, listener)
+	c.AddEventListener(EventReactionAdded, listener)
+
+	c.handleFrame([]byte(`{"type":"pin_added","user":"U1","channel_id":"C1","item":{"type":"message","channel":"C1","message":{"type":"message","user":"U1","text":"pin me","ts":"1456528852.000005"},"created":1508881078,"created_by":"U1"},"event_ts":"1508881078.000001"}`))
+	c.WaitForListeners()
+	c.handleFrame([]byte(`{"type":"star_added","user":"U1","item":{"type":"file","file":{"id":"F1","name":"runbook.pdf"},"date_create":1508881080},"event_ts":"1508881080.000001"}`))
+	c.WaitForListeners()
+	c.handleFrame([]byte(`{"type":"reaction_added","user":"U1","reaction":"eyes","item_user":"U2","item":{"type":"file","file":"F1"},"event_ts":"1508881081.000001"}`))
+	c.WaitForListeners()
+
+	assert.Len(items, 3)
+	assert.Equal("pin me", items[0].Message.Text)
+	assert.Equal("U1", items[0].CreatedBy)
+	assert.Equal(ItemTypeFile, items[1].Type)
+	assert.Equal("F1", items[1].File.ID)
+	assert.Equal(FileRef("F1"), items[2].Ref())
+}
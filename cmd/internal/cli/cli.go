@@ -0,0 +1,173 @@
+// Package cli has the helpers shared by the commands in cmd: resolving channels by name, parsing message timestamps,
+// and reading `key = value` config files from the user's home directory.
+package cli
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	exception "github.com/blendlabs/go-exception"
+	slack "github.com/wcharczuk/go-slack"
+)
+
+// pageSize is the page size of the paged list calls.
+var pageSize = 200
+
+// timestampExpr matches a message `ts`, i.e. `1503435956.000247`.
+var timestampExpr = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
+
+// ResolveChannel returns the id of a channel or private channel by name (i.e. `ops` or `#ops`),
+// of the direct message with a user by name (i.e. `@alice`), or the channel id itself.
+func ResolveChannel(client *slack.Client, channel string) (string, error) {
+	if strings.HasPrefix(channel, "@") {
+		users, err := Users(client)
+		if err != nil {
+			return "", err
+		}
+		for _, user := range users {
+			if user.Name == channel[1:] {
+				im, err := client.ImOpen(user.ID)
+				if err != nil {
+					return "", err
+				}
+				return im.ID, nil
+			}
+		}
+		return "", exception.New("user `" + channel + "` not found.")
+	}
+	if IsChannelID(channel) {
+		return channel, nil
+	}
+
+	name := strings.TrimPrefix(channel, "#")
+	channels, err := Channels(client, false)
+	if err != nil {
+		return "", err
+	}
+	for _, c := range channels {
+		if c.Name == name {
+			return c.ID, nil
+		}
+	}
+	groups, err := Groups(client, false)
+	if err != nil {
+		return "", err
+	}
+	for _, g := range groups {
+		if g.Name == name {
+			return g.ID, nil
+		}
+	}
+	return "", exception.New("channel `" + channel + "` not found.")
+}
+
+// Users returns all the users of a workspace, following `next_cursor` until the last page.
+func Users(client *slack.Client) ([]slack.User, error) {
+	users := []slack.User{}
+	var cursor string
+	for {
+		page, err := client.UsersListPage(pageSize, cursor)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, page.Users...)
+		cursor = page.ResponseMetadata.NextCursor
+		if len(cursor) == 0 || len(page.Users) == 0 {
+			return users, nil
+		}
+	}
+}
+
+// Channels returns all the channels of a workspace, following `next_cursor` until the last page.
+func Channels(client *slack.Client, excludeArchived bool) ([]slack.Channel, error) {
+	channels := []slack.Channel{}
+	var cursor string
+	for {
+		page, err := client.ChannelsListPage(excludeArchived, pageSize, cursor)
+		if err != nil {
+			return nil, err
+		}
+		channels = append(channels, page.Channels...)
+		cursor = page.ResponseMetadata.NextCursor
+		if len(cursor) == 0 || len(page.Channels) == 0 {
+			return channels, nil
+		}
+	}
+}
+
+// Groups returns all the private channels the calling user is a member of, following `next_cursor` until the last page.
+func Groups(client *slack.Client, excludeArchived bool) ([]slack.Group, error) {
+	groups := []slack.Group{}
+	var cursor string
+	for {
+		page, err := client.GroupsListPage(excludeArchived, pageSize, cursor)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, page.Groups...)
+		cursor = page.ResponseMetadata.NextCursor
+		if len(cursor) == 0 || len(page.Groups) == 0 {
+			return groups, nil
+		}
+	}
+}
+
+// ParseTimestamp parses a message `ts`, i.e. `1503435956.000247`.
+func ParseTimestamp(value string) (*slack.Timestamp, error) {
+	if !timestampExpr.MatchString(value) {
+		return nil, exception.New("invalid ts `" + value + "`.")
+	}
+	ts := &slack.Timestamp{}
+	if err := ts.UnmarshalJSON([]byte(value)); err != nil {
+		return nil, exception.Wrap(err)
+	}
+	return ts, nil
+}
+
+// IsChannelID returns if a value is a channel, private channel or direct message id; channel names are lowercase.
+func IsChannelID(value string) bool {
+	if len(value) < 2 || !strings.ContainsAny(value[:1], "CGD") {
+		return false
+	}
+	for _, r := range value {
+		if !(r >= 'A' && r <= 'Z') && !(r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// LoadConfig reads the `key = value` lines of a config file, skipping blank lines and `#` comments.
+// An empty path reads defaultFile in the user's home directory, which is empty if it doesn't exist.
+func LoadConfig(path, defaultFile string) (map[string]string, error) {
+	config := map[string]string{}
+	isDefault := len(path) == 0
+	if isDefault {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return config, nil
+		}
+		path = filepath.Join(home, defaultFile)
+	}
+
+	contents, err := ioutil.ReadFile(path)
+	if isDefault && os.IsNotExist(err) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, exception.Wrap(err)
+	}
+	for _, line := range strings.Split(string(contents), "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if pieces := strings.SplitN(line, "=", 2); len(pieces) == 2 {
+			config[strings.TrimSpace(pieces[0])] = strings.TrimSpace(pieces[1])
+		}
+	}
+	return config, nil
+}
@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	assert "github.com/blendlabs/go-assert"
+	slack "github.com/wcharczuk/go-slack"
+	"github.com/wcharczuk/go-slack/slacktest"
+)
+
+func TestResolveChannel(t *testing.T) {
+	a := assert.New(t)
+	server := slacktest.New()
+	defer server.Close()
+	server.Workspace.AddGroup(slack.Group{ID: "G0SECRET", Name: "secret", Members: []string{slacktest.DefaultBotID}})
+	client := server.Client()
+
+	for _, channel := range []string{"general", "#general", slacktest.DefaultChannelID} {
+		channelID, err := ResolveChannel(client, channel)
+		a.Nil(err)
+		a.Equal(slacktest.DefaultChannelID, channelID)
+	}
+	channelID, err := ResolveChannel(client, "secret")
+	a.Nil(err)
+	a.Equal("G0SECRET", channelID)
+
+	channelID, err = ResolveChannel(client, "@user")
+	a.Nil(err)
+	a.True(strings.HasPrefix(channelID, "D"))
+
+	_, err = ResolveChannel(client, "@nobody")
+	a.NotNil(err)
+	_, err = ResolveChannel(client, "nowhere")
+	a.NotNil(err)
+}
+
+func TestResolveChannelPages(t *testing.T) {
+	a := assert.New(t)
+	defer func(size int) { pageSize = size }(pageSize)
+	pageSize = 1
+
+	server := slacktest.New()
+	defer server.Close()
+	server.Workspace.AddChannel(slack.Channel{ID: "C0LAST", Name: "zzz-last"})
+	server.Workspace.AddGroup(slack.Group{ID: "G0LAST", Name: "zzz-secret", Members: []string{slacktest.DefaultBotID}})
+	server.Workspace.AddUser(slack.User{ID: "U0LAST", Name: "zzz"})
+	client := server.Client()
+
+	channelID, err := ResolveChannel(client, "zzz-last")
+	a.Nil(err)
+	a.Equal("C0LAST", channelID)
+	channelID, err = ResolveChannel(client, "zzz-secret")
+	a.Nil(err)
+	a.Equal("G0LAST", channelID)
+	channelID, err = ResolveChannel(client, "@zzz")
+	a.Nil(err)
+	a.True(strings.HasPrefix(channelID, "D"))
+
+	calls := len(server.Calls("users.list"))
+	users, err := Users(client)
+	a.Nil(err)
+	a.True(len(users) > 1)
+	a.Len(server.Calls("users.list"), calls+len(users))
+}
+
+func TestParseTimestamp(t *testing.T) {
+	a := assert.New(t)
+	ts, err := ParseTimestamp("1503435956.000247")
+	a.Nil(err)
+	a.Equal("1503435956.000247", ts.String())
+	ts, err = ParseTimestamp("1503435956")
+	a.Nil(err)
+	a.Equal(int64(1503435956), ts.Time().Unix())
+
+	for _, value := range []string{"", "yesterday", "1503435956.", ".000247", "p1503435956000247"} {
+		_, err = ParseTimestamp(value)
+		a.NotNil(err)
+	}
+}
+
+func TestIsChannelID(t *testing.T) {
+	a := assert.New(t)
+	a.True(IsChannelID("C024BE91L"))
+	a.True(IsChannelID("D0ALICE"))
+	a.False(IsChannelID("general"))
+	a.False(IsChannelID("U0ALICE"))
+	a.False(IsChannelID("C"))
+}
+
+func TestLoadConfig(t *testing.T) {
+	a := assert.New(t)
+	dir, err := ioutil.TempDir("", "cli")
+	a.Nil(err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "config")
+	a.Nil(ioutil.WriteFile(path, []byte("# ops alerts\ntoken = xoxb-1234\nchannel=ops\nnot a setting\n"), 0600))
+	config, err := LoadConfig(path, ".cli")
+	a.Nil(err)
+	a.Equal(map[string]string{"token": "xoxb-1234", "channel": "ops"}, config)
+
+	_, err = LoadConfig(filepath.Join(dir, "missing"), ".cli")
+	a.NotNil(err)
+}
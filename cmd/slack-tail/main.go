@@ -0,0 +1,306 @@
+// Command slack-tail connects to the rtm api and prints incoming events, with user and channel names
+// from the session:
+//
+//	slack-tail
+//	slack-tail -type message,reaction_added -channel general,ops
+//	slack-tail -user alice -match 'deploy(ed|ing)'
+//	slack-tail -json > rtm.jsonl
+//	slack-tail -json | jq .frame
+//
+// `-json` prints the raw frames as a capture (one slack.CapturedFrame per line), which can be replayed
+// through a bot's listeners with `slack.ReplayFile`. The token is read from `-token` or `$SLACK_TOKEN`.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	exception "github.com/blendlabs/go-exception"
+	slack "github.com/wcharczuk/go-slack"
+)
+
+const (
+	// tokenEnvironmentVariable is read when `-token` isn't set.
+	tokenEnvironmentVariable = "SLACK_TOKEN"
+	// timeFormat is the time format of printed events.
+	timeFormat = "15:04:05"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "slack-tail: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("slack-tail", flag.ContinueOnError)
+	token := flags.String("token", "", "the api token (default $"+tokenEnvironmentVariable+")")
+	types := flags.String("type", "", "comma separated event types or message subtypes to print (default all)")
+	channels := flags.String("channel", "", "comma separated channel names or ids to print events from")
+	users := flags.String("user", "", "comma separated user names or ids to print events from")
+	match := flags.String("match", "", "a regular expression the event's raw json must match")
+	jsonl := flags.Bool("json", false, "print raw frames as a replayable capture (jsonl)")
+	all := flags.Bool("all", false, "include pongs and message acks")
+	debug := flags.Bool("debug", false, "log client debug output")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if len(*token) == 0 {
+		*token = os.Getenv(tokenEnvironmentVariable)
+	}
+	if len(*token) == 0 {
+		return exception.New("no token; set -token or $" + tokenEnvironmentVariable + ".")
+	}
+
+	client := slack.NewClient(*token)
+	client.SetDebug(*debug)
+	t := &tail{client: client, out: stdout}
+	t.types = splitList(*types)
+	t.channels = splitList(*channels)
+	t.users = splitList(*users)
+	t.jsonl = *jsonl
+	t.all = *all
+	if len(*match) > 0 {
+		pattern, err := regexp.Compile(*match)
+		if err != nil {
+			return exception.Wrap(err)
+		}
+		t.match = pattern
+	}
+
+	// frames are captured in the order they're read, unlike listeners, which are dispatched concurrently.
+	client.SetCapture(t)
+	if _, err := client.Connect(); err != nil {
+		return err
+	}
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	<-interrupt
+	return client.Stop()
+}
+
+// tail filters and prints captured rtm frames; it is the client's capture writer.
+type tail struct {
+	client *slack.Client
+	out    io.Writer
+
+	types    []string
+	channels []string
+	users    []string
+	match    *regexp.Regexp
+	jsonl    bool
+	all      bool
+
+	lock    sync.Mutex
+	pending []byte
+}
+
+// event is the part of an rtm frame slack-tail prints.
+type event struct {
+	Type     string          `json:"type"`
+	SubType  string          `json:"subtype"`
+	Channel  json.RawMessage `json:"channel"`
+	User     json.RawMessage `json:"user"`
+	Username string          `json:"username"`
+	Text     string          `json:"text"`
+	ReplyTo  *int64          `json:"reply_to"`
+	Item     *struct {
+		Channel string `json:"channel"`
+	} `json:"item"`
+	Message *struct {
+		User string `json:"user"`
+		Text string `json:"text"`
+	} `json:"message"`
+}
+
+// Write implements io.Writer, handling each complete line.
+func (t *tail) Write(contents []byte) (int, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.pending = append(t.pending, contents...)
+	for {
+		index := bytes.IndexByte(t.pending, '\n')
+		if index < 0 {
+			return len(contents), nil
+		}
+		line := t.pending[:index]
+		t.pending = t.pending[index+1:]
+		if err := t.handle(line); err != nil {
+			return len(contents), err
+		}
+	}
+}
+
+func (t *tail) handle(line []byte) error {
+	var captured slack.CapturedFrame
+	if err := json.Unmarshal(line, &captured); err != nil {
+		return exception.Wrap(err)
+	}
+	var e event
+	if err := json.Unmarshal(captured.Frame, &e); err != nil {
+		return exception.Wrap(err)
+	}
+	if !t.matches(&e, captured.Frame) {
+		return nil
+	}
+
+	var err error
+	if t.jsonl {
+		_, err = t.out.Write(append(append([]byte{}, line...), '\n'))
+	} else {
+		_, err = fmt.Fprintln(t.out, t.format(captured.Time, &e))
+	}
+	if err != nil {
+		return exception.Wrap(err)
+	}
+	return nil
+}
+
+func (t *tail) matches(e *event, frame []byte) bool {
+	if !t.all && (e.Type == string(slack.EventPong) || (len(e.Type) == 0 && e.ReplyTo != nil)) {
+		return false
+	}
+	if len(t.types) > 0 && !contains(t.types, e.Type) && !(len(e.SubType) > 0 && contains(t.types, e.SubType)) {
+		return false
+	}
+	if len(t.channels) > 0 {
+		channelID := e.channelID()
+		if len(channelID) == 0 || !(contains(t.channels, channelID) || contains(t.channels, t.client.State().ChannelName(channelID))) {
+			return false
+		}
+	}
+	if len(t.users) > 0 {
+		userID := e.userID()
+		if len(userID) == 0 || !(contains(t.users, userID) || contains(t.users, t.client.State().UserName(userID))) {
+			return false
+		}
+	}
+	if t.match != nil && !t.match.Match(frame) {
+		return false
+	}
+	return true
+}
+
+// format returns an event as `15:04:05 message/subtype #channel @user: text`.
+func (t *tail) format(at time.Time, e *event) string {
+	parts := []string{at.Local().Format(timeFormat)}
+	if len(e.Type) == 0 {
+		parts = append(parts, "ack")
+	} else if len(e.SubType) > 0 {
+		parts = append(parts, e.Type+"/"+e.SubType)
+	} else {
+		parts = append(parts, e.Type)
+	}
+	if channelID := e.channelID(); len(channelID) > 0 {
+		parts = append(parts, t.channelLabel(channelID))
+	}
+	if userID := e.userID(); len(userID) > 0 {
+		parts = append(parts, "@"+t.name(t.client.State().UserName(userID), userID))
+	} else if len(e.Username) > 0 {
+		parts = append(parts, "@"+e.Username)
+	}
+
+	line := strings.Join(parts, " ")
+	if text := e.text(); len(text) > 0 {
+		line += ": " + strings.Replace(t.client.PlainText(text), "\n", "\n\t", -1)
+	}
+	return line
+}
+
+// channelLabel returns `#name` for channels and private channels, and `im:@name` for direct messages.
+func (t *tail) channelLabel(channelID string) string {
+	state := t.client.State()
+	if im, isIM := state.IM(channelID); isIM {
+		return "im:@" + t.name(state.UserName(im.User), im.User)
+	}
+	return "#" + t.name(state.ChannelName(channelID), channelID)
+}
+
+func (t *tail) name(name, id string) string {
+	if len(name) > 0 {
+		return name
+	}
+	return id
+}
+
+// channelID returns the event's channel id; `channel` is an object in events like `channel_created`,
+// and reaction and star events name it on their item.
+func (e *event) channelID() string {
+	if channelID := idOf(e.Channel); len(channelID) > 0 {
+		return channelID
+	}
+	if e.Item != nil {
+		return e.Item.Channel
+	}
+	return ""
+}
+
+// userID returns the event's user id; `user` is an object in events like `user_change`,
+// and edited messages name it on the new message.
+func (e *event) userID() string {
+	if userID := idOf(e.User); len(userID) > 0 {
+		return userID
+	}
+	if e.Message != nil {
+		return e.Message.User
+	}
+	return ""
+}
+
+func (e *event) text() string {
+	if len(e.Text) == 0 && e.Message != nil {
+		return e.Message.Text
+	}
+	return e.Text
+}
+
+// idOf returns an id from a json string, or from the `id` of a json object.
+func idOf(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var id string
+	if err := json.Unmarshal(raw, &id); err == nil {
+		return id
+	}
+	var object struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(raw, &object); err == nil {
+		return object.ID
+	}
+	return ""
+}
+
+// splitList splits a comma separated flag, dropping `#` and `@` prefixes from names.
+func splitList(value string) []string {
+	var values []string
+	for _, piece := range strings.Split(value, ",") {
+		if piece = strings.TrimLeft(strings.TrimSpace(piece), "#@"); len(piece) > 0 {
+			values = append(values, piece)
+		}
+	}
+	return values
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
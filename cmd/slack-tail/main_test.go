@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	assert "github.com/blendlabs/go-assert"
+	slack "github.com/wcharczuk/go-slack"
+	"github.com/wcharczuk/go-slack/slacktest"
+)
+
+// syncBuffer is a bytes.Buffer that's safe to read while the client's listen loop writes to it.
+type syncBuffer struct {
+	lock   sync.Mutex
+	buffer bytes.Buffer
+}
+
+func (sb *syncBuffer) Write(contents []byte) (int, error) {
+	sb.lock.Lock()
+	defer sb.lock.Unlock()
+	return sb.buffer.Write(contents)
+}
+
+func (sb *syncBuffer) Lines() []string {
+	sb.lock.Lock()
+	defer sb.lock.Unlock()
+	return strings.Split(strings.TrimSpace(sb.buffer.String()), "\n")
+}
+
+func newTestTail() (*tail, *bytes.Buffer) {
+	client := slack.NewClient("xoxb-test")
+	client.State().SetUser(slack.User{ID: "U0ALICE", Name: "alice"})
+	client.State().SetChannel(slack.Channel{ID: "C0OPS", Name: "ops"})
+	client.State().SetIM(slack.InstantMessage{ID: "D0ALICE", User: "U0ALICE"})
+	out := bytes.NewBuffer(nil)
+	return &tail{client: client, out: out}, out
+}
+
+func capturedLine(a *assert.Assertions, frame string) []byte {
+	line, err := json.Marshal(slack.CapturedFrame{Time: time.Date(2019, 1, 1, 12, 30, 0, 0, time.Local), Frame: json.RawMessage(frame)})
+	a.Nil(err)
+	return append(line, '\n')
+}
+
+func TestTailFormat(t *testing.T) {
+	a := assert.New(t)
+	tail, out := newTestTail()
+
+	frames := []string{
+		`{"type":"message","channel":"C0OPS","user":"U0ALICE","text":"ping <@U0ALICE>\nsecond line"}`,
+		`{"type":"message","subtype":"bot_message","channel":"D0ALICE","username":"deploys","text":"deployed"}`,
+		`{"type":"reaction_added","user":"U0ALICE","reaction":"shipit","item":{"type":"message","channel":"C0OPS","ts":"1546300800.000001"}}`,
+		`{"type":"channel_created","channel":{"id":"C0NEW","name":"new","creator":"U0ALICE"}}`,
+		`{"type":"pong","reply_to":1}`,
+		`{"ok":true,"reply_to":2,"ts":"1546300800.000002","text":"hi"}`,
+	}
+	var capture []byte
+	for _, frame := range frames {
+		capture = append(capture, capturedLine(a, frame)...)
+	}
+	// captures can be written in pieces.
+	_, err := tail.Write(capture[:10])
+	a.Nil(err)
+	_, err = tail.Write(capture[10:])
+	a.Nil(err)
+
+	a.Equal(strings.Join([]string{
+		"12:30:00 message #ops @alice: ping @alice",
+		"\tsecond line",
+		"12:30:00 message/bot_message im:@alice @deploys: deployed",
+		"12:30:00 reaction_added #ops @alice",
+		"12:30:00 channel_created #C0NEW",
+	}, "\n")+"\n", out.String())
+}
+
+func TestTailFilters(t *testing.T) {
+	a := assert.New(t)
+	frames := []string{
+		`{"type":"message","channel":"C0OPS","user":"U0ALICE","text":"deployed"}`,
+		`{"type":"message","channel":"C0GENERAL","user":"U0BOB","text":"deploying"}`,
+		`{"type":"message","subtype":"bot_message","channel":"C0OPS","text":"build failed"}`,
+		`{"type":"reaction_added","user":"U0BOB","item":{"channel":"C0OPS"}}`,
+	}
+	run := func(configure func(*tail)) []string {
+		tail, out := newTestTail()
+		configure(tail)
+		for _, frame := range frames {
+			_, err := tail.Write(capturedLine(a, frame))
+			a.Nil(err)
+		}
+		var texts []string
+		for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+			if len(line) > 0 {
+				texts = append(texts, line[len("12:30:00 "):])
+			}
+		}
+		return texts
+	}
+
+	a.Equal([]string{"message #ops @alice: deployed", "message/bot_message #ops: build failed", "reaction_added #ops @U0BOB"},
+		run(func(t *tail) { t.channels = splitList("#ops") }))
+	a.Equal([]string{"message #C0GENERAL @U0BOB: deploying", "reaction_added #ops @U0BOB"},
+		run(func(t *tail) { t.users = splitList("U0BOB") }))
+	a.Equal([]string{"message/bot_message #ops: build failed", "reaction_added #ops @U0BOB"},
+		run(func(t *tail) { t.types = splitList("bot_message, reaction_added") }))
+	a.Equal([]string{"message #ops @alice: deployed", "message #C0GENERAL @U0BOB: deploying"},
+		run(func(t *tail) { t.match = regexp.MustCompile(`deploy(ed|ing)`) }))
+	a.Equal([]string{"message #ops @alice: deployed"},
+		run(func(t *tail) { t.users = splitList("@alice"); t.channels = splitList("C0OPS") }))
+}
+
+func TestTailConnected(t *testing.T) {
+	a := assert.New(t)
+	server := slacktest.New()
+	defer server.Close()
+
+	client := server.Client()
+	out := &syncBuffer{}
+	client.SetCapture(&tail{client: client, out: out, jsonl: true, types: []string{"message"}})
+	_, err := client.Connect()
+	a.Nil(err)
+	defer client.Stop()
+
+	_, err = server.SendMessage(slacktest.DefaultChannelID, slacktest.DefaultUserID, "ping")
+	a.Nil(err)
+	deadline := time.Now().Add(time.Second)
+	for len(out.Lines()[0]) == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// the output is a capture, so it replays through listeners.
+	lines := out.Lines()
+	a.Len(lines, 1)
+	replayed := slack.NewClient("xoxb-test")
+	replayed.SetDebug(false)
+	var text string
+	replayed.AddEventListener(slack.EventMessage, func(c *slack.Client, m *slack.Message) {
+		text = m.Text
+	})
+	count, err := slack.NewReplayer(replayed, strings.NewReader(lines[0])).Replay()
+	a.Nil(err)
+	a.Equal(1, count)
+	a.Equal("ping", text)
+}
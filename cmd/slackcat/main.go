@@ -0,0 +1,325 @@
+// Command slackcat posts text and files to a channel, direct message or thread:
+//
+//	echo "deploy finished" | slackcat -channel ops
+//	slackcat -channel @alice -code build.log
+//	slackcat -channel ops -upload report.csv
+//	tail -f app.log | slackcat -channel ops -stream
+//
+// Files named as arguments are read in order, otherwise stdin is read. The `ts` of each posted message is
+// printed, so scripts can reply in its thread with `-thread`.
+//
+// The token is read from `-token`, then `$SLACK_TOKEN`, then the config file (`~/.slackcat` by default),
+// which holds `key = value` lines:
+//
+//	token = xoxb-...
+//	channel = ops
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	exception "github.com/blendlabs/go-exception"
+	slack "github.com/wcharczuk/go-slack"
+	"github.com/wcharczuk/go-slack/cmd/internal/cli"
+	"github.com/wcharczuk/go-slack/format"
+)
+
+const (
+	// tokenEnvironmentVariable is read when `-token` isn't set.
+	tokenEnvironmentVariable = "SLACK_TOKEN"
+	// defaultConfigFile is the config file in the user's home directory.
+	defaultConfigFile = ".slackcat"
+	// maxMessageLength is the longest message slackcat posts; longer text is split across messages.
+	// It's measured before escaping, which at most grows text five times, to stay under slack's limit.
+	maxMessageLength = 4000
+	// codeBlock wraps text in `-code` mode.
+	codeBlock = "```"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "slackcat: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	flags := flag.NewFlagSet("slackcat", flag.ContinueOnError)
+	token := flags.String("token", "", "the api token (default $"+tokenEnvironmentVariable+" or the config file's `token`)")
+	configPath := flags.String("config", "", "the config file (default ~/"+defaultConfigFile+")")
+	channel := flags.String("channel", "", "the channel name, `@user` or id to post to (default the config file's `channel`)")
+	thread := flags.String("thread", "", "the `ts` of a message to reply to in its thread")
+	code := flags.Bool("code", false, "wrap text in a code block")
+	upload := flags.Bool("upload", false, "upload the input as files instead of posting it as text")
+	filename := flags.String("filename", "stdin", "the file name of uploaded stdin")
+	title := flags.String("title", "", "the title of uploaded files")
+	comment := flags.String("comment", "", "the message uploaded files are shared with")
+	stream := flags.Bool("stream", false, "post input as it arrives, updating one message")
+	interval := flags.Duration("interval", time.Second, "how often a streamed message is updated")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	config, err := cli.LoadConfig(*configPath, defaultConfigFile)
+	if err != nil {
+		return err
+	}
+	if len(*token) == 0 {
+		*token = os.Getenv(tokenEnvironmentVariable)
+	}
+	if len(*token) == 0 {
+		*token = config["token"]
+	}
+	if len(*token) == 0 {
+		return exception.New("no token; set -token, $" + tokenEnvironmentVariable + " or the config file's `token`.")
+	}
+	if len(*channel) == 0 {
+		*channel = config["channel"]
+	}
+	if len(*channel) == 0 {
+		return exception.New("no channel; set -channel or the config file's `channel`.")
+	}
+	if *interval <= 0 {
+		return exception.New("-interval must be positive.")
+	}
+	var threadTimestamp *slack.Timestamp
+	if len(*thread) > 0 {
+		if threadTimestamp, err = cli.ParseTimestamp(*thread); err != nil {
+			return exception.New("-thread: " + err.Error())
+		}
+	}
+
+	client := slack.NewClient(*token)
+	client.SetDebug(false)
+	channelID, err := cli.ResolveChannel(client, *channel)
+	if err != nil {
+		return err
+	}
+
+	sc := &slackcat{client: client, channelID: channelID, thread: threadTimestamp, code: *code, interval: *interval, out: stdout}
+
+	if *stream {
+		return sc.stream(stdin)
+	}
+	if *upload {
+		if flags.NArg() == 0 {
+			return sc.upload(*filename, *title, *comment, stdin)
+		}
+		for _, path := range flags.Args() {
+			if err := sc.uploadFile(path, *title, *comment); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	text, err := readInput(flags.Args(), stdin)
+	if err != nil {
+		return err
+	}
+	return sc.post(text)
+}
+
+// slackcat posts to a channel.
+type slackcat struct {
+	client    *slack.Client
+	channelID string
+	thread    *slack.Timestamp
+	code      bool
+	interval  time.Duration
+	out       io.Writer
+}
+
+// post posts text, split into as many messages as it needs.
+func (sc *slackcat) post(text string) error {
+	for _, chunk := range split(strings.TrimRight(text, "\n"), sc.maxLength()) {
+		if _, err := sc.postMessage(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stream posts lines as they're read, updating the current message at most once an interval,
+// and starting a new message when the current one is full.
+func (sc *slackcat) stream(r io.Reader) error {
+	lines := make(chan string)
+	readErr := make(chan error, 1)
+	go func() {
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		readErr <- scanner.Err()
+		close(lines)
+	}()
+
+	ticker := time.NewTicker(sc.interval)
+	defer ticker.Stop()
+
+	var ts *slack.Timestamp
+	var current []string
+	var dirty bool
+	flush := func() error {
+		if !dirty {
+			return nil
+		}
+		dirty = false
+		text := strings.Join(current, "\n")
+		if len(strings.TrimSpace(text)) == 0 {
+			return nil
+		}
+		if ts == nil {
+			posted, err := sc.postMessage(text)
+			ts = posted
+			return err
+		}
+		_, err := sc.client.ChatUpdate(*ts, &slack.ChatMessage{Channel: sc.channelID, Text: sc.format(text)})
+		return err
+	}
+
+	length := 0
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				if err := flush(); err != nil {
+					return err
+				}
+				if err := <-readErr; err != nil {
+					return exception.Wrap(err)
+				}
+				return nil
+			}
+			if len(line) > sc.maxLength() {
+				line = line[:runeBoundary(line, sc.maxLength())]
+			}
+			if len(current) > 0 && length+1+len(line) > sc.maxLength() {
+				if err := flush(); err != nil {
+					return err
+				}
+				ts, current, length = nil, nil, 0
+			}
+			if len(current) > 0 {
+				length++
+			}
+			current = append(current, line)
+			length += len(line)
+			dirty = true
+		case <-ticker.C:
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// uploadFile uploads a file, titled with its name unless a title is given.
+func (sc *slackcat) uploadFile(path, title, comment string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return exception.Wrap(err)
+	}
+	defer f.Close()
+	return sc.upload(filepath.Base(path), title, comment, f)
+}
+
+func (sc *slackcat) upload(filename, title, comment string, contents io.Reader) error {
+	file, err := sc.client.FilesUpload(&slack.FileUpload{
+		Channels:        []string{sc.channelID},
+		Filename:        filename,
+		Title:           title,
+		InitialComment:  comment,
+		ThreadTimestamp: sc.thread,
+	}, contents)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(sc.out, file.ID)
+	return nil
+}
+
+// postMessage posts a message and prints its `ts`.
+func (sc *slackcat) postMessage(text string) (*slack.Timestamp, error) {
+	res, err := sc.client.ChatPostMessage(&slack.ChatMessage{Channel: sc.channelID, Text: sc.format(text), ThreadTimestamp: sc.thread})
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintln(sc.out, res.Timestamp.String())
+	return &res.Timestamp, nil
+}
+
+// format escapes piped text, so `&`, `<` and `>` aren't read as mentions or links, and wraps it in `-code` mode.
+func (sc *slackcat) format(text string) string {
+	text = format.Escape(text)
+	if sc.code {
+		return codeBlock + "\n" + text + "\n" + codeBlock
+	}
+	return text
+}
+
+// maxLength is the longest text that fits in a message, leaving room for the code block.
+func (sc *slackcat) maxLength() int {
+	if sc.code {
+		return maxMessageLength - 2*(len(codeBlock)+1)
+	}
+	return maxMessageLength
+}
+
+// readInput reads and concatenates files, or stdin if there aren't any.
+func readInput(paths []string, stdin io.Reader) (string, error) {
+	if len(paths) == 0 {
+		contents, err := ioutil.ReadAll(stdin)
+		if err != nil {
+			return "", exception.Wrap(err)
+		}
+		return string(contents), nil
+	}
+	var text []string
+	for _, path := range paths {
+		contents, err := ioutil.ReadFile(path)
+		if err != nil {
+			return "", exception.Wrap(err)
+		}
+		text = append(text, string(contents))
+	}
+	return strings.Join(text, ""), nil
+}
+
+// split splits text into chunks of at most max bytes, at line breaks where it can.
+func split(text string, max int) []string {
+	var chunks []string
+	for len(text) > max {
+		cut := strings.LastIndex(text[:max+1], "\n")
+		if cut <= 0 {
+			cut = runeBoundary(text, max)
+			chunks = append(chunks, text[:cut])
+			text = text[cut:]
+			continue
+		}
+		chunks = append(chunks, text[:cut])
+		text = text[cut+1:]
+	}
+	if len(text) > 0 {
+		chunks = append(chunks, text)
+	}
+	return chunks
+}
+
+// runeBoundary returns the largest index at most max that doesn't split a multibyte character.
+func runeBoundary(text string, max int) int {
+	cut := max
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return cut
+}
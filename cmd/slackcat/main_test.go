@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	assert "github.com/blendlabs/go-assert"
+	"github.com/wcharczuk/go-slack/slacktest"
+)
+
+func newTestSlackcat(server *slacktest.Server) (*slackcat, *bytes.Buffer) {
+	out := bytes.NewBuffer(nil)
+	return &slackcat{client: server.Client(), channelID: slacktest.DefaultChannelID, interval: 10 * time.Millisecond, out: out}, out
+}
+
+func TestPost(t *testing.T) {
+	a := assert.New(t)
+	server := slacktest.New()
+	defer server.Close()
+
+	sc, out := newTestSlackcat(server)
+	sc.code = true
+	a.Nil(sc.post("make: *** [all] Error 1\n"))
+	history := server.Workspace.History(slacktest.DefaultChannelID)
+	a.Len(history, 1)
+	a.Equal("```\nmake: *** [all] Error 1\n```", history[0].Text)
+	a.Equal(history[0].Timestamp.String()+"\n", out.String())
+
+	sc.code = false
+	sc.thread = history[0].Timestamp
+	a.Nil(sc.post(strings.Repeat("a", maxMessageLength) + "\n" + "b"))
+	history = server.Workspace.History(slacktest.DefaultChannelID)
+	a.Len(history, 3)
+	a.Equal("b", history[2].Text)
+	a.Equal(sc.thread.String(), history[2].ThreadTimestamp.String())
+
+	// piped text is escaped, so it can't mention or link.
+	sc.thread = nil
+	a.Nil(sc.post("<!channel> if a < b && b > c\n"))
+	history = server.Workspace.History(slacktest.DefaultChannelID)
+	a.Equal("&lt;!channel&gt; if a &lt; b &amp;&amp; b &gt; c", history[len(history)-1].Text)
+}
+
+func TestRunInvalidThread(t *testing.T) {
+	a := assert.New(t)
+	err := run([]string{"-token", "xoxb-1234", "-channel", slacktest.DefaultChannelID, "-thread", "yesterday"}, strings.NewReader("hello"), ioutil.Discard)
+	a.NotNil(err)
+	a.Contains("-thread", err.Error())
+}
+
+func TestStream(t *testing.T) {
+	a := assert.New(t)
+	server := slacktest.New()
+	defer server.Close()
+
+	sc, _ := newTestSlackcat(server)
+	r, w := io.Pipe()
+	done := make(chan error, 1)
+	go func() { done <- sc.stream(r) }()
+
+	io.WriteString(w, "starting\n")
+	a.Nil(waitFor(func() bool { return len(server.Workspace.History(slacktest.DefaultChannelID)) == 1 }))
+	io.WriteString(w, "listening on <:8080>\n")
+	a.Nil(waitFor(func() bool {
+		history := server.Workspace.History(slacktest.DefaultChannelID)
+		return len(history) == 1 && history[0].Text == "starting\nlistening on &lt;:8080&gt;"
+	}))
+
+	// a full message is left as is and the stream continues in a new one.
+	io.WriteString(w, strings.Repeat("x", maxMessageLength)+"\n")
+	w.Close()
+	a.Nil(<-done)
+	history := server.Workspace.History(slacktest.DefaultChannelID)
+	a.Len(history, 2)
+	a.Equal("starting\nlistening on &lt;:8080&gt;", history[0].Text)
+	a.Len(history[1].Text, maxMessageLength)
+}
+
+func TestUpload(t *testing.T) {
+	a := assert.New(t)
+	server := slacktest.New()
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "slackcat")
+	a.Nil(err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "report.csv")
+	a.Nil(ioutil.WriteFile(path, []byte("a,b\n1,2\n"), 0644))
+
+	sc, out := newTestSlackcat(server)
+	a.Nil(sc.uploadFile(path, "", "weekly report"))
+	history := server.Workspace.History(slacktest.DefaultChannelID)
+	a.Len(history, 1)
+	a.Equal("weekly report", history[0].Text)
+	a.Len(history[0].Files, 1)
+	a.Equal("report.csv", history[0].Files[0].Name)
+	a.Equal("a,b\n1,2\n", server.Workspace.Files[history[0].Files[0].ID].Preview)
+	a.Equal(history[0].Files[0].ID+"\n", out.String())
+}
+
+func TestSplit(t *testing.T) {
+	a := assert.New(t)
+	a.Equal([]string{"ab", "cd"}, split("ab\ncd", 3))
+	a.Equal([]string{"abc", "d"}, split("abcd", 3))
+	a.Equal([]string{"é", "é"}, split("éé", 3))
+	a.Empty(split("", 3))
+}
+
+func waitFor(condition func() bool) error {
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			return io.ErrNoProgress
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return nil
+}
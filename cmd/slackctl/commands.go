@@ -0,0 +1,353 @@
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+
+	exception "github.com/blendlabs/go-exception"
+	slack "github.com/wcharczuk/go-slack"
+	"github.com/wcharczuk/go-slack/cmd/internal/cli"
+)
+
+// pageSize is the page size of paged list calls.
+const pageSize = 100
+
+// command is a subcommand, i.e. `users list`.
+type command struct {
+	name  string
+	usage string
+	run   func(client *slack.Client, args []string) (*result, error)
+}
+
+var commands = []command{
+	{"auth test", "", authTest},
+	{"channels info", "<channel>", channelsInfo},
+	{"channels list", "[-archived]", channelsList},
+	{"chat post", "[-thread ts] <channel> <text>", chatPost},
+	{"emoji list", "", emojiList},
+	{"reactions add", "<channel> <ts> <name>", reactionsAdd},
+	{"reactions list", "[-user id] [-limit n]", reactionsList},
+	{"search messages", "[-limit n] <query>", searchMessages},
+	{"users info", "<user>", usersInfo},
+	{"users list", "[-deleted]", usersList},
+}
+
+// findCommand returns the command named by the first two arguments, and the rest of the arguments.
+func findCommand(args []string) (*command, []string, error) {
+	if len(args) < 2 {
+		return nil, nil, exception.New("no command.")
+	}
+	name := args[0] + " " + args[1]
+	for index := range commands {
+		if commands[index].name == name {
+			return &commands[index], args[2:], nil
+		}
+	}
+	return nil, nil, exception.New("unknown command `" + name + "`.")
+}
+
+// parseArgs parses a command's flags and checks it has the number of arguments it needs (at least, if atLeast).
+func parseArgs(name, usage string, flags *flag.FlagSet, args []string, count int, atLeast bool) ([]string, error) {
+	flags.SetOutput(ioutil.Discard)
+	if err := flags.Parse(args); err != nil {
+		return nil, exception.Wrap(err)
+	}
+	if flags.NArg() < count || (!atLeast && flags.NArg() > count) {
+		return nil, exception.New("usage: slackctl " + name + " " + usage)
+	}
+	return flags.Args(), nil
+}
+
+func authTest(client *slack.Client, args []string) (*result, error) {
+	if _, err := parseArgs("auth test", "", flag.NewFlagSet("auth test", flag.ContinueOnError), args, 0, false); err != nil {
+		return nil, err
+	}
+	res, err := client.AuthTest()
+	if err != nil {
+		return nil, err
+	}
+	return &result{
+		columns: []string{"team", "team_id", "user", "user_id", "url"},
+		rows:    [][]string{{res.Team, res.TeamID, res.User, res.UserID, res.URL}},
+		value:   res,
+	}, nil
+}
+
+func channelsInfo(client *slack.Client, args []string) (*result, error) {
+	args, err := parseArgs("channels info", "<channel>", flag.NewFlagSet("channels info", flag.ContinueOnError), args, 1, false)
+	if err != nil {
+		return nil, err
+	}
+	channelID, err := cli.ResolveChannel(client, args[0])
+	if err != nil {
+		return nil, err
+	}
+	channel, err := client.ChannelsInfo(channelID)
+	if err != nil {
+		return nil, err
+	}
+	return channelsResult([]slack.Channel{*channel}, channel), nil
+}
+
+func channelsList(client *slack.Client, args []string) (*result, error) {
+	flags := flag.NewFlagSet("channels list", flag.ContinueOnError)
+	archived := flags.Bool("archived", false, "include archived channels")
+	if _, err := parseArgs("channels list", "[-archived]", flags, args, 0, false); err != nil {
+		return nil, err
+	}
+	channels, err := cli.Channels(client, !*archived)
+	if err != nil {
+		return nil, err
+	}
+	return channelsResult(channels, channels), nil
+}
+
+func channelsResult(channels []slack.Channel, value interface{}) *result {
+	res := &result{columns: []string{"id", "name", "members", "is_member", "is_archived", "topic"}, value: value}
+	for _, channel := range channels {
+		var topic string
+		if channel.Topic != nil {
+			topic = channel.Topic.Value
+		}
+		res.rows = append(res.rows, []string{
+			channel.ID,
+			channel.Name,
+			strconv.Itoa(len(channel.Members)),
+			strconv.FormatBool(channel.IsMember),
+			strconv.FormatBool(channel.IsArchived),
+			topic,
+		})
+	}
+	return res
+}
+
+func chatPost(client *slack.Client, args []string) (*result, error) {
+	flags := flag.NewFlagSet("chat post", flag.ContinueOnError)
+	thread := flags.String("thread", "", "the `ts` of a message to reply to in its thread")
+	args, err := parseArgs("chat post", "[-thread ts] <channel> <text>", flags, args, 2, true)
+	if err != nil {
+		return nil, err
+	}
+	var threadTimestamp *slack.Timestamp
+	if len(*thread) > 0 {
+		if threadTimestamp, err = cli.ParseTimestamp(*thread); err != nil {
+			return nil, exception.New("-thread: " + err.Error())
+		}
+	}
+	channelID, err := cli.ResolveChannel(client, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	m := &slack.ChatMessage{Channel: channelID, Text: strings.Join(args[1:], " "), ThreadTimestamp: threadTimestamp}
+	res, err := client.ChatPostMessage(m)
+	if err != nil {
+		return nil, err
+	}
+	return &result{
+		columns: []string{"channel", "ts"},
+		rows:    [][]string{{res.Channel, res.Timestamp.String()}},
+		value:   res,
+	}, nil
+}
+
+func emojiList(client *slack.Client, args []string) (*result, error) {
+	if _, err := parseArgs("emoji list", "", flag.NewFlagSet("emoji list", flag.ContinueOnError), args, 0, false); err != nil {
+		return nil, err
+	}
+	emoji, err := client.EmojiList()
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for name := range emoji {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	res := &result{columns: []string{"name", "url"}, value: emoji}
+	for _, name := range names {
+		res.rows = append(res.rows, []string{name, emoji[name]})
+	}
+	return res, nil
+}
+
+func reactionsAdd(client *slack.Client, args []string) (*result, error) {
+	args, err := parseArgs("reactions add", "<channel> <ts> <name>", flag.NewFlagSet("reactions add", flag.ContinueOnError), args, 3, false)
+	if err != nil {
+		return nil, err
+	}
+	ts, err := cli.ParseTimestamp(args[1])
+	if err != nil {
+		return nil, err
+	}
+	channelID, err := cli.ResolveChannel(client, args[0])
+	if err != nil {
+		return nil, err
+	}
+	if err := client.ReactionsAdd(strings.Trim(args[2], ":"), slack.MessageRef(channelID, *ts)); err != nil {
+		return nil, err
+	}
+	return &result{value: map[string]bool{"ok": true}}, nil
+}
+
+// reactionsList follows `next_cursor` until the last page, or until `-limit` items.
+func reactionsList(client *slack.Client, args []string) (*result, error) {
+	flags := flag.NewFlagSet("reactions list", flag.ContinueOnError)
+	userID := flags.String("user", "", "the user id to list reactions of (default the calling user)")
+	limit := flags.Int("limit", 0, "the most items to list (default all)")
+	if _, err := parseArgs("reactions list", "[-user id] [-limit n]", flags, args, 0, false); err != nil {
+		return nil, err
+	}
+
+	items := []slack.Item{}
+	var cursor string
+	for {
+		count := pageSize
+		if *limit > 0 && *limit-len(items) < count {
+			count = *limit - len(items)
+		}
+		page, err := client.ReactionsList(*userID, false, count, cursor)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, page.Items...)
+		cursor = page.ResponseMetadata.NextCursor
+		if len(cursor) == 0 || len(page.Items) == 0 || (*limit > 0 && len(items) >= *limit) {
+			break
+		}
+	}
+	if *limit > 0 && len(items) > *limit {
+		items = items[:*limit]
+	}
+
+	res := &result{columns: []string{"type", "channel", "ts", "file", "reactions"}, value: items}
+	for _, item := range items {
+		var ts, fileID string
+		var reactions []slack.Reaction
+		if item.Message != nil {
+			reactions = item.Message.Reactions
+			if item.Message.Timestamp != nil {
+				ts = item.Message.Timestamp.String()
+			}
+		}
+		if item.File != nil {
+			fileID = item.File.ID
+			reactions = item.File.Reactions
+		}
+		if item.Comment != nil {
+			reactions = item.Comment.Reactions
+		}
+		var counts []string
+		for _, reaction := range reactions {
+			counts = append(counts, reaction.Name+":"+strconv.Itoa(reaction.Count))
+		}
+		res.rows = append(res.rows, []string{item.Type, item.Channel, ts, fileID, strings.Join(counts, " ")})
+	}
+	return res, nil
+}
+
+// searchMessages fetches pages until the last page, or until `-limit` matches.
+func searchMessages(client *slack.Client, args []string) (*result, error) {
+	flags := flag.NewFlagSet("search messages", flag.ContinueOnError)
+	limit := flags.Int("limit", 0, "the most matches to list (default all)")
+	args, err := parseArgs("search messages", "[-limit n] <query>", flags, args, 1, true)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := []slack.SearchMessage{}
+	err = client.SearchMessagesEach(strings.Join(args, " "), &slack.SearchParameters{Count: pageSize}, func(match slack.SearchMessage) bool {
+		matches = append(matches, match)
+		return *limit <= 0 || len(matches) < *limit
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := &result{columns: []string{"channel", "user", "ts", "text"}, value: matches}
+	for _, match := range matches {
+		var ts string
+		if match.Timestamp != nil {
+			ts = match.Timestamp.String()
+		}
+		res.rows = append(res.rows, []string{match.Channel.Name, match.Username, ts, match.Text})
+	}
+	return res, nil
+}
+
+func usersInfo(client *slack.Client, args []string) (*result, error) {
+	args, err := parseArgs("users info", "<user>", flag.NewFlagSet("users info", flag.ContinueOnError), args, 1, false)
+	if err != nil {
+		return nil, err
+	}
+
+	userID := args[0]
+	if strings.HasPrefix(userID, "@") {
+		users, err := client.UsersList()
+		if err != nil {
+			return nil, err
+		}
+		userID = ""
+		for _, user := range users {
+			if user.Name == args[0][1:] {
+				userID = user.ID
+				break
+			}
+		}
+		if len(userID) == 0 {
+			return nil, exception.New("user `" + args[0] + "` not found.")
+		}
+	}
+	user, err := client.UsersInfo(userID)
+	if err != nil {
+		return nil, err
+	}
+	return usersResult([]slack.User{*user}, user), nil
+}
+
+func usersList(client *slack.Client, args []string) (*result, error) {
+	flags := flag.NewFlagSet("users list", flag.ContinueOnError)
+	deleted := flags.Bool("deleted", false, "include deactivated users")
+	if _, err := parseArgs("users list", "[-deleted]", flags, args, 0, false); err != nil {
+		return nil, err
+	}
+	users, err := cli.Users(client)
+	if err != nil {
+		return nil, err
+	}
+
+	listed := []slack.User{}
+	for _, user := range users {
+		if *deleted || !user.Deleted {
+			listed = append(listed, user)
+		}
+	}
+	return usersResult(listed, listed), nil
+}
+
+func usersResult(users []slack.User, value interface{}) *result {
+	res := &result{columns: []string{"id", "name", "real_name", "email", "is_bot", "deleted"}, value: value}
+	for _, user := range users {
+		var email string
+		realName := user.RealName
+		if user.Profile != nil {
+			email = user.Profile.Email
+			if len(realName) == 0 {
+				realName = user.Profile.RealName
+			}
+		}
+		res.rows = append(res.rows, []string{
+			user.ID,
+			user.Name,
+			realName,
+			email,
+			strconv.FormatBool(user.IsBot),
+			strconv.FormatBool(user.Deleted),
+		})
+	}
+	return res
+}
@@ -0,0 +1,175 @@
+// Command slackctl calls the web api from the command line:
+//
+//	slackctl auth test
+//	slackctl users list
+//	slackctl -output csv channels list -archived
+//	slackctl chat post general "deploy finished"
+//	slackctl reactions add general 1546300800.000100 shipit
+//	slackctl -all-workspaces emoji list
+//
+// Results are printed as a table (the default), json or csv. List calls that page are followed to the end,
+// or to their `-limit`.
+//
+// The token is read from `-token`, then `$SLACK_TOKEN`, then the config file (`~/.slackctl` by default),
+// which stores a token per workspace as `name = token` lines:
+//
+//	acme = xoxb-...
+//	acme-staging = xoxb-...
+//
+// `-workspace` picks a stored token (the only one is used by default), and `-all-workspaces` runs the command
+// against every stored token, adding a `workspace` column.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	exception "github.com/blendlabs/go-exception"
+	slack "github.com/wcharczuk/go-slack"
+	"github.com/wcharczuk/go-slack/cmd/internal/cli"
+)
+
+const (
+	// tokenEnvironmentVariable is read when `-token` isn't set.
+	tokenEnvironmentVariable = "SLACK_TOKEN"
+	// defaultConfigFile is the config file in the user's home directory.
+	defaultConfigFile = ".slackctl"
+)
+
+func main() {
+	c := &ctl{newClient: slack.NewClient, getenv: os.Getenv, stdout: os.Stdout, stderr: os.Stderr}
+	if err := c.run(os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "slackctl: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// ctl runs a command line.
+type ctl struct {
+	newClient func(token string) *slack.Client
+	getenv    func(key string) string
+	stdout    io.Writer
+	stderr    io.Writer
+}
+
+// workspace is a named token.
+type workspace struct {
+	name  string
+	token string
+}
+
+func (c *ctl) run(args []string) error {
+	flags := flag.NewFlagSet("slackctl", flag.ContinueOnError)
+	flags.SetOutput(c.stderr)
+	token := flags.String("token", "", "the api token (default $"+tokenEnvironmentVariable+" or a stored token)")
+	configPath := flags.String("config", "", "the file of stored tokens (default ~/"+defaultConfigFile+")")
+	workspaceName := flags.String("workspace", "", "the stored token to use")
+	allWorkspaces := flags.Bool("all-workspaces", false, "run the command against every stored token")
+	output := flags.String("output", outputTable, "the output format: table, json or csv")
+	flags.Usage = func() {
+		fmt.Fprintln(c.stderr, "usage: slackctl [flags] <command> [command flags] [arguments]")
+		flags.PrintDefaults()
+		fmt.Fprintln(c.stderr, "\ncommands:")
+		for _, cmd := range commands {
+			fmt.Fprintf(c.stderr, "  %s %s\n", cmd.name, cmd.usage)
+		}
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if !isOutput(*output) {
+		return exception.New("unknown output `" + *output + "`; use table, json or csv.")
+	}
+
+	cmd, commandArgs, err := findCommand(flags.Args())
+	if err != nil {
+		flags.Usage()
+		return err
+	}
+
+	workspaces, err := c.workspaces(*token, *configPath, *workspaceName, *allWorkspaces)
+	if err != nil {
+		return err
+	}
+
+	var results []*result
+	var failed int
+	for _, ws := range workspaces {
+		client := c.newClient(ws.token)
+		client.SetDebug(false)
+		res, err := cmd.run(client, commandArgs)
+		if err != nil {
+			if !*allWorkspaces {
+				return err
+			}
+			fmt.Fprintf(c.stderr, "slackctl: %s: %v\n", ws.name, err)
+			failed++
+			continue
+		}
+		if res != nil {
+			res.workspace = ws.name
+			results = append(results, res)
+		}
+	}
+
+	if err := writeResults(c.stdout, *output, results, *allWorkspaces); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return exception.New(fmt.Sprintf("%d of %d workspaces failed.", failed, len(workspaces)))
+	}
+	return nil
+}
+
+// workspaces returns the tokens to run a command with.
+func (c *ctl) workspaces(token, configPath, name string, all bool) ([]workspace, error) {
+	if len(token) > 0 && !all {
+		return []workspace{{token: token}}, nil
+	}
+	if envToken := c.getenv(tokenEnvironmentVariable); len(envToken) > 0 && len(name) == 0 && !all {
+		return []workspace{{token: envToken}}, nil
+	}
+
+	stored, err := loadWorkspaces(configPath)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case all:
+		if len(stored) == 0 {
+			return nil, exception.New("no stored tokens for -all-workspaces.")
+		}
+		return stored, nil
+	case len(name) > 0:
+		for _, ws := range stored {
+			if ws.name == name {
+				return []workspace{ws}, nil
+			}
+		}
+		return nil, exception.New("no stored token for workspace `" + name + "`.")
+	case len(stored) == 1:
+		return stored, nil
+	case len(stored) > 1:
+		return nil, exception.New("several stored tokens; pick one with -workspace or use -all-workspaces.")
+	}
+	return nil, exception.New("no token; set -token, $" + tokenEnvironmentVariable + " or store one in the config file.")
+}
+
+// loadWorkspaces reads the stored tokens, sorted by name; a missing default config file has none.
+func loadWorkspaces(path string) ([]workspace, error) {
+	config, err := cli.LoadConfig(path, defaultConfigFile)
+	if err != nil {
+		return nil, err
+	}
+	var workspaces []workspace
+	for name, token := range config {
+		workspaces = append(workspaces, workspace{name: name, token: token})
+	}
+	sort.Slice(workspaces, func(i, j int) bool {
+		return workspaces[i].name < workspaces[j].name
+	})
+	return workspaces, nil
+}
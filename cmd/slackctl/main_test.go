@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	assert "github.com/blendlabs/go-assert"
+	slack "github.com/wcharczuk/go-slack"
+	"github.com/wcharczuk/go-slack/slacktest"
+)
+
+// newTestCtl returns a ctl whose clients call the server with the client's token.
+func newTestCtl(servers ...*slacktest.Server) (*ctl, *bytes.Buffer, *bytes.Buffer) {
+	stdout, stderr := bytes.NewBuffer(nil), bytes.NewBuffer(nil)
+	return &ctl{
+		newClient: func(token string) *slack.Client {
+			client := slack.NewClient(token)
+			for _, server := range servers {
+				if server.Token == token {
+					client.SetAPIEndpoint("http", server.Host())
+				}
+			}
+			return client
+		},
+		getenv: func(string) string { return "" },
+		stdout: stdout,
+		stderr: stderr,
+	}, stdout, stderr
+}
+
+func TestCtlOutput(t *testing.T) {
+	a := assert.New(t)
+	server := slacktest.New()
+	defer server.Close()
+
+	c, stdout, _ := newTestCtl(server)
+	a.Nil(c.run([]string{"-token", server.Token, "users", "list"}))
+	a.Equal(strings.Join([]string{
+		"ID      NAME  REAL_NAME  EMAIL             IS_BOT  DELETED",
+		"U0BOT   bot   Bot                          true    false",
+		"U0USER  user  Test User  user@example.com  false   false",
+	}, "\n")+"\n", stdout.String())
+
+	stdout.Reset()
+	a.Nil(c.run([]string{"-token", server.Token, "-output", "csv", "channels", "info", "#general"}))
+	a.Equal("id,name,members,is_member,is_archived,topic\nC0GENERAL,general,2,true,false,\n", stdout.String())
+
+	stdout.Reset()
+	a.Nil(c.run([]string{"-token", server.Token, "-output", "json", "auth", "test"}))
+	var auth slack.AuthTestResponse
+	a.Nil(json.Unmarshal(stdout.Bytes(), &auth))
+	a.Equal(slacktest.DefaultBotID, auth.UserID)
+
+	a.NotNil(c.run([]string{"-token", server.Token, "-output", "xml", "auth", "test"}))
+	a.NotNil(c.run([]string{"-token", server.Token, "users", "delete"}))
+	a.NotNil(c.run([]string{"-token", server.Token, "chat", "post", "general"}))
+}
+
+func TestCtlWriteCommands(t *testing.T) {
+	a := assert.New(t)
+	server := slacktest.New()
+	defer server.Close()
+
+	c, stdout, _ := newTestCtl(server)
+	a.Nil(c.run([]string{"-token", server.Token, "chat", "post", "general", "deploy", "finished"}))
+	history := server.Workspace.History(slacktest.DefaultChannelID)
+	a.Len(history, 1)
+	a.Equal("deploy finished", history[0].Text)
+	ts := history[0].Timestamp.String()
+	a.True(strings.Contains(stdout.String(), ts))
+
+	stdout.Reset()
+	a.Nil(c.run([]string{"-token", server.Token, "reactions", "add", slacktest.DefaultChannelID, ts, ":shipit:"}))
+	a.Empty(stdout.String())
+	a.Nil(c.run([]string{"-token", server.Token, "-output", "csv", "reactions", "list"}))
+	a.Equal("type,channel,ts,file,reactions\nmessage,"+slacktest.DefaultChannelID+","+ts+",,shipit:1\n", stdout.String())
+
+	// invalid timestamps are errors, rather than the zero timestamp.
+	a.NotNil(c.run([]string{"-token", server.Token, "reactions", "add", slacktest.DefaultChannelID, "yesterday", ":shipit:"}))
+	a.NotNil(c.run([]string{"-token", server.Token, "chat", "post", "-thread", "yesterday", "general", "reply"}))
+	a.Len(server.Workspace.History(slacktest.DefaultChannelID), 1)
+
+	stdout.Reset()
+	a.Nil(c.run([]string{"-token", server.Token, "-output", "csv", "search", "messages", "-limit", "1", "deploy"}))
+	a.Equal("channel,user,ts,text\ngeneral,bot,"+ts+",deploy finished\n", stdout.String())
+}
+
+func TestCtlWorkspaces(t *testing.T) {
+	a := assert.New(t)
+	acme := slacktest.New()
+	defer acme.Close()
+	other := slacktest.New()
+	defer other.Close()
+	other.Token = "xoxb-other"
+	other.Workspace.Emoji = map[string]string{"party": "https://emoji.example.com/party.gif"}
+
+	dir, err := ioutil.TempDir("", "slackctl")
+	a.Nil(err)
+	defer os.RemoveAll(dir)
+	config := filepath.Join(dir, "tokens")
+	a.Nil(ioutil.WriteFile(config, []byte("# stored tokens\nother = xoxb-other\nacme = "+acme.Token+"\n"), 0600))
+
+	c, stdout, stderr := newTestCtl(acme, other)
+	a.Nil(c.run([]string{"-config", config, "-all-workspaces", "-output", "csv", "emoji", "list"}))
+	a.Equal("workspace,name,url\nacme,shipit,https://emoji.example.com/shipit.png\nother,party,https://emoji.example.com/party.gif\n", stdout.String())
+
+	stdout.Reset()
+	a.Nil(c.run([]string{"-config", config, "-workspace", "other", "-output", "json", "emoji", "list"}))
+	a.True(strings.Contains(stdout.String(), "party"))
+	a.NotNil(c.run([]string{"-config", config, "emoji", "list"}))
+	a.NotNil(c.run([]string{"-config", config, "-workspace", "missing", "emoji", "list"}))
+
+	// a failing workspace is reported, and the others still print.
+	other.SetError("emoji.list", slack.ErrorInvalidAuth)
+	stdout.Reset()
+	a.NotNil(c.run([]string{"-config", config, "-all-workspaces", "emoji", "list"}))
+	a.True(strings.Contains(stdout.String(), "shipit"))
+	a.True(strings.Contains(stderr.String(), "other: invalid_auth"))
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	exception "github.com/blendlabs/go-exception"
+)
+
+// Output formats.
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputCSV   = "csv"
+)
+
+func isOutput(output string) bool {
+	return output == outputTable || output == outputJSON || output == outputCSV
+}
+
+// result is a command's result; rows are printed as a table or csv, and value as json.
+type result struct {
+	workspace string
+	columns   []string
+	rows      [][]string
+	value     interface{}
+}
+
+// writeResults writes the results of every workspace, adding a `workspace` column
+// (or, for json, an object keyed by workspace) when there are several.
+func writeResults(w io.Writer, output string, results []*result, byWorkspace bool) error {
+	if output == outputJSON {
+		var value interface{}
+		if byWorkspace {
+			values := map[string]interface{}{}
+			for _, res := range results {
+				values[res.workspace] = res.value
+			}
+			value = values
+		} else if len(results) > 0 {
+			value = results[0].value
+		}
+		contents, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return exception.Wrap(err)
+		}
+		_, err = fmt.Fprintln(w, string(contents))
+		if err != nil {
+			return exception.Wrap(err)
+		}
+		return nil
+	}
+
+	var columns []string
+	var rows [][]string
+	for _, res := range results {
+		if len(res.columns) == 0 {
+			continue
+		}
+		columns = res.columns
+		for _, row := range res.rows {
+			if byWorkspace {
+				row = append([]string{res.workspace}, row...)
+			}
+			rows = append(rows, row)
+		}
+	}
+	if len(columns) == 0 {
+		return nil
+	}
+	if byWorkspace {
+		columns = append([]string{"workspace"}, columns...)
+	}
+
+	if output == outputCSV {
+		writer := csv.NewWriter(w)
+		writer.Write(columns)
+		writer.WriteAll(rows)
+		if err := writer.Error(); err != nil {
+			return exception.Wrap(err)
+		}
+		return nil
+	}
+
+	writer := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, strings.ToUpper(strings.Join(columns, "\t")))
+	for _, row := range rows {
+		cells := make([]string, len(row))
+		for index, cell := range row {
+			cells[index] = strings.NewReplacer("\n", " ", "\t", " ").Replace(cell)
+		}
+		fmt.Fprintln(writer, strings.Join(cells, "\t"))
+	}
+	if err := writer.Flush(); err != nil {
+		return exception.Wrap(err)
+	}
+	return nil
+}
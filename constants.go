@@ -52,6 +52,81 @@ const (
 	ErrorTooManyEmoji = "too_many_emoji"
 	// ErrorTooManyReactions : 	The limit for reactions a person may add to the item has been reached.
 	ErrorTooManyReactions = "too_many_reactions"
+	// ErrorRateLimited : The request has been rate limited by Slack.
+	ErrorRateLimited = "ratelimited"
+	// ErrorInvalidPayload : The data sent to an incoming webhook was either missing or syntactically invalid.
+	ErrorInvalidPayload = "invalid_payload"
+	// ErrorChannelIsArchived : The channel an incoming webhook posts to has been archived.
+	ErrorChannelIsArchived = "channel_is_archived"
+	// ErrorNoText : The message sent to an incoming webhook had no text (or attachments / blocks).
+	ErrorNoText = "no_text"
+	// ErrorUsersNotFound : The user (or email address) was not found.
+	ErrorUsersNotFound = "users_not_found"
+	// ErrorUserNotFound : The user was not found.
+	ErrorUserNotFound = "user_not_found"
+	// ErrorNotFound : The reminder (or other object) was not found.
+	ErrorNotFound = "not_found"
+	// ErrorCannotCompleteRecurring : Recurring reminders can't be marked complete.
+	ErrorCannotCompleteRecurring = "cannot_complete_recurring"
+	// ErrorAlreadyInChannel : The invited user is already a member of the channel.
+	ErrorAlreadyInChannel = "already_in_channel"
+	// ErrorCantInviteSelf : The calling user can't invite themselves to a channel.
+	ErrorCantInviteSelf = "cant_invite_self"
+	// ErrorNameTaken : A channel with the name already exists.
+	ErrorNameTaken = "name_taken"
+	// ErrorAlreadyArchived : The channel is already archived.
+	ErrorAlreadyArchived = "already_archived"
+	// ErrorNotArchived : The channel isn't archived.
+	ErrorNotArchived = "not_archived"
+	// ErrorNoFileData : An upload had no file contents.
+	ErrorNoFileData = "no_file_data"
+	// ErrorInvalidCursor : The value passed for `cursor` was not valid.
+	ErrorInvalidCursor = "invalid_cursor"
+
+	// ItemTypeMessage is the type of a message Item.
+	ItemTypeMessage = "message"
+	// ItemTypeFile is the type of a file Item.
+	ItemTypeFile = "file"
+	// ItemTypeFileComment is the type of a file comment Item.
+	ItemTypeFileComment = "file_comment"
+	// ItemTypeChannel is the type of a (starred) channel Item.
+	ItemTypeChannel = "channel"
+	// ItemTypeIM is the type of a (starred) direct message Item.
+	ItemTypeIM = "im"
+	// ItemTypeGroup is the type of a (starred) private channel Item.
+	ItemTypeGroup = "group"
+
+	// PresenceAuto lets slack set a user's presence from their activity (users.setPresence).
+	PresenceAuto = "auto"
+	// PresenceAway marks a user as away (users.setPresence).
+	PresenceAway = "away"
+	// PresenceActive is the presence of an active user (users.getPresence).
+	PresenceActive = "active"
+
+	// SearchSortScore sorts search results by relevance.
+	SearchSortScore = "score"
+	// SearchSortTimestamp sorts search results by time.
+	SearchSortTimestamp = "timestamp"
+	// SearchSortAsc sorts search results in ascending order.
+	SearchSortAsc = "asc"
+	// SearchSortDesc sorts search results in descending order.
+	SearchSortDesc = "desc"
+
+	// SearchHasLink finds messages with links (SearchQuery.Has).
+	SearchHasLink = "link"
+	// SearchHasStar finds messages the calling user starred (SearchQuery.Has).
+	SearchHasStar = "star"
+	// SearchHasPin finds pinned messages (SearchQuery.Has).
+	SearchHasPin = "pin"
+
+	// RecurrenceDaily repeats a reminder every day.
+	RecurrenceDaily = "daily"
+	// RecurrenceWeekly repeats a reminder on the `Weekdays` of a ReminderRecurrence.
+	RecurrenceWeekly = "weekly"
+	// RecurrenceMonthly repeats a reminder every month.
+	RecurrenceMonthly = "monthly"
+	// RecurrenceYearly repeats a reminder every year.
+	RecurrenceYearly = "yearly"
 
 	// EventHello is an enumerated event.
 	EventHello Event = "hello"
@@ -67,6 +142,8 @@ const (
 	EventUserTyping Event = "user_typing"
 	// EventChannelMarked is an enumerated event.
 	EventChannelMarked Event = "channel_marked"
+	// EventChannelCreated is an enumerated event.
+	EventChannelCreated Event = "channel_created"
 	// EventChannelJoined is an enumerated event.
 	EventChannelJoined Event = "channel_joined"
 	// EventChannelLeft is an enumerated event.
@@ -171,6 +248,16 @@ const (
 	EventTeamProfileDelete Event = "team_profile_delete"
 	// EventTeamProfileReorder is an enumerated event.
 	EventTeamProfileReorder Event = "team_profile_reorder"
+	// EventSubteamCreated is an enumerated event.
+	EventSubteamCreated Event = "subteam_created"
+	// EventSubteamUpdated is an enumerated event.
+	EventSubteamUpdated Event = "subteam_updated"
+	// EventSubteamMembersChanged is an enumerated event.
+	EventSubteamMembersChanged Event = "subteam_members_changed"
+	// EventSubteamSelfAdded is an enumerated event.
+	EventSubteamSelfAdded Event = "subteam_self_added"
+	// EventSubteamSelfRemoved is an enumerated event.
+	EventSubteamSelfRemoved Event = "subteam_self_removed"
 	// EventBotAdded is an enumerated event.
 	EventBotAdded Event = "bot_added"
 	// EventBotChanged is an enumerated event.
@@ -202,4 +289,6 @@ const (
 	EventSubtypeChannelArchive Event = "channel_archive"
 	// EventSubtypeChannelUnarchive is an enumerated sub event.
 	EventSubtypeChannelUnarchive Event = "channel_unarchive"
+	// EventSubtypeFileShare is an enumerated sub event.
+	EventSubtypeFileShare Event = "file_share"
 )
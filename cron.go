@@ -0,0 +1,185 @@
+package slack
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	exception "github.com/blendlabs/go-exception"
+)
+
+// Schedule determines when a scheduled post runs next.
+type Schedule interface {
+	// Next returns the next time the schedule fires after a given time.
+	// A zero time means the schedule won't fire again.
+	Next(after time.Time) time.Time
+}
+
+// Every returns a schedule that fires on a fixed interval.
+func Every(interval time.Duration) Schedule {
+	return intervalSchedule(interval)
+}
+
+type intervalSchedule time.Duration
+
+func (is intervalSchedule) Next(after time.Time) time.Time {
+	if is <= 0 {
+		return time.Time{}
+	}
+	return after.Add(time.Duration(is))
+}
+
+// ParseCron parses a standard five field cron expression (`minute hour day-of-month month day-of-week`)
+// in the local timezone, i.e. `30 9 * * 1-5` for 9:30am on weekdays.
+// Fields support `*`, lists (`1,15`), ranges (`1-5`) and steps (`*/15`).
+// The descriptors `@hourly`, `@daily`, `@weekly`, `@monthly` and `@weekdays` are also supported.
+func ParseCron(expr string) (*CronSchedule, error) {
+	return ParseCronInLocation(expr, time.Local)
+}
+
+// ParseCronInLocation parses a cron expression that is evaluated in a given timezone.
+func ParseCronInLocation(expr string, location *time.Location) (*CronSchedule, error) {
+	expr = strings.TrimSpace(expr)
+	switch expr {
+	case "@hourly":
+		expr = "0 * * * *"
+	case "@daily", "@midnight":
+		expr = "0 0 * * *"
+	case "@weekly":
+		expr = "0 0 * * 0"
+	case "@monthly":
+		expr = "0 0 1 * *"
+	case "@weekdays":
+		expr = "0 0 * * 1-5"
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, exception.Newf("invalid cron expression `%s`; expected 5 fields.", expr)
+	}
+
+	cs := &CronSchedule{Expression: expr, Location: location}
+	var err error
+	if cs.minutes, err = parseCronField(fields[0], 0, 59); err != nil {
+		return nil, err
+	}
+	if cs.hours, err = parseCronField(fields[1], 0, 23); err != nil {
+		return nil, err
+	}
+	if cs.daysOfMonth, err = parseCronField(fields[2], 1, 31); err != nil {
+		return nil, err
+	}
+	if cs.months, err = parseCronField(fields[3], 1, 12); err != nil {
+		return nil, err
+	}
+	if cs.daysOfWeek, err = parseCronField(fields[4], 0, 7); err != nil {
+		return nil, err
+	}
+	// sunday is both 0 and 7.
+	if cs.daysOfWeek[7] {
+		cs.daysOfWeek[0] = true
+	}
+	cs.anyDayOfMonth = fields[2] == "*"
+	cs.anyDayOfWeek = fields[4] == "*"
+	return cs, nil
+}
+
+// CronSchedule is a Schedule from a cron expression.
+type CronSchedule struct {
+	Expression string
+	Location   *time.Location
+
+	minutes       map[int]bool
+	hours         map[int]bool
+	daysOfMonth   map[int]bool
+	months        map[int]bool
+	daysOfWeek    map[int]bool
+	anyDayOfMonth bool
+	anyDayOfWeek  bool
+}
+
+// Next implements Schedule.
+func (cs *CronSchedule) Next(after time.Time) time.Time {
+	location := cs.Location
+	if location == nil {
+		location = time.Local
+	}
+
+	t := after.In(location).Truncate(time.Minute).Add(time.Minute)
+	// five years is enough to find any valid date (i.e. feb 29th).
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if !cs.months[int(t.Month())] {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, location)
+			continue
+		}
+		if !cs.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, location)
+			continue
+		}
+		if !cs.hours[t.Hour()] {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, location)
+			continue
+		}
+		if !cs.minutes[t.Minute()] {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// matchesDay follows the cron convention that if both day fields are restricted either can match.
+func (cs *CronSchedule) matchesDay(t time.Time) bool {
+	dayOfMonth := cs.daysOfMonth[t.Day()]
+	dayOfWeek := cs.daysOfWeek[int(t.Weekday())]
+	if cs.anyDayOfMonth || cs.anyDayOfWeek {
+		return dayOfMonth && dayOfWeek
+	}
+	return dayOfMonth || dayOfWeek
+}
+
+func (cs *CronSchedule) String() string {
+	return cs.Expression
+}
+
+func parseCronField(field string, min, max int) (map[int]bool, error) {
+	values := map[int]bool{}
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if pieces := strings.SplitN(part, "/", 2); len(pieces) == 2 {
+			parsedStep, err := strconv.Atoi(pieces[1])
+			if err != nil || parsedStep < 1 {
+				return nil, exception.Newf("invalid cron step in `%s`.", field)
+			}
+			part = pieces[0]
+			step = parsedStep
+		}
+
+		low, high := min, max
+		if part != "*" {
+			pieces := strings.SplitN(part, "-", 2)
+			var err error
+			if low, err = strconv.Atoi(pieces[0]); err != nil {
+				return nil, exception.Newf("invalid cron value in `%s`.", field)
+			}
+			high = low
+			if len(pieces) == 2 {
+				if high, err = strconv.Atoi(pieces[1]); err != nil {
+					return nil, exception.Newf("invalid cron range in `%s`.", field)
+				}
+			} else if step > 1 {
+				high = max
+			}
+		}
+
+		if low < min || high > max || low > high {
+			return nil, exception.Newf("cron field `%s` out of range %d-%d.", field, min, max)
+		}
+		for value := low; value <= high; value += step {
+			values[value] = true
+		}
+	}
+	return values, nil
+}
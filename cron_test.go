@@ -0,0 +1,53 @@
+package slack
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blendlabs/go-assert"
+)
+
+func TestParseCron(t *testing.T) {
+	a := assert.New(t)
+
+	_, err := ParseCron("* * *")
+	a.NotNil(err)
+	_, err = ParseCron("60 * * * *")
+	a.NotNil(err)
+	_, err = ParseCron("*/0 * * * *")
+	a.NotNil(err)
+
+	cs, err := ParseCron("@weekdays")
+	a.Nil(err)
+	a.Equal("0 0 * * 1-5", cs.String())
+}
+
+func TestCronScheduleNext(t *testing.T) {
+	a := assert.New(t)
+
+	cs, err := ParseCronInLocation("30 9 * * 1-5", time.UTC)
+	a.Nil(err)
+
+	// friday afternoon => monday morning.
+	friday := time.Date(2016, 3, 4, 15, 0, 0, 0, time.UTC)
+	a.Equal(time.Date(2016, 3, 7, 9, 30, 0, 0, time.UTC), cs.Next(friday))
+
+	// exactly on the schedule => the next day.
+	monday := time.Date(2016, 3, 7, 9, 30, 0, 0, time.UTC)
+	a.Equal(time.Date(2016, 3, 8, 9, 30, 0, 0, time.UTC), cs.Next(monday))
+
+	every15, err := ParseCronInLocation("*/15 * * * *", time.UTC)
+	a.Nil(err)
+	a.Equal(time.Date(2016, 3, 4, 15, 15, 0, 0, time.UTC), every15.Next(time.Date(2016, 3, 4, 15, 1, 30, 0, time.UTC)))
+
+	leap, err := ParseCronInLocation("0 0 29 2 *", time.UTC)
+	a.Nil(err)
+	a.Equal(time.Date(2020, 2, 29, 0, 0, 0, 0, time.UTC), leap.Next(time.Date(2016, 3, 1, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestEvery(t *testing.T) {
+	a := assert.New(t)
+	now := time.Now()
+	a.Equal(now.Add(time.Minute), Every(time.Minute).Next(now))
+	a.True(Every(0).Next(now).IsZero())
+}
@@ -0,0 +1,77 @@
+// Package export writes and reads conversation history in slack's export layout:
+//
+//	users.json              the workspace's users
+//	channels.json           the exported public channels
+//	groups.json             the exported private channels
+//	dms.json                the exported direct messages
+//	mpims.json              the exported multiparty direct messages
+//	<name>/YYYY-MM-DD.json  a conversation's messages for a day, oldest first
+//	files/<id>/<name>       downloaded files, if enabled
+//
+// Conversation directories are named after the channel, except direct messages, which use their id.
+package export
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	slack "github.com/wcharczuk/go-slack"
+)
+
+// Export file names.
+const (
+	// UsersFile lists the workspace's users.
+	UsersFile = "users.json"
+	// ChannelsFile lists the exported public channels.
+	ChannelsFile = "channels.json"
+	// GroupsFile lists the exported private channels.
+	GroupsFile = "groups.json"
+	// DMsFile lists the exported direct messages.
+	DMsFile = "dms.json"
+	// MPIMsFile lists the exported multiparty direct messages.
+	MPIMsFile = "mpims.json"
+	// FilesDirectory holds downloaded files.
+	FilesDirectory = "files"
+	// CheckpointFile records an export's progress so it can be resumed; it isn't included in zips.
+	CheckpointFile = ".checkpoint.json"
+)
+
+// DayFormat is the date format of day file names.
+const DayFormat = "2006-01-02"
+
+// DM is an entry of `dms.json`.
+type DM struct {
+	ID      string          `json:"id"`
+	Created slack.Timestamp `json:"created"`
+	Members []string        `json:"members"`
+}
+
+// timestampTime returns the time of a message timestamp, including its fractional part as microseconds.
+func timestampTime(ts *slack.Timestamp) time.Time {
+	micros, err := strconv.Atoi(ts.UUID())
+	if err != nil || len(ts.UUID()) != 6 {
+		return ts.Time()
+	}
+	return ts.Time().Add(time.Duration(micros) * time.Microsecond)
+}
+
+// timestampLess returns if a is before b, comparing the fractional part too.
+func timestampLess(a, b *slack.Timestamp) bool {
+	if a.Time().Unix() != b.Time().Unix() {
+		return a.Time().Unix() < b.Time().Unix()
+	}
+	return a.UUID() < b.UUID()
+}
+
+// sortMessages sorts messages oldest first.
+func sortMessages(messages []slack.Message) {
+	sort.SliceStable(messages, func(i, j int) bool {
+		return timestampLess(messages[i].Timestamp, messages[j].Timestamp)
+	})
+}
+
+// day returns the day file name of a time.
+func day(t time.Time, location *time.Location) string {
+	return t.In(location).Format(DayFormat) + ".json"
+}
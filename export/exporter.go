@@ -0,0 +1,578 @@
+package export
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	exception "github.com/blendlabs/go-exception"
+	slack "github.com/wcharczuk/go-slack"
+)
+
+const (
+	// DefaultPageSize is the number of messages fetched per history call.
+	DefaultPageSize = 1000
+)
+
+// NewExporter returns an exporter that reads history with a client.
+func NewExporter(client *slack.Client) *Exporter {
+	return &Exporter{
+		client:     client,
+		httpClient: http.DefaultClient,
+		location:   time.UTC,
+		pageSize:   DefaultPageSize,
+	}
+}
+
+// Exporter writes the history of channels, private channels and direct messages in slack's export layout.
+// Exports are resumable and incremental: progress is checkpointed after every day written, and exporting to the same
+// directory again picks up where the last export stopped, or adds the messages posted since it finished.
+type Exporter struct {
+	client        *slack.Client
+	httpClient    *http.Client
+	location      *time.Location
+	oldest        *time.Time
+	latest        *time.Time
+	downloadFiles bool
+	conversations []string
+	pageSize      int
+}
+
+// SetRange limits the export to messages after oldest and before latest; a zero time leaves that end open.
+// Thread replies are exported if both the reply and the thread's parent message are in the range.
+func (e *Exporter) SetRange(oldest, latest time.Time) {
+	e.oldest, e.latest = nil, nil
+	if !oldest.IsZero() {
+		e.oldest = &oldest
+	}
+	if !latest.IsZero() {
+		e.latest = &latest
+	}
+}
+
+// SetLocation sets the time zone messages are split into days in (UTC by default).
+func (e *Exporter) SetLocation(location *time.Location) {
+	e.location = location
+}
+
+// SetDownloadFiles sets if files shared in messages are downloaded into the export.
+func (e *Exporter) SetDownloadFiles(downloadFiles bool) {
+	e.downloadFiles = downloadFiles
+}
+
+// SetHTTPClient sets the http client files are downloaded with.
+func (e *Exporter) SetHTTPClient(httpClient *http.Client) {
+	e.httpClient = httpClient
+}
+
+// SetConversations limits the export to channels, private channels and direct messages by id.
+// By default every channel the client is a member of is exported, with every private channel and direct message.
+func (e *Exporter) SetConversations(channelIDs ...string) {
+	e.conversations = channelIDs
+}
+
+// ExportDirectory exports to a directory, resuming the export already in it if there is one,
+// or adding the messages posted since it finished. The range must be the same as the existing export's.
+func (e *Exporter) ExportDirectory(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return exception.Wrap(err)
+	}
+
+	cp, err := loadCheckpoint(filepath.Join(dir, CheckpointFile))
+	if err != nil {
+		return err
+	}
+	if len(cp.Conversations) == 0 {
+		cp.Oldest, cp.Latest = e.oldest, e.latest
+	} else if !sameTime(cp.Oldest, e.oldest) || !sameTime(cp.Latest, e.latest) {
+		return exception.New("the export in `" + dir + "` has a different range; export to a new directory.")
+	}
+
+	conversations, err := e.writeConversationLists(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range conversations {
+		if err := e.exportConversation(dir, c, cp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExportZip exports to a working directory (see ExportDirectory), then zips it.
+// The working directory is kept, so a failed export can be resumed by calling ExportZip again.
+func (e *Exporter) ExportZip(dir, zipPath string) error {
+	if err := e.ExportDirectory(dir); err != nil {
+		return err
+	}
+	return zipDirectory(dir, zipPath)
+}
+
+//--------------------------------------------------------------------------------
+// INTERNAL METHODS
+//--------------------------------------------------------------------------------
+
+// conversation is a channel, private channel or direct message to export.
+type conversation struct {
+	id      string
+	dir     string
+	history func(channelID string, latest, oldest *time.Time, count int, unreads bool) (*slack.ChannelsHistoryResponse, error)
+	replies func(channelID string, threadTS slack.Timestamp) ([]slack.Message, error)
+}
+
+// includes returns if a conversation is exported.
+func (e *Exporter) includes(channelID string, isMember bool) bool {
+	if len(e.conversations) == 0 {
+		return isMember
+	}
+	for _, id := range e.conversations {
+		if id == channelID {
+			return true
+		}
+	}
+	return false
+}
+
+// writeConversationLists writes the users and conversation list files, and returns the conversations to export.
+func (e *Exporter) writeConversationLists(dir string) ([]conversation, error) {
+	auth, err := e.client.AuthTest()
+	if err != nil {
+		return nil, err
+	}
+	users, err := e.client.UsersList()
+	if err != nil {
+		return nil, err
+	}
+	if err := writeJSON(filepath.Join(dir, UsersFile), users); err != nil {
+		return nil, err
+	}
+
+	var conversations []conversation
+
+	allChannels, err := e.client.ChannelsList(false)
+	if err != nil {
+		return nil, err
+	}
+	channels := []slack.Channel{}
+	for _, channel := range allChannels {
+		if e.includes(channel.ID, channel.IsMember) {
+			channels = append(channels, channel)
+			conversations = append(conversations, conversation{id: channel.ID, dir: channel.Name, history: e.client.ChannelsHistory, replies: e.client.ChannelsReplies})
+		}
+	}
+	if err := writeJSON(filepath.Join(dir, ChannelsFile), channels); err != nil {
+		return nil, err
+	}
+
+	allGroups, err := e.client.GroupsList(false)
+	if err != nil {
+		return nil, err
+	}
+	groups := []slack.Group{}
+	for _, group := range allGroups {
+		if e.includes(group.ID, true) {
+			groups = append(groups, group)
+			conversations = append(conversations, conversation{id: group.ID, dir: group.Name, history: e.client.GroupsHistory, replies: e.client.GroupsReplies})
+		}
+	}
+	if err := writeJSON(filepath.Join(dir, GroupsFile), groups); err != nil {
+		return nil, err
+	}
+
+	ims, err := e.client.ImList()
+	if err != nil {
+		return nil, err
+	}
+	dms := []DM{}
+	for _, im := range ims {
+		if e.includes(im.ID, true) {
+			dms = append(dms, DM{ID: im.ID, Created: im.Created, Members: []string{auth.UserID, im.User}})
+			conversations = append(conversations, conversation{id: im.ID, dir: im.ID, history: e.client.ImHistory, replies: e.client.ImReplies})
+		}
+	}
+	if err := writeJSON(filepath.Join(dir, DMsFile), dms); err != nil {
+		return nil, err
+	}
+
+	allMPIMs, err := e.client.MpimList()
+	if err != nil {
+		return nil, err
+	}
+	mpims := []slack.Group{}
+	for _, mpim := range allMPIMs {
+		if e.includes(mpim.ID, true) {
+			mpims = append(mpims, mpim)
+			conversations = append(conversations, conversation{id: mpim.ID, dir: mpim.Name, history: e.client.MpimHistory, replies: e.client.MpimReplies})
+		}
+	}
+	if err := writeJSON(filepath.Join(dir, MPIMsFile), mpims); err != nil {
+		return nil, err
+	}
+	return conversations, nil
+}
+
+// exportConversation pages through a conversation's history, newest first, writing each day once all of it has been read.
+// The checkpoint's cursor is the oldest message written, so a resumed export continues from the message before it.
+// Once a conversation is done, exporting it again fetches the messages newer than the newest one exported.
+func (e *Exporter) exportConversation(dir string, c conversation, cp *checkpoint) error {
+	progress := cp.progress(c.id)
+	if progress.Done {
+		progress.Done, progress.Cursor = false, nil
+	}
+	latest := e.latest
+	if progress.Cursor != nil {
+		cursor := timestampTime(progress.Cursor)
+		latest = &cursor
+	}
+	oldest := e.oldest
+	if progress.Newest != nil {
+		newest := timestampTime(progress.Newest)
+		if oldest == nil || newest.After(*oldest) {
+			oldest = &newest
+		}
+	}
+
+	var currentDay string
+	var buffer []slack.Message
+	flush := func() error {
+		if len(buffer) == 0 {
+			return nil
+		}
+		if err := e.writeDay(dir, c, currentDay, buffer); err != nil {
+			return err
+		}
+		if progress.Pending == nil {
+			progress.Pending = buffer[0].Timestamp
+		}
+		progress.Cursor = buffer[len(buffer)-1].Timestamp
+		buffer = nil
+		return cp.save()
+	}
+
+	for {
+		res, err := c.history(c.id, latest, oldest, e.pageSize, false)
+		if err != nil {
+			return err
+		}
+
+		added := 0
+		for _, m := range res.Messages {
+			if m.Timestamp == nil || containsMessage(buffer, m) {
+				continue
+			}
+			if messageDay := day(m.Timestamp.Time(), e.location); messageDay != currentDay {
+				if err := flush(); err != nil {
+					return err
+				}
+				currentDay = messageDay
+			}
+			buffer = append(buffer, m)
+			added++
+		}
+		if !res.HasMore || added == 0 {
+			break
+		}
+		next := timestampTime(buffer[len(buffer)-1].Timestamp)
+		latest = &next
+	}
+
+	if err := flush(); err != nil {
+		return err
+	}
+	if progress.Pending != nil {
+		progress.Newest, progress.Pending = progress.Pending, nil
+	}
+	progress.Done, progress.Cursor = true, nil
+	return cp.save()
+}
+
+// writeDay writes a day of messages, with the replies to threads started that day.
+// Replies are written to the day they were posted, which can be a day that's already written.
+func (e *Exporter) writeDay(dir string, c conversation, dayFile string, messages []slack.Message) error {
+	days := map[string][]slack.Message{dayFile: messages}
+	for _, m := range messages {
+		if m.ReplyCount == 0 || (m.ThreadTimestamp != nil && m.ThreadTimestamp.String() != m.Timestamp.String()) {
+			continue
+		}
+		replies, err := c.replies(c.id, *m.Timestamp)
+		if err != nil {
+			return err
+		}
+		for _, reply := range replies {
+			if reply.Timestamp == nil || reply.Timestamp.String() == m.Timestamp.String() || !e.inRange(reply.Timestamp.Time()) {
+				continue
+			}
+			replyDay := day(reply.Timestamp.Time(), e.location)
+			days[replyDay] = append(days[replyDay], reply)
+		}
+	}
+
+	for dayFile, dayMessages := range days {
+		if err := e.downloadMessageFiles(dir, dayMessages); err != nil {
+			return err
+		}
+		if err := mergeDayFile(filepath.Join(dir, c.dir, dayFile), dayMessages); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// inRange returns if a time is in the export's date range.
+func (e *Exporter) inRange(t time.Time) bool {
+	return (e.oldest == nil || t.After(*e.oldest)) && (e.latest == nil || t.Before(*e.latest))
+}
+
+// downloadMessageFiles downloads the files shared in messages, skipping files that are already downloaded.
+func (e *Exporter) downloadMessageFiles(dir string, messages []slack.Message) error {
+	if !e.downloadFiles {
+		return nil
+	}
+	for _, m := range messages {
+		for _, file := range m.Files {
+			if err := e.downloadFile(dir, file); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// slackFileHosts are the hosts the client's token is sent to when downloading files, with their subdomains.
+var slackFileHosts = []string{"files.slack.com", "slack-files.com"}
+
+// isSlackFileHost returns if a file url is hosted by slack.
+func isSlackFileHost(fileURL string) bool {
+	parsed, err := url.Parse(fileURL)
+	if err != nil || parsed.Scheme != "https" {
+		return false
+	}
+	host := strings.ToLower(parsed.Hostname())
+	for _, slackHost := range slackFileHosts {
+		if host == slackHost || strings.HasSuffix(host, "."+slackHost) {
+			return true
+		}
+	}
+	return false
+}
+
+// downloadFile downloads a file hosted by slack; external files (i.e. from google drive) are skipped.
+func (e *Exporter) downloadFile(dir string, file slack.File) error {
+	if file.IsExternal {
+		return nil
+	}
+	fileURL := file.URLPrivateDownload
+	if len(fileURL) == 0 {
+		fileURL = file.URLPrivate
+	}
+	if len(fileURL) == 0 || len(file.ID) == 0 {
+		return nil
+	}
+
+	name := filepath.Base(file.Name)
+	if len(file.Name) == 0 || name == "." || name == string(filepath.Separator) {
+		name = file.ID
+	}
+	path := filepath.Join(dir, FilesDirectory, file.ID, name)
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+
+	req, err := http.NewRequest("GET", fileURL, nil)
+	if err != nil {
+		return exception.Wrap(err)
+	}
+	// only send the token to slack.
+	if isSlackFileHost(fileURL) {
+		req.Header.Set("Authorization", "Bearer "+e.client.Token)
+	}
+	res, err := e.httpClient.Do(req)
+	if err != nil {
+		return exception.Wrap(err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return exception.New(fmt.Sprintf("downloading file `%s` failed: %s", file.ID, res.Status))
+	}
+
+	return writeFile(path, func(w io.Writer) error {
+		_, err := io.Copy(w, res.Body)
+		return err
+	})
+}
+
+// containsMessage returns if a message (by timestamp) is in a list.
+func containsMessage(messages []slack.Message, m slack.Message) bool {
+	for _, other := range messages {
+		if other.Timestamp.String() == m.Timestamp.String() {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeDayFile adds messages to a day file, replacing messages it already has, and sorts it oldest first.
+func mergeDayFile(path string, messages []slack.Message) error {
+	var merged []slack.Message
+	if contents, err := ioutil.ReadFile(path); err == nil {
+		if err := json.Unmarshal(contents, &merged); err != nil {
+			return exception.Wrap(err)
+		}
+	} else if !os.IsNotExist(err) {
+		return exception.Wrap(err)
+	}
+
+	for _, m := range messages {
+		replaced := false
+		for index := range merged {
+			if merged[index].Timestamp.String() == m.Timestamp.String() {
+				merged[index], replaced = m, true
+			}
+		}
+		if !replaced {
+			merged = append(merged, m)
+		}
+	}
+	sortMessages(merged)
+	return writeJSON(path, merged)
+}
+
+// writeJSON writes a value as indented json.
+func writeJSON(path string, value interface{}) error {
+	return writeFile(path, func(w io.Writer) error {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "    ")
+		return encoder.Encode(value)
+	})
+}
+
+// writeFile writes a file through a temporary file, so an interrupted export never leaves a partial file.
+func writeFile(path string, write func(io.Writer) error) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return exception.Wrap(err)
+	}
+	f, err := ioutil.TempFile(filepath.Dir(path), ".tmp-")
+	if err != nil {
+		return exception.Wrap(err)
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return exception.Wrap(err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return exception.Wrap(err)
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		os.Remove(f.Name())
+		return exception.Wrap(err)
+	}
+	return nil
+}
+
+// zipDirectory zips an export directory, leaving out the checkpoint.
+func zipDirectory(dir, zipPath string) error {
+	return writeFile(zipPath, func(w io.Writer) error {
+		archive := zip.NewWriter(w)
+		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			name, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+			if info.IsDir() || name == CheckpointFile || strings.HasPrefix(info.Name(), ".tmp-") {
+				return nil
+			}
+
+			entry, err := archive.Create(filepath.ToSlash(name))
+			if err != nil {
+				return err
+			}
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			_, err = io.Copy(entry, f)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		return archive.Close()
+	})
+}
+
+//--------------------------------------------------------------------------------
+// checkpoint
+//--------------------------------------------------------------------------------
+
+// checkpoint is the progress of an export, and the range it was made with.
+type checkpoint struct {
+	path          string
+	Oldest        *time.Time                       `json:"oldest,omitempty"`
+	Latest        *time.Time                       `json:"latest,omitempty"`
+	Conversations map[string]*conversationProgress `json:"conversations"`
+}
+
+// conversationProgress is the progress of a conversation's export.
+// Cursor is the oldest message written by the export in progress, and Pending the newest;
+// Newest is the newest message written by the finished exports.
+type conversationProgress struct {
+	Cursor  *slack.Timestamp `json:"cursor,omitempty"`
+	Pending *slack.Timestamp `json:"pending,omitempty"`
+	Newest  *slack.Timestamp `json:"newest,omitempty"`
+	Done    bool             `json:"done"`
+}
+
+func loadCheckpoint(path string) (*checkpoint, error) {
+	cp := &checkpoint{path: path, Conversations: map[string]*conversationProgress{}}
+	contents, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cp, nil
+	}
+	if err != nil {
+		return nil, exception.Wrap(err)
+	}
+	if err := json.Unmarshal(contents, cp); err != nil {
+		return nil, exception.Wrap(err)
+	}
+	if cp.Conversations == nil {
+		cp.Conversations = map[string]*conversationProgress{}
+	}
+	return cp, nil
+}
+
+func (cp *checkpoint) progress(channelID string) *conversationProgress {
+	progress, hasProgress := cp.Conversations[channelID]
+	if !hasProgress {
+		progress = &conversationProgress{}
+		cp.Conversations[channelID] = progress
+	}
+	return progress
+}
+
+func (cp *checkpoint) save() error {
+	return writeJSON(cp.path, cp)
+}
+
+// sameTime returns if two optional times are both unset or equal.
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equal(*b)
+}
@@ -0,0 +1,202 @@
+package export
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	assert "github.com/blendlabs/go-assert"
+	slack "github.com/wcharczuk/go-slack"
+	"github.com/wcharczuk/go-slack/slacktest"
+)
+
+// testTimestamp returns a message timestamp with a fractional part, i.e. `1546300800.000001`.
+func testTimestamp(value string) *slack.Timestamp {
+	var ts slack.Timestamp
+	ts.UnmarshalJSON([]byte(value))
+	return &ts
+}
+
+func readDay(a *assert.Assertions, path string) []string {
+	contents, err := ioutil.ReadFile(path)
+	a.Nil(err)
+	var messages []slack.Message
+	a.Nil(json.Unmarshal(contents, &messages))
+	var texts []string
+	for _, m := range messages {
+		texts = append(texts, m.Text)
+	}
+	return texts
+}
+
+func newTestServer() *slacktest.Server {
+	server := slacktest.New()
+	w := server.Workspace
+	// 2019-01-01 and 2019-01-02, UTC.
+	w.AddMessage(slacktest.DefaultChannelID, slack.Message{User: slacktest.DefaultUserID, Text: "first", Timestamp: testTimestamp("1546300800.000001")})
+	w.AddMessage(slacktest.DefaultChannelID, slack.Message{User: slacktest.DefaultUserID, Text: "second", Timestamp: testTimestamp("1546300800.000002")})
+	w.AddMessage(slacktest.DefaultChannelID, slack.Message{User: slacktest.DefaultUserID, Text: "third", Timestamp: testTimestamp("1546300800.000003")})
+	w.AddMessage(slacktest.DefaultChannelID, slack.Message{User: slacktest.DefaultUserID, Text: "next day", Timestamp: testTimestamp("1546387200.000001")})
+	w.AddMessage(slacktest.DefaultChannelID, slack.Message{User: slacktest.DefaultBotID, Text: "late reply", Timestamp: testTimestamp("1546390800.000001"), ThreadTimestamp: testTimestamp("1546300800.000002")})
+	w.AddMessage(slacktest.DefaultChannelID, slack.Message{User: slacktest.DefaultBotID, Text: "same day reply", Timestamp: testTimestamp("1546300900.000001"), ThreadTimestamp: testTimestamp("1546300800.000002")})
+
+	im := w.AddIM(slacktest.DefaultUserID)
+	w.AddMessage(im.ID, slack.Message{User: slacktest.DefaultUserID, Text: "psst", Timestamp: testTimestamp("1546300800.000004")})
+	w.AddGroup(slack.Group{ID: "G0SECRET", Name: "secret", Members: []string{slacktest.DefaultBotID}})
+	return server
+}
+
+func TestExportDirectory(t *testing.T) {
+	a := assert.New(t)
+	server := newTestServer()
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "export")
+	a.Nil(err)
+	defer os.RemoveAll(dir)
+
+	exporter := NewExporter(server.Client())
+	exporter.pageSize = 2
+	a.Nil(exporter.ExportDirectory(dir))
+
+	a.Equal([]string{"first", "second", "third", "same day reply"}, readDay(a, filepath.Join(dir, "general", "2019-01-01.json")))
+	a.Equal([]string{"next day", "late reply"}, readDay(a, filepath.Join(dir, "general", "2019-01-02.json")))
+
+	var dms []DM
+	contents, err := ioutil.ReadFile(filepath.Join(dir, DMsFile))
+	a.Nil(err)
+	a.Nil(json.Unmarshal(contents, &dms))
+	a.Len(dms, 1)
+	a.Equal([]string{slacktest.DefaultBotID, slacktest.DefaultUserID}, dms[0].Members)
+	a.Equal([]string{"psst"}, readDay(a, filepath.Join(dir, dms[0].ID, "2019-01-01.json")))
+
+	var groups []slack.Group
+	contents, err = ioutil.ReadFile(filepath.Join(dir, GroupsFile))
+	a.Nil(err)
+	a.Nil(json.Unmarshal(contents, &groups))
+	a.Len(groups, 1)
+	// like slack's exports, `created` is an integer.
+	var rawChannels []map[string]interface{}
+	contents, err = ioutil.ReadFile(filepath.Join(dir, ChannelsFile))
+	a.Nil(err)
+	a.Nil(json.Unmarshal(contents, &rawChannels))
+	a.NotEmpty(rawChannels)
+	_, isNumber := rawChannels[0]["created"].(float64)
+	a.True(isNumber, rawChannels[0]["created"])
+	var rawDMs []map[string]interface{}
+	contents, err = ioutil.ReadFile(filepath.Join(dir, DMsFile))
+	a.Nil(err)
+	a.Nil(json.Unmarshal(contents, &rawDMs))
+	_, isNumber = rawDMs[0]["created"].(float64)
+	a.True(isNumber, rawDMs[0]["created"])
+
+	for _, name := range []string{UsersFile, ChannelsFile, MPIMsFile, CheckpointFile} {
+		_, err := os.Stat(filepath.Join(dir, name))
+		a.Nil(err, name)
+	}
+
+	// exporting a finished export again adds the messages newer than the newest one exported.
+	server.Workspace.AddMessage(slacktest.DefaultChannelID, slack.Message{Text: "after the export", Timestamp: testTimestamp("1546387300.000001")})
+	a.Nil(exporter.ExportDirectory(dir))
+	calls := server.Calls("channels.history")
+	a.Equal("1546387200.000001", calls[len(calls)-1].Get("oldest"))
+	a.Equal([]string{"next day", "after the export", "late reply"}, readDay(a, filepath.Join(dir, "general", "2019-01-02.json")))
+	cp, err := loadCheckpoint(filepath.Join(dir, CheckpointFile))
+	a.Nil(err)
+	a.Equal("1546387300.000001", cp.progress(slacktest.DefaultChannelID).Newest.String())
+	a.True(cp.progress(slacktest.DefaultChannelID).Done)
+
+	// an unfinished export resumes from its cursor without duplicating messages.
+	cp.Conversations[slacktest.DefaultChannelID] = &conversationProgress{Cursor: testTimestamp("1546387200.000001"), Pending: testTimestamp("1546387300.000001")}
+	a.Nil(cp.save())
+	a.Nil(os.Remove(filepath.Join(dir, "general", "2019-01-01.json")))
+	a.Nil(exporter.ExportDirectory(dir))
+	a.Equal([]string{"first", "second", "third", "same day reply"}, readDay(a, filepath.Join(dir, "general", "2019-01-01.json")))
+	a.Equal([]string{"next day", "after the export", "late reply"}, readDay(a, filepath.Join(dir, "general", "2019-01-02.json")))
+	cp, err = loadCheckpoint(filepath.Join(dir, CheckpointFile))
+	a.Nil(err)
+	a.Equal("1546387300.000001", cp.progress(slacktest.DefaultChannelID).Newest.String())
+
+	// the range can't change between exports.
+	exporter.SetRange(time.Unix(1546387200, 0), time.Time{})
+	a.NotNil(exporter.ExportDirectory(dir))
+}
+
+func TestExportRangeAndFiles(t *testing.T) {
+	a := assert.New(t)
+	server := newTestServer()
+	defer server.Close()
+
+	// the token is only sent to slack's file host, and external files aren't downloaded.
+	var lock sync.Mutex
+	authorized := map[string]bool{}
+	files := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		authorized[r.Host+r.URL.Path] = r.Header.Get("Authorization") == "Bearer "+slacktest.DefaultToken
+		lock.Unlock()
+		rw.Write([]byte("notes"))
+	}))
+	defer files.Close()
+	filesURL, err := url.Parse(files.URL)
+	a.Nil(err)
+	server.Workspace.AddMessage(slacktest.DefaultChannelID, slack.Message{
+		User:      slacktest.DefaultUserID,
+		Timestamp: testTimestamp("1546387200.000002"),
+		Files: []slack.File{
+			{ID: "F0NOTES", Name: "notes.txt", URLPrivateDownload: "https://files.slack.com/files-pri/T0/F0NOTES/notes.txt"},
+			{ID: "F0MIRROR", Name: "mirror.txt", URLPrivateDownload: "https://files.example.com/mirror.txt"},
+			{ID: "F0DRIVE", Name: "drive.doc", IsExternal: true, URLPrivate: "https://files.slack.com/files-pri/T0/F0DRIVE/drive.doc"},
+		},
+	})
+
+	dir, err := ioutil.TempDir("", "export")
+	a.Nil(err)
+	defer os.RemoveAll(dir)
+
+	exporter := NewExporter(server.Client())
+	exporter.SetHTTPClient(&http.Client{Transport: roundTripper(func(r *http.Request) (*http.Response, error) {
+		r.Host = r.URL.Host
+		r.URL.Scheme, r.URL.Host = filesURL.Scheme, filesURL.Host
+		return http.DefaultTransport.RoundTrip(r)
+	})})
+	exporter.SetRange(time.Unix(1546387200, 0), time.Time{})
+	exporter.SetConversations(slacktest.DefaultChannelID)
+	exporter.SetDownloadFiles(true)
+	zipPath := filepath.Join(dir, "export.zip")
+	a.Nil(exporter.ExportZip(filepath.Join(dir, "work"), zipPath))
+
+	_, err = os.Stat(filepath.Join(dir, "work", "general", "2019-01-01.json"))
+	a.True(os.IsNotExist(err))
+	// the late reply's thread started before the range.
+	a.Equal([]string{"next day", ""}, readDay(a, filepath.Join(dir, "work", "general", "2019-01-02.json")))
+	contents, err := ioutil.ReadFile(filepath.Join(dir, "work", FilesDirectory, "F0NOTES", "notes.txt"))
+	a.Nil(err)
+	a.Equal("notes", string(contents))
+	a.Equal(map[string]bool{"files.slack.com/files-pri/T0/F0NOTES/notes.txt": true, "files.example.com/mirror.txt": false}, authorized)
+
+	archive, err := zip.OpenReader(zipPath)
+	a.Nil(err)
+	defer archive.Close()
+	var names []string
+	for _, f := range archive.File {
+		names = append(names, f.Name)
+	}
+	sort.Strings(names)
+	a.Equal([]string{"channels.json", "dms.json", "files/F0MIRROR/mirror.txt", "files/F0NOTES/notes.txt", "general/2019-01-02.json", "groups.json", "mpims.json", "users.json"}, names)
+}
+
+// roundTripper is an http.RoundTripper func.
+type roundTripper func(*http.Request) (*http.Response, error)
+
+func (rt roundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	return rt(r)
+}
@@ -0,0 +1,392 @@
+package export
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	exception "github.com/blendlabs/go-exception"
+	slack "github.com/wcharczuk/go-slack"
+)
+
+// Conversation types.
+const (
+	// ConversationChannel is a public channel, listed in `channels.json`.
+	ConversationChannel = "channel"
+	// ConversationGroup is a private channel, listed in `groups.json`.
+	ConversationGroup = "group"
+	// ConversationIM is a direct message, listed in `dms.json`.
+	ConversationIM = "im"
+	// ConversationMPIM is a multiparty direct message, listed in `mpims.json`.
+	ConversationMPIM = "mpim"
+)
+
+// Conversation is a channel, private channel or direct message in an export.
+type Conversation struct {
+	ID   string
+	Name string
+	Type string
+	// Dir is the directory of the conversation's day files.
+	Dir string
+}
+
+// Message is a message read from an export.
+type Message struct {
+	slack.Message
+	// Sender is the message's user from `users.json`, or nil for bot messages and users that aren't in the export.
+	Sender *slack.User
+}
+
+// OpenReader opens an export zip, or an export directory (i.e. one written by ExportDirectory).
+func OpenReader(exportPath string) (*Reader, error) {
+	info, err := os.Stat(exportPath)
+	if err != nil {
+		return nil, exception.Wrap(err)
+	}
+	if info.IsDir() {
+		return newReader(dirSource(exportPath))
+	}
+
+	archive, err := zip.OpenReader(exportPath)
+	if err != nil {
+		return nil, exception.Wrap(err)
+	}
+	r, err := newReader(newZipSource(&archive.Reader, archive))
+	if err != nil {
+		archive.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// NewReader reads an export zip from an io.ReaderAt, i.e. an uploaded file.
+func NewReader(readerAt io.ReaderAt, size int64) (*Reader, error) {
+	archive, err := zip.NewReader(readerAt, size)
+	if err != nil {
+		return nil, exception.Wrap(err)
+	}
+	return newReader(newZipSource(archive, nil))
+}
+
+// Reader reads an export into the library's types. Users and conversation lists are read when it's opened;
+// messages are read a day at a time as they're iterated, so large exports aren't loaded into memory.
+// Reader implements `format.Names`, so message text can be rendered with `format.PlainText(m.Text, reader)`.
+type Reader struct {
+	source        source
+	users         []slack.User
+	usersByID     map[string]*slack.User
+	channels      []slack.Channel
+	groups        []slack.Group
+	dms           []DM
+	mpims         []slack.Group
+	conversations []Conversation
+}
+
+// Close closes the export.
+func (r *Reader) Close() error {
+	return r.source.Close()
+}
+
+// Users returns the users in `users.json`.
+func (r *Reader) Users() []slack.User {
+	return r.users
+}
+
+// User returns a user by id.
+func (r *Reader) User(userID string) (slack.User, bool) {
+	if user, hasUser := r.usersByID[userID]; hasUser {
+		return *user, true
+	}
+	return slack.User{}, false
+}
+
+// UserName returns the name of a user, or an empty string.
+func (r *Reader) UserName(userID string) string {
+	if user, hasUser := r.usersByID[userID]; hasUser {
+		return user.Name
+	}
+	return ""
+}
+
+// ChannelName returns the name of a channel or private channel, or an empty string.
+func (r *Reader) ChannelName(channelID string) string {
+	for _, c := range r.conversations {
+		if c.ID == channelID && (c.Type == ConversationChannel || c.Type == ConversationGroup) {
+			return c.Name
+		}
+	}
+	return ""
+}
+
+// Channels returns the channels in `channels.json`.
+func (r *Reader) Channels() []slack.Channel {
+	return r.channels
+}
+
+// Groups returns the private channels in `groups.json`.
+func (r *Reader) Groups() []slack.Group {
+	return r.groups
+}
+
+// DMs returns the direct messages in `dms.json`.
+func (r *Reader) DMs() []DM {
+	return r.dms
+}
+
+// MPIMs returns the multiparty direct messages in `mpims.json`.
+func (r *Reader) MPIMs() []slack.Group {
+	return r.mpims
+}
+
+// Conversations returns every conversation in the export.
+func (r *Reader) Conversations() []Conversation {
+	return r.conversations
+}
+
+// Messages returns an iterator over a conversation's messages (by id or name), oldest first.
+// Unknown conversations have no messages.
+func (r *Reader) Messages(conversation string) *MessageIterator {
+	it := &MessageIterator{reader: r}
+	for _, c := range r.conversations {
+		if c.ID == conversation || c.Name == conversation {
+			it.conversation = c
+			it.days = r.days(c.Dir)
+			break
+		}
+	}
+	return it
+}
+
+// OpenFile opens a file downloaded into the export (see Exporter.SetDownloadFiles).
+func (r *Reader) OpenFile(file slack.File) (io.ReadCloser, error) {
+	prefix := FilesDirectory + "/" + file.ID + "/"
+	for _, name := range r.source.Names() {
+		if strings.HasPrefix(name, prefix) {
+			return r.source.Open(name)
+		}
+	}
+	return nil, exception.New("file `" + file.ID + "` isn't in the export.")
+}
+
+// MessageIterator iterates over a conversation's messages, reading a day file at a time:
+//
+//	it := reader.Messages("general")
+//	for it.Next() {
+//		m := it.Message()
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type MessageIterator struct {
+	reader       *Reader
+	conversation Conversation
+	days         []string
+	day          []slack.Message
+	current      *Message
+	err          error
+}
+
+// Next advances to the next message, returning false at the end of the conversation or on an error.
+func (it *MessageIterator) Next() bool {
+	for len(it.day) == 0 {
+		if it.err != nil || len(it.days) == 0 {
+			it.current = nil
+			return false
+		}
+		it.day, it.err = it.reader.readDay(it.days[0])
+		it.days = it.days[1:]
+	}
+
+	m := Message{Message: it.day[0]}
+	it.day = it.day[1:]
+	m.Channel = it.conversation.ID
+	if user, hasUser := it.reader.usersByID[m.User]; hasUser {
+		m.Sender = user
+	}
+	it.current = &m
+	return true
+}
+
+// Message returns the current message.
+func (it *MessageIterator) Message() *Message {
+	return it.current
+}
+
+// Conversation returns the conversation being iterated.
+func (it *MessageIterator) Conversation() Conversation {
+	return it.conversation
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *MessageIterator) Err() error {
+	return it.err
+}
+
+//--------------------------------------------------------------------------------
+// INTERNAL METHODS
+//--------------------------------------------------------------------------------
+
+func newReader(s source) (*Reader, error) {
+	r := &Reader{source: s, usersByID: map[string]*slack.User{}}
+	lists := []struct {
+		name  string
+		value interface{}
+	}{
+		{UsersFile, &r.users},
+		{ChannelsFile, &r.channels},
+		{GroupsFile, &r.groups},
+		{DMsFile, &r.dms},
+		{MPIMsFile, &r.mpims},
+	}
+	for _, list := range lists {
+		if err := r.readJSON(list.name, list.value); err != nil {
+			return nil, err
+		}
+	}
+
+	for index := range r.users {
+		r.usersByID[r.users[index].ID] = &r.users[index]
+	}
+	for _, channel := range r.channels {
+		r.conversations = append(r.conversations, Conversation{ID: channel.ID, Name: channel.Name, Type: ConversationChannel, Dir: channel.Name})
+	}
+	for _, group := range r.groups {
+		r.conversations = append(r.conversations, Conversation{ID: group.ID, Name: group.Name, Type: ConversationGroup, Dir: group.Name})
+	}
+	for _, dm := range r.dms {
+		r.conversations = append(r.conversations, Conversation{ID: dm.ID, Name: dm.ID, Type: ConversationIM, Dir: dm.ID})
+	}
+	for _, mpim := range r.mpims {
+		r.conversations = append(r.conversations, Conversation{ID: mpim.ID, Name: mpim.Name, Type: ConversationMPIM, Dir: mpim.Name})
+	}
+	return r, nil
+}
+
+// readJSON decodes a file; missing files (i.e. `groups.json` in exports from free workspaces) are left empty.
+func (r *Reader) readJSON(name string, value interface{}) error {
+	f, err := r.source.Open(name)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := json.NewDecoder(f).Decode(value); err != nil {
+		return exception.Wrap(err)
+	}
+	return nil
+}
+
+// days returns a conversation's day files, oldest first.
+func (r *Reader) days(dir string) []string {
+	var days []string
+	for _, name := range r.source.Names() {
+		if path.Dir(name) != dir || path.Ext(name) != ".json" {
+			continue
+		}
+		if _, err := time.Parse(DayFormat, strings.TrimSuffix(path.Base(name), ".json")); err == nil {
+			days = append(days, name)
+		}
+	}
+	sort.Strings(days)
+	return days
+}
+
+// readDay reads a day file, sorted oldest first.
+func (r *Reader) readDay(name string) ([]slack.Message, error) {
+	var messages []slack.Message
+	if err := r.readJSON(name, &messages); err != nil {
+		return nil, err
+	}
+
+	sorted := messages[:0]
+	for _, m := range messages {
+		if m.Timestamp != nil {
+			sorted = append(sorted, m)
+		}
+	}
+	sortMessages(sorted)
+	return sorted, nil
+}
+
+//--------------------------------------------------------------------------------
+// sources
+//--------------------------------------------------------------------------------
+
+// source is the zip or directory an export is read from; names are slash separated and relative to the export's root.
+type source interface {
+	Names() []string
+	Open(name string) (io.ReadCloser, error)
+	Close() error
+}
+
+func newZipSource(archive *zip.Reader, closer io.Closer) *zipSource {
+	s := &zipSource{closer: closer, files: map[string]*zip.File{}}
+	for _, f := range archive.File {
+		if !f.FileInfo().IsDir() {
+			s.names = append(s.names, f.Name)
+			s.files[f.Name] = f
+		}
+	}
+	return s
+}
+
+type zipSource struct {
+	closer io.Closer
+	names  []string
+	files  map[string]*zip.File
+}
+
+func (s *zipSource) Names() []string {
+	return s.names
+}
+
+func (s *zipSource) Open(name string) (io.ReadCloser, error) {
+	f, hasFile := s.files[name]
+	if !hasFile {
+		return nil, os.ErrNotExist
+	}
+	contents, err := f.Open()
+	if err != nil {
+		return nil, exception.Wrap(err)
+	}
+	return contents, nil
+}
+
+func (s *zipSource) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+	return s.closer.Close()
+}
+
+type dirSource string
+
+func (s dirSource) Names() []string {
+	var names []string
+	filepath.Walk(string(s), func(filePath string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return nil
+		}
+		if name, err := filepath.Rel(string(s), filePath); err == nil {
+			names = append(names, filepath.ToSlash(name))
+		}
+		return nil
+	})
+	return names
+}
+
+func (s dirSource) Open(name string) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(string(s), filepath.FromSlash(name)))
+}
+
+func (s dirSource) Close() error {
+	return nil
+}
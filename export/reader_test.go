@@ -0,0 +1,142 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	assert "github.com/blendlabs/go-assert"
+	slack "github.com/wcharczuk/go-slack"
+	"github.com/wcharczuk/go-slack/format"
+	"github.com/wcharczuk/go-slack/slacktest"
+)
+
+// testArchive is a trimmed down export, as downloaded from a workspace's admin pages.
+var testArchive = map[string]string{
+	"users.json":    `[{"id":"U0ALICE","name":"alice"},{"id":"U0BOB","name":"bob"}]`,
+	"channels.json": `[{"id":"C0GENERAL","name":"general","members":["U0ALICE","U0BOB"]},{"id":"C0RANDOM","name":"random"}]`,
+	"general/2019-01-02.json": `[
+		{"type":"message","user":"U0BOB","text":"later","ts":"1546387200.000100"}
+	]`,
+	"general/2019-01-01.json": `[
+		{"type":"message","user":"U0BOB","text":"hi <@U0ALICE>","ts":"1546300800.000200"},
+		{"type":"message","user":"U0ALICE","text":"hello","ts":"1546300800.000100"},
+		{"type":"message","subtype":"bot_message","bot_id":"B0BOT","username":"deploys","text":"deployed","ts":"1546300900.000100"}
+	]`,
+	"general/notes.txt":   "not a day file",
+	"files/F0NOTES/a.txt": "notes",
+}
+
+func testArchiveBytes(a *assert.Assertions) []byte {
+	buffer := bytes.NewBuffer(nil)
+	archive := zip.NewWriter(buffer)
+	for name, contents := range testArchive {
+		w, err := archive.Create(name)
+		a.Nil(err)
+		_, err = w.Write([]byte(contents))
+		a.Nil(err)
+	}
+	a.Nil(archive.Close())
+	return buffer.Bytes()
+}
+
+func TestReader(t *testing.T) {
+	a := assert.New(t)
+	contents := testArchiveBytes(a)
+	reader, err := NewReader(bytes.NewReader(contents), int64(len(contents)))
+	a.Nil(err)
+	defer reader.Close()
+
+	a.Len(reader.Users(), 2)
+	a.Len(reader.Channels(), 2)
+	a.Empty(reader.Groups())
+	a.Empty(reader.DMs())
+	a.Len(reader.Conversations(), 2)
+	a.Equal("alice", reader.UserName("U0ALICE"))
+	a.Equal("general", reader.ChannelName("C0GENERAL"))
+
+	var texts, senders []string
+	it := reader.Messages("general")
+	for it.Next() {
+		m := it.Message()
+		a.Equal("C0GENERAL", m.Channel)
+		texts = append(texts, format.PlainText(m.Text, reader))
+		if m.Sender != nil {
+			senders = append(senders, m.Sender.Name)
+		} else {
+			senders = append(senders, m.Username)
+		}
+	}
+	a.Nil(it.Err())
+	a.Equal([]string{"hello", "hi @alice", "deployed", "later"}, texts)
+	a.Equal([]string{"alice", "bob", "deploys", "bob"}, senders)
+
+	it = reader.Messages("C0RANDOM")
+	a.False(it.Next())
+	a.Nil(it.Err())
+	a.False(reader.Messages("not-a-channel").Next())
+
+	f, err := reader.OpenFile(slack.File{ID: "F0NOTES"})
+	a.Nil(err)
+	notes, err := ioutil.ReadAll(f)
+	f.Close()
+	a.Nil(err)
+	a.Equal("notes", string(notes))
+	_, err = reader.OpenFile(slack.File{ID: "F0MISSING"})
+	a.NotNil(err)
+}
+
+func TestReaderCorruptDay(t *testing.T) {
+	a := assert.New(t)
+	dir, err := ioutil.TempDir("", "export")
+	a.Nil(err)
+	defer os.RemoveAll(dir)
+
+	a.Nil(os.MkdirAll(filepath.Join(dir, "general"), 0755))
+	a.Nil(ioutil.WriteFile(filepath.Join(dir, ChannelsFile), []byte(testArchive[ChannelsFile]), 0644))
+	a.Nil(ioutil.WriteFile(filepath.Join(dir, "general", "2019-01-01.json"), []byte(`[{"text":`), 0644))
+
+	reader, err := OpenReader(dir)
+	a.Nil(err)
+	it := reader.Messages("general")
+	a.False(it.Next())
+	a.NotNil(it.Err())
+}
+
+func TestReaderRoundTrip(t *testing.T) {
+	a := assert.New(t)
+	server := newTestServer()
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "export")
+	a.Nil(err)
+	defer os.RemoveAll(dir)
+
+	zipPath := filepath.Join(dir, "export.zip")
+	a.Nil(NewExporter(server.Client()).ExportZip(filepath.Join(dir, "work"), zipPath))
+
+	for _, exportPath := range []string{zipPath, filepath.Join(dir, "work")} {
+		reader, err := OpenReader(exportPath)
+		a.Nil(err)
+
+		var texts []string
+		it := reader.Messages(slacktest.DefaultChannelID)
+		for it.Next() {
+			texts = append(texts, it.Message().Text)
+		}
+		a.Nil(it.Err())
+		a.Equal([]string{"first", "second", "third", "same day reply", "next day", "late reply"}, texts)
+
+		a.Len(reader.DMs(), 1)
+		it = reader.Messages(reader.DMs()[0].ID)
+		a.True(it.Next())
+		a.Equal("psst", it.Message().Text)
+		a.NotNil(it.Message().Sender)
+		a.Equal(slacktest.DefaultUserID, it.Message().Sender.ID)
+		a.Equal(ConversationIM, it.Conversation().Type)
+		a.Nil(reader.Close())
+	}
+}
@@ -0,0 +1,47 @@
+package format
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Date tokens, rendered in the reader's timezone and locale.
+const (
+	// DateNum renders as `2014-02-18`.
+	DateNum = "{date_num}"
+	// DateShort renders as `Feb 18, 2014`.
+	DateShort = "{date_short}"
+	// DateLong renders as `Tuesday, February 18th, 2014`.
+	DateLong = "{date_long}"
+	// DatePretty renders as `yesterday` / `today` / `tomorrow`, or DateShort otherwise.
+	DatePretty = "{date_pretty}"
+	// DateShortPretty renders as `yesterday` / `today` / `tomorrow`, or DateShort otherwise.
+	DateShortPretty = "{date_short_pretty}"
+	// DateLongPretty renders as `yesterday` / `today` / `tomorrow`, or DateLong otherwise.
+	DateLongPretty = "{date_long_pretty}"
+	// Time renders as `6:39 AM`.
+	Time = "{time}"
+	// TimeSecs renders as `6:39:42 AM`.
+	TimeSecs = "{time_secs}"
+)
+
+// Date returns a date that slack renders in the reader's timezone, i.e. `format.Date(t, format.DateShort+" at "+format.Time)`.
+// The fallback is shown by clients that can't render dates; it defaults to the time in UTC.
+func Date(t time.Time, tokens string, fallback ...string) string {
+	return DateWithLink(t, tokens, "", fallback...)
+}
+
+// DateWithLink returns a date (see Date) that links to a url; a target that isn't a url isn't linked.
+func DateWithLink(t time.Time, tokens, url string, fallback ...string) string {
+	fallbackText := t.UTC().Format(time.RFC1123)
+	if len(fallback) > 0 && len(fallback[0]) > 0 {
+		fallbackText = fallback[0]
+	}
+
+	pieces := []string{fmt.Sprintf("!date^%d^%s", t.Unix(), label(strings.Replace(tokens, "^", "", -1)))}
+	if isURL(url) {
+		pieces = append(pieces, linkURL(url))
+	}
+	return "<" + strings.Join(pieces, "^") + "|" + label(fallbackText) + ">"
+}
@@ -0,0 +1,165 @@
+// Package format builds slack message text (mrkdwn) safely.
+//
+// Slack treats `&`, `<` and `>` as control characters; text from users or other systems should always go
+// through Escape (or one of the builders here, which escape for you) so it can't inject mentions or links:
+//
+//	text := format.User(m.User) + " deployed " + format.Code(branch) + " to " + format.Link(url, env)
+//
+// Messages built this way should be sent with `Parse: "none"`, so slack doesn't re-interpret plain names.
+package format
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Special mentions.
+const (
+	// Here notifies the active members of a channel.
+	Here = "<!here>"
+	// Channel notifies every member of a channel.
+	Channel = "<!channel>"
+	// Everyone notifies every member of the workspace (only in the general channel).
+	Everyone = "<!everyone>"
+)
+
+var (
+	escaper   = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	unescaper = strings.NewReplacer("&amp;", "&", "&lt;", "<", "&gt;", ">")
+
+	// urlSchemeExpr matches the scheme at the start of a url; a link target without one
+	// (i.e. `!here` or `@U024BE7LH`) would be read as a mention.
+	urlSchemeExpr = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9+.-]*:`)
+)
+
+// Escape escapes the control characters `&`, `<` and `>` in text.
+func Escape(text string) string {
+	return escaper.Replace(text)
+}
+
+// Escapef formats a string and escapes the result.
+func Escapef(format string, args ...interface{}) string {
+	return Escape(fmt.Sprintf(format, args...))
+}
+
+// Unescape reverses Escape.
+func Unescape(text string) string {
+	return unescaper.Replace(text)
+}
+
+// User returns a mention of a user, i.e. `<@U0KMCE0MC>`.
+func User(userID string) string {
+	return fmt.Sprintf("<@%s>", userID)
+}
+
+// ChannelLink returns a link to a channel, i.e. `<#C024BE7LR>`.
+func ChannelLink(channelID string) string {
+	return fmt.Sprintf("<#%s>", channelID)
+}
+
+// ChannelLinkWithName returns a link to a channel with a fallback name for clients that can't resolve it.
+func ChannelLinkWithName(channelID, name string) string {
+	return fmt.Sprintf("<#%s|%s>", channelID, label(name))
+}
+
+// UserGroup returns a mention of a user group, i.e. `<!subteam^S0614TZR7>`, which notifies its members.
+func UserGroup(userGroupID string) string {
+	return fmt.Sprintf("<!subteam^%s>", userGroupID)
+}
+
+// UserGroupWithHandle returns a mention of a user group with a fallback handle (i.e. `oncall`) for clients that can't resolve it.
+func UserGroupWithHandle(userGroupID, handle string) string {
+	return fmt.Sprintf("<!subteam^%s|@%s>", userGroupID, label(strings.TrimPrefix(handle, "@")))
+}
+
+// Link returns a link to a url, with an optional label.
+// A target that isn't a url (it has no scheme, i.e. `!here`) can't be linked, so it's returned as escaped text.
+func Link(url string, labelText ...string) string {
+	if !isURL(url) {
+		if len(labelText) > 0 && len(labelText[0]) > 0 {
+			return Escape(labelText[0])
+		}
+		return Escape(url)
+	}
+	if len(labelText) > 0 && len(labelText[0]) > 0 {
+		return fmt.Sprintf("<%s|%s>", linkURL(url), label(labelText[0]))
+	}
+	return fmt.Sprintf("<%s>", linkURL(url))
+}
+
+// Email returns a `mailto:` link to an email address.
+func Email(address string) string {
+	return fmt.Sprintf("<mailto:%s|%s>", linkURL(address), label(address))
+}
+
+// Bold returns bold text.
+func Bold(text string) string {
+	return wrap("*", text)
+}
+
+// Italic returns italic text.
+func Italic(text string) string {
+	return wrap("_", text)
+}
+
+// Strike returns struck through text.
+func Strike(text string) string {
+	return wrap("~", text)
+}
+
+// Code returns inline code. Slack has no way to escape a backtick inside inline code, so they're replaced with `'`.
+func Code(text string) string {
+	return "`" + Escape(strings.Replace(text, "`", "'", -1)) + "`"
+}
+
+// CodeBlock returns a preformatted block.
+func CodeBlock(text string) string {
+	return "```\n" + Escape(strings.Replace(text, "```", "` ` `", -1)) + "\n```"
+}
+
+// Quote returns text as a block quote.
+func Quote(text string) string {
+	lines := strings.Split(text, "\n")
+	for index, line := range lines {
+		lines[index] = ">" + Escape(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// List returns a bulleted list.
+func List(items ...string) string {
+	lines := make([]string, len(items))
+	for index, item := range items {
+		lines[index] = "• " + Escape(item)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// wrap surrounds escaped text with a formatting character; slack only applies the formatting
+// if it isn't separated from the text by whitespace, so that's moved outside the markers.
+func wrap(marker, text string) string {
+	trimmed := strings.TrimSpace(text)
+	if len(trimmed) == 0 {
+		return Escape(text)
+	}
+	leading := text[:strings.Index(text, trimmed)]
+	trailing := text[len(leading)+len(trimmed):]
+	return leading + marker + Escape(trimmed) + marker + trailing
+}
+
+// label escapes the label of a link; `|` and `>` would end it early.
+func label(text string) string {
+	return Escape(strings.Replace(text, "|", "¦", -1))
+}
+
+// isURL returns if a link target starts with a url scheme; targets starting with `@`, `#` or `!`
+// (which slack reads as mentions) never do.
+func isURL(target string) bool {
+	return urlSchemeExpr.MatchString(target)
+}
+
+// linkURL escapes the url part of a link.
+func linkURL(url string) string {
+	return Escape(strings.Replace(url, "|", "%7C", -1))
+}
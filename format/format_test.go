@@ -0,0 +1,66 @@
+package format
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blendlabs/go-assert"
+)
+
+func TestEscape(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal("a &amp;&amp; b &lt;@U123&gt; &lt;!here&gt;", Escape("a && b <@U123> <!here>"))
+	a.Equal("&amp;amp;", Escape("&amp;"))
+	a.Equal("&amp;", Unescape(Escape("&amp;")))
+	a.Equal("1 &lt; 2", Escapef("%d < %d", 1, 2))
+}
+
+func TestMentions(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal("<@U0KMCE0MC>", User("U0KMCE0MC"))
+	a.Equal("<#C024BE7LR>", ChannelLink("C024BE7LR"))
+	a.Equal("<#C024BE7LR|general>", ChannelLinkWithName("C024BE7LR", "general"))
+	a.Equal("<!here>", Here)
+	a.Equal("<!subteam^S0614TZR7>", UserGroup("S0614TZR7"))
+	a.Equal("<!subteam^S0614TZR7|@oncall>", UserGroupWithHandle("S0614TZR7", "@oncall"))
+}
+
+func TestLinks(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal("<https://example.com>", Link("https://example.com"))
+	a.Equal("<https://example.com?a=1&amp;b=2|a &lt;b&gt; ¦ c>", Link("https://example.com?a=1&b=2", "a <b> | c"))
+	a.Equal("<https://example.com/%7C>", Link("https://example.com/|", ""))
+	a.Equal("<mailto:bob@example.com|bob@example.com>", Email("bob@example.com"))
+
+	// targets that aren't urls would be mentions, so they're escaped text.
+	a.Equal("docs", Link("!here", "docs"))
+	a.Equal("@U123", Link("@U123"))
+	a.Equal("#C024BE7LR", Link("#C024BE7LR"))
+	a.Equal("&lt;b&gt;", Link("example.com", "<b>"))
+}
+
+func TestStyles(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal("*bold*", Bold("bold"))
+	a.Equal(" _it &lt;@U1&gt;_ ", Italic(" it <@U1> "))
+	a.Equal("~gone~", Strike("gone"))
+	a.Equal("  ", Bold("  "))
+	a.Equal("`a 'b' &lt;c&gt;`", Code("a `b` <c>"))
+	a.Equal("```\nfunc() {}\n&lt;!channel&gt;\n```", CodeBlock("func() {}\n<!channel>"))
+	a.Equal("&gt;one\n&gt;&lt;!here&gt;", Escape(">one\n><!here>"))
+	a.Equal(">one\n>&lt;!here&gt;", Quote("one\n<!here>"))
+	a.Equal("• a\n• &lt;b&gt;", List("a", "<b>"))
+}
+
+func TestDate(t *testing.T) {
+	a := assert.New(t)
+
+	ts := time.Date(2014, 02, 18, 14, 39, 42, 0, time.UTC)
+	a.Equal("<!date^1392734382^{date_short} at {time}|Feb 18 2014>", Date(ts, DateShort+" at "+Time, "Feb 18 2014"))
+	a.Equal("<!date^1392734382^{date_num}|Tue, 18 Feb 2014 14:39:42 UTC>", Date(ts, DateNum))
+	a.Equal("<!date^1392734382^{date_long}^https://example.com|Feb 18 2014>", DateWithLink(ts, DateLong, "https://example.com", "Feb 18 2014"))
+}
@@ -0,0 +1,261 @@
+package format
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Resolver resolves the name in an `@name` (TokenUser) or `#name` (TokenChannel) mention to an id.
+// `@name` mentions that aren't users are then resolved as user group handles (TokenUserGroup).
+// It returns an empty string for unknown names, which are left as text.
+type Resolver func(tokenType TokenType, name string) string
+
+var (
+	markdownFence       = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})")
+	markdownHeadingLine = regexp.MustCompile(`^ {0,3}#{1,6}(?:\s+(.*?))?(?:\s+#+)?\s*$`)
+	markdownRule        = regexp.MustCompile(`^ {0,3}(?:(?:\*\s*){3,}|(?:-\s*){3,}|(?:_\s*){3,})$`)
+	markdownQuote       = regexp.MustCompile(`^ {0,3}> ?`)
+	markdownBullet      = regexp.MustCompile(`^(\s*)[-*+]\s+`)
+	markdownOrdered     = regexp.MustCompile(`^(\s*)(\d+)[.)]\s+`)
+	markdownHardBreak   = regexp.MustCompile(`(\\| {2,})$`)
+	markdownMentionName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*`)
+)
+
+// FromMarkdown converts CommonMark to slack mrkdwn, i.e. for posting text from other systems into slack.
+// Text is escaped, so it can't inject mentions; `@name` and `#name` become mentions if resolve (which can be nil) knows them.
+func FromMarkdown(markdown string, resolve Resolver) string {
+	lines := strings.Split(strings.Replace(markdown, "\r\n", "\n", -1), "\n")
+
+	var output []string
+	for index := 0; index < len(lines); index++ {
+		line := lines[index]
+
+		if match := markdownFence.FindStringSubmatch(line); match != nil {
+			var code []string
+			for index++; index < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[index]), match[1]); index++ {
+				code = append(code, lines[index])
+			}
+			output = append(output, CodeBlock(strings.Join(code, "\n")))
+			continue
+		}
+
+		line = markdownHardBreak.ReplaceAllString(line, "")
+		switch {
+		case markdownRule.MatchString(line):
+			output = append(output, "──────────")
+		case markdownHeadingLine.MatchString(line):
+			heading := markdownHeadingLine.FindStringSubmatch(line)[1]
+			output = append(output, "*"+markdownToMrkdwn(heading, resolve)+"*")
+		case markdownQuote.MatchString(line):
+			output = append(output, ">"+markdownToMrkdwn(markdownQuote.ReplaceAllString(line, ""), resolve))
+		case markdownBullet.MatchString(line):
+			match := markdownBullet.FindStringSubmatch(line)
+			output = append(output, match[1]+"• "+markdownToMrkdwn(line[len(match[0]):], resolve))
+		case markdownOrdered.MatchString(line):
+			match := markdownOrdered.FindStringSubmatch(line)
+			output = append(output, match[1]+match[2]+". "+markdownToMrkdwn(line[len(match[0]):], resolve))
+		default:
+			output = append(output, markdownToMrkdwn(line, resolve))
+		}
+	}
+	return strings.Join(output, "\n")
+}
+
+// markdownToMrkdwn converts the inline markup in a line of CommonMark.
+func markdownToMrkdwn(text string, resolve Resolver) string {
+	var output strings.Builder
+	open := map[string]bool{}
+
+	for index := 0; index < len(text); {
+		c := text[index]
+		switch {
+		case c == '\\' && index+1 < len(text) && isPunctuation(text[index+1]):
+			output.WriteString(Escape(text[index+1 : index+2]))
+			index += 2
+
+		case c == '`':
+			fence := delimiterRun(text, index)
+			end := strings.Index(text[index+len(fence):], fence)
+			if end < 0 {
+				output.WriteString(fence)
+				index += len(fence)
+				continue
+			}
+			code := text[index+len(fence) : index+len(fence)+end]
+			if len(code) > 1 && code[0] == ' ' && code[len(code)-1] == ' ' {
+				code = code[1 : len(code)-1]
+			}
+			output.WriteString(Code(code))
+			index += len(fence) + end + len(fence)
+
+		case c == '[' || (c == '!' && strings.HasPrefix(text[index:], "![")):
+			start := index
+			if c == '!' {
+				start++
+			}
+			labelText, url, end, ok := markdownLink(text, start)
+			if !ok {
+				output.WriteString(Escape(text[index : index+1]))
+				index++
+				continue
+			}
+			// Link writes a destination that isn't a url (i.e. `!channel`) as its escaped label.
+			output.WriteString(Link(url, markdownUnescape(labelText)))
+			index = end
+
+		case c == '<':
+			end := strings.IndexByte(text[index:], '>')
+			if end < 0 || !isURL(text[index+1:index+end]) || strings.ContainsAny(text[index+1:index+end], " <") {
+				output.WriteString(Escape("<"))
+				index++
+				continue
+			}
+			output.WriteString(Link(text[index+1 : index+end]))
+			index += end + 1
+
+		case c == '*' || c == '_' || c == '~':
+			run := delimiterRun(text, index)
+			var before, after byte
+			if index > 0 {
+				before = text[index-1]
+			}
+			if index+len(run) < len(text) {
+				after = text[index+len(run)]
+			}
+
+			styles := markdownStyles(run)
+			if len(styles) == 0 {
+				output.WriteString(run)
+				index += len(run)
+				continue
+			}
+
+			if open[run] && !isSpace(before) {
+				for i := len(styles) - 1; i >= 0; i-- {
+					output.WriteString(styles[i])
+				}
+				delete(open, run)
+			} else if !isSpace(after) && (c == '*' || isBoundary(before)) && strings.Contains(text[index+len(run):], run) {
+				for _, style := range styles {
+					output.WriteString(style)
+				}
+				open[run] = true
+			} else {
+				output.WriteString(run)
+			}
+			index += len(run)
+
+		case (c == '@' || c == '#') && resolve != nil && (index == 0 || isBoundary(text[index-1])):
+			name := strings.TrimRight(markdownMentionName.FindString(text[index+1:]), "._-")
+			tokenType := TokenUser
+			if c == '#' {
+				tokenType = TokenChannel
+			}
+
+			var id string
+			if len(name) > 0 {
+				id = resolve(tokenType, name)
+				if len(id) == 0 && tokenType == TokenUser {
+					tokenType = TokenUserGroup
+					id = resolve(tokenType, name)
+				}
+			}
+			if len(id) == 0 {
+				output.WriteString(text[index : index+1])
+				index++
+				continue
+			}
+			switch tokenType {
+			case TokenUser:
+				output.WriteString(User(id))
+			case TokenUserGroup:
+				output.WriteString(UserGroup(id))
+			default:
+				output.WriteString(ChannelLink(id))
+			}
+			index += 1 + len(name)
+
+		default:
+			output.WriteString(Escape(text[index : index+1]))
+			index++
+		}
+	}
+	return output.String()
+}
+
+// markdownStyles returns the mrkdwn markers for a CommonMark delimiter run.
+func markdownStyles(run string) []string {
+	switch run {
+	case "*", "_":
+		return []string{"_"}
+	case "**", "__":
+		return []string{"*"}
+	case "***", "___":
+		return []string{"*", "_"}
+	case "~~":
+		return []string{"~"}
+	default:
+		return nil
+	}
+}
+
+// markdownLink parses `[label](url "title")` starting at index, returning the index after it.
+func markdownLink(text string, index int) (labelText, url string, end int, ok bool) {
+	depth := 0
+	for position := index; position < len(text); position++ {
+		switch text[position] {
+		case '\\':
+			position++
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth > 0 {
+				continue
+			}
+			if position+1 >= len(text) || text[position+1] != '(' {
+				return "", "", 0, false
+			}
+			closeParen := strings.IndexByte(text[position+2:], ')')
+			if closeParen < 0 {
+				return "", "", 0, false
+			}
+			destination := strings.TrimSpace(text[position+2 : position+2+closeParen])
+			if space := strings.IndexAny(destination, " \t"); space >= 0 {
+				destination = destination[:space]
+			}
+			destination = strings.TrimSuffix(strings.TrimPrefix(destination, "<"), ">")
+			return text[index+1 : position], destination, position + 2 + closeParen + 1, len(destination) > 0
+		}
+	}
+	return "", "", 0, false
+}
+
+// markdownUnescape removes backslash escapes and emphasis markers, for link labels (which slack doesn't format).
+func markdownUnescape(text string) string {
+	var output strings.Builder
+	for index := 0; index < len(text); index++ {
+		switch {
+		case text[index] == '\\' && index+1 < len(text) && isPunctuation(text[index+1]):
+			index++
+			output.WriteByte(text[index])
+		case text[index] == '*' || text[index] == '`':
+		default:
+			output.WriteByte(text[index])
+		}
+	}
+	return output.String()
+}
+
+// delimiterRun returns the run of the character at index.
+func delimiterRun(text string, index int) string {
+	end := index
+	for end < len(text) && text[end] == text[index] {
+		end++
+	}
+	return text[index:end]
+}
+
+func isPunctuation(c byte) bool {
+	return c < 0x80 && c > ' ' && !isWordByte(c)
+}
@@ -0,0 +1,80 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/blendlabs/go-assert"
+)
+
+var testLookup = NamesLookup(testNames{"U1": "bob", "C1": "general"})
+
+func testResolver(tokenType TokenType, name string) string {
+	switch {
+	case tokenType == TokenUser && name == "bob":
+		return "U1"
+	case tokenType == TokenChannel && name == "general":
+		return "C1"
+	case tokenType == TokenUserGroup && name == "oncall":
+		return "S1"
+	default:
+		return ""
+	}
+}
+
+func TestToMarkdown(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal("**bold** _italic_ ~~struck~~ snake\\_case 2\\*3\\*4", ToMarkdown("*bold* _italic_ ~struck~ snake_case 2*3*4", nil))
+	a.Equal("**hey @bob** see #general and [the docs](https://example.com/a%20b) or <https://example.com>", ToMarkdown("*hey <@U1>* see <#C1> and <https://example.com/a b|the docs> or <https://example.com>", testLookup))
+	a.Equal("line one\\\nline \\<two\\>\n\nnew paragraph", ToMarkdown("line one\nline &lt;two&gt;\n\nnew paragraph", nil))
+	a.Equal("> quoted **text**\\\n> more\n\nafter", ToMarkdown("&gt; quoted *text*\n&gt; more\nafter", nil))
+	a.Equal("- one\n- two\n\n1. first\n2. second", ToMarkdown("• one\n• two\n1. first\n2. second", nil))
+	a.Equal("run `make`:\n\n```\nif a < b {}\n```", ToMarkdown("run `make`:\n```\nif a &lt; b {}\n```", nil))
+	a.Equal("``a`b``", markdownCode("a`b"))
+	a.Equal("`` `a ``", markdownCode("`a"))
+	a.Equal("\\# not a heading #general", ToMarkdown("# not a heading <#C1>", testLookup))
+}
+
+func TestToHTML(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal("<p><strong>bold</strong> <em>it</em> <del>gone</del> &lt;b&gt; @bob <a href=\"https://example.com?a=1&amp;b=2\">docs</a></p>",
+		ToHTML("*bold* _it_ ~gone~ &lt;b&gt; <@U1> <https://example.com?a=1&amp;b=2|docs>", testLookup))
+	a.Equal("<blockquote>a<br>\nb</blockquote>\n<ul>\n<li>one</li>\n<li><code>two</code></li>\n</ul>\n<pre><code>x &lt; y</code></pre>",
+		ToHTML("&gt; a\n&gt; b\n• one\n• `two`\n```x &lt; y```", nil))
+	a.Equal("<ol>\n<li>first</li>\n</ol>\n<p>one<br>\ntwo</p>", ToHTML("1. first\none\ntwo", nil))
+
+	// only http, https and mailto links become anchors.
+	a.Equal("<p>x and <a href=\"mailto:bob@example.com\">bob@example.com</a></p>",
+		ToHTML("<javascript:alert(1)|x> and <mailto:bob@example.com>", nil))
+	a.Equal("<p>data:text/html,&lt;script&gt;</p>", ToHTML("<data:text/html,&lt;script&gt;>", nil))
+}
+
+func TestFromMarkdown(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal("*bold* _italic_ _also_ *_both_* ~struck~ snake_case", FromMarkdown("**bold** *italic* _also_ ***both*** ~~struck~~ snake_case", nil))
+	a.Equal("&lt;@U2&gt; &amp; &lt;!channel&gt; 1 &lt; 2", FromMarkdown("<@U2> & <!channel> 1 < 2", nil))
+	a.Equal("<https://example.com|the *docs*> <https://example.com> <https://example.com/i.png|logo>", FromMarkdown("[the \\*docs\\*](https://example.com \"title\") <https://example.com> ![logo](https://example.com/i.png)", nil))
+	a.Equal("`a &lt; b` literal *stars*", FromMarkdown("`a < b` literal \\*stars\\*", nil))
+	a.Equal("*Heading*\n• one\n  • nested\n2. second\n>quoted\n──────────\n```\nx &lt; y\n```", FromMarkdown("## Heading ##\n- one\n  * nested\n2) second\n> quoted\n---\n```go\nx < y\n```", nil))
+	a.Equal("hi <@U1> in <#C1>, @alice and email@example.com", FromMarkdown("hi @bob in #general, @alice and email@example.com", testResolver))
+	a.Equal("paging <!subteam^S1>", FromMarkdown("paging @oncall", testResolver))
+	a.Equal("hard break", FromMarkdown("hard break  ", nil))
+
+	// link destinations and autolinks that aren't urls can't become mentions.
+	a.Equal("hi hi", FromMarkdown("[hi](!channel) [hi](@U123)", nil))
+	a.Equal("&lt;!channel:x&gt; &lt;@U123:x&gt; &lt;!subteam^S1:x&gt; &lt;#C1:x&gt;", FromMarkdown("<!channel:x> <@U123:x> <!subteam^S1:x> <#C1:x>", nil))
+	for _, markdown := range []string{"[hi](!channel)", "[hi](@U123)", "<!channel:x>", "<@U123:x>", "<!subteam^S1:x>"} {
+		for _, token := range Parse(FromMarkdown(markdown, nil)) {
+			a.Equal(TokenText, token.Type, markdown)
+		}
+	}
+}
+
+func TestMarkdownRoundTrip(t *testing.T) {
+	a := assert.New(t)
+
+	text := "*deploy* of `api` to <https://example.com|prod> by <@U1>\n\n• step one\n• step _two_"
+	a.Equal(text, FromMarkdown(ToMarkdown(text, testLookup), testResolver))
+}
@@ -0,0 +1,388 @@
+package format
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// ToMarkdown converts slack mrkdwn (i.e. `Message.Text` or `ChatMessageAttachment.Text`) to CommonMark
+// (with GitHub's `~~strikethrough~~`). Mentions are rendered as `@name` / `#name` using lookup, which can be nil.
+func ToMarkdown(text string, lookup Lookup) string {
+	var blocks []string
+	for _, b := range splitBlocks(Parse(text)) {
+		var lines []string
+		for index, line := range b.lines {
+			if b.kind == blockCode {
+				lines = append(lines, markdownCodeBlock(line[0].Text))
+				continue
+			}
+
+			rendered := renderInline(line, markdownInline, lookup)
+			switch b.kind {
+			case blockQuote:
+				rendered = "> " + rendered
+			case blockBullet:
+				rendered = "- " + rendered
+			case blockOrdered:
+				rendered = b.numbers[index] + ". " + rendered
+			default:
+				if markdownHeading.MatchString(rendered) {
+					rendered = "\\" + rendered
+				}
+			}
+			lines = append(lines, rendered)
+		}
+
+		separator := "\n"
+		if b.kind == blockParagraph || b.kind == blockQuote {
+			separator = "\\\n"
+		}
+		blocks = append(blocks, strings.Join(lines, separator))
+	}
+	return strings.Join(blocks, "\n\n")
+}
+
+// ToHTML converts slack mrkdwn to an html fragment. Mentions are rendered as `@name` / `#name` using lookup, which can be nil.
+func ToHTML(text string, lookup Lookup) string {
+	var blocks []string
+	for _, b := range splitBlocks(Parse(text)) {
+		var lines []string
+		for _, line := range b.lines {
+			if b.kind == blockCode {
+				lines = append(lines, html.EscapeString(line[0].Text))
+				continue
+			}
+			lines = append(lines, renderInline(line, htmlInline, lookup))
+		}
+
+		switch b.kind {
+		case blockCode:
+			blocks = append(blocks, "<pre><code>"+strings.Join(lines, "\n")+"</code></pre>")
+		case blockQuote:
+			blocks = append(blocks, "<blockquote>"+strings.Join(lines, "<br>\n")+"</blockquote>")
+		case blockBullet, blockOrdered:
+			tag := "ul"
+			if b.kind == blockOrdered {
+				tag = "ol"
+			}
+			blocks = append(blocks, fmt.Sprintf("<%s>\n<li>%s</li>\n</%s>", tag, strings.Join(lines, "</li>\n<li>"), tag))
+		default:
+			blocks = append(blocks, "<p>"+strings.Join(lines, "<br>\n")+"</p>")
+		}
+	}
+	return strings.Join(blocks, "\n")
+}
+
+// --------------------------------------------------------------------------------
+// blocks
+// --------------------------------------------------------------------------------
+
+type blockKind int
+
+const (
+	blockParagraph blockKind = iota
+	blockQuote
+	blockBullet
+	blockOrdered
+	blockCode
+)
+
+// block is a run of lines of the same kind; code blocks have a single line holding the code block token.
+type block struct {
+	kind    blockKind
+	lines   [][]Token
+	numbers []string
+}
+
+var (
+	orderedPrefix   = regexp.MustCompile(`^(\d+)[.)] `)
+	markdownHeading = regexp.MustCompile(`^#{1,6}( |$)`)
+	bulletPrefixes  = []string{"• ", "◦ ", "▪ "}
+)
+
+// splitBlocks splits tokens into lines, strips quote and list prefixes, and groups consecutive lines of the same kind.
+// Empty lines end a block.
+func splitBlocks(tokens []Token) []block {
+	var blocks []block
+	var current *block
+
+	add := func(kind blockKind, line []Token, number string) {
+		if current == nil || current.kind != kind || kind == blockCode {
+			blocks = append(blocks, block{kind: kind})
+			current = &blocks[len(blocks)-1]
+		}
+		current.lines = append(current.lines, line)
+		current.numbers = append(current.numbers, number)
+	}
+
+	for _, line := range splitLines(tokens) {
+		if len(line) == 0 {
+			current = nil
+			continue
+		}
+		if line[0].Type == TokenCodeBlock {
+			add(blockCode, line, "")
+			continue
+		}
+
+		kind, number := blockParagraph, ""
+		if line[0].Type == TokenText {
+			first := line[0].Text
+			switch {
+			case strings.HasPrefix(first, ">"):
+				kind, first = blockQuote, strings.TrimPrefix(strings.TrimPrefix(first, ">"), " ")
+			case orderedPrefix.MatchString(first):
+				match := orderedPrefix.FindStringSubmatch(first)
+				kind, number, first = blockOrdered, match[1], first[len(match[0]):]
+			default:
+				for _, prefix := range bulletPrefixes {
+					if strings.HasPrefix(first, prefix) {
+						kind, first = blockBullet, strings.TrimPrefix(first, prefix)
+					}
+				}
+			}
+
+			if kind != blockParagraph {
+				stripped := append([]Token{}, line...)
+				stripped[0].Text = first
+				if len(first) == 0 {
+					stripped = stripped[1:]
+				}
+				line = stripped
+			}
+		}
+		add(kind, line, number)
+	}
+	return blocks
+}
+
+// splitLines splits tokens at newlines in text; code blocks are always on a line of their own.
+func splitLines(tokens []Token) [][]Token {
+	lines := [][]Token{nil}
+	for _, token := range tokens {
+		switch token.Type {
+		case TokenText:
+			pieces := strings.Split(token.Text, "\n")
+			for index, piece := range pieces {
+				if index > 0 {
+					lines = append(lines, nil)
+				}
+				if len(piece) > 0 {
+					lines[len(lines)-1] = append(lines[len(lines)-1], Token{Type: TokenText, Text: piece})
+				}
+			}
+		case TokenCodeBlock:
+			if len(lines[len(lines)-1]) > 0 {
+				lines = append(lines, nil)
+			}
+			lines[len(lines)-1] = append(lines[len(lines)-1], token)
+			lines = append(lines, nil)
+		default:
+			lines[len(lines)-1] = append(lines[len(lines)-1], token)
+		}
+	}
+
+	// a trailing empty line after a code block isn't a paragraph break.
+	if len(lines) > 1 && len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// --------------------------------------------------------------------------------
+// inline formatting
+// --------------------------------------------------------------------------------
+
+// inlineFormat is how an output format renders inline markup.
+type inlineFormat struct {
+	// styles are the open and close markers for mrkdwn's `*bold*`, `_italic_` and `~strike~`.
+	styles  map[byte][2]string
+	escape  func(text string) string
+	code    func(code string) string
+	link    func(url, label string) string
+	mention func(text string) string
+}
+
+var markdownInline = inlineFormat{
+	styles: map[byte][2]string{'*': {"**", "**"}, '_': {"_", "_"}, '~': {"~~", "~~"}},
+	escape: markdownEscaper.Replace,
+	code:   markdownCode,
+	link: func(url, labelText string) string {
+		url = strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29").Replace(url)
+		if len(labelText) == 0 {
+			return "<" + url + ">"
+		}
+		return "[" + markdownEscaper.Replace(labelText) + "](" + url + ")"
+	},
+	mention: markdownEscaper.Replace,
+}
+
+var htmlInline = inlineFormat{
+	styles: map[byte][2]string{'*': {"<strong>", "</strong>"}, '_': {"<em>", "</em>"}, '~': {"<del>", "</del>"}},
+	escape: html.EscapeString,
+	code: func(code string) string {
+		return "<code>" + html.EscapeString(code) + "</code>"
+	},
+	link: func(url, labelText string) string {
+		if len(labelText) == 0 {
+			labelText = strings.TrimPrefix(url, "mailto:")
+		}
+		if !isSafeLink(url) {
+			return html.EscapeString(labelText)
+		}
+		return fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(url), html.EscapeString(labelText))
+	},
+	mention: html.EscapeString,
+}
+
+// isSafeLink returns if a link can be used as an html `href`; only http, https and mailto links can,
+// so i.e. a `javascript:` link is rendered as text.
+func isSafeLink(link string) bool {
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(parsed.Scheme) {
+	case "http", "https", "mailto":
+		return true
+	}
+	return false
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "*", `\*`, "_", `\_`, "~", `\~`, "`", "\\`", "[", `\[`, "]", `\]`, "<", `\<`, ">", `\>`,
+)
+
+// markdownCode returns an inline code span, fenced with more backticks than the code contains.
+func markdownCode(code string) string {
+	fence := "`"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+	if strings.HasPrefix(code, "`") || strings.HasSuffix(code, "`") {
+		code = " " + code + " "
+	}
+	return fence + code + fence
+}
+
+// markdownCodeBlock returns a fenced code block.
+func markdownCodeBlock(code string) string {
+	fence := "```"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+	return fence + "\n" + code + "\n" + fence
+}
+
+// renderInline renders a line of tokens.
+func renderInline(line []Token, f inlineFormat, lookup Lookup) string {
+	markers := pairStyleMarkers(line)
+
+	var output strings.Builder
+	for index, token := range line {
+		switch token.Type {
+		case TokenText:
+			start := 0
+			for offset := 0; offset < len(token.Text); offset++ {
+				isOpen, isMarker := markers[markerPosition{index, offset}]
+				if !isMarker {
+					continue
+				}
+				output.WriteString(f.escape(token.Text[start:offset]))
+				if isOpen {
+					output.WriteString(f.styles[token.Text[offset]][0])
+				} else {
+					output.WriteString(f.styles[token.Text[offset]][1])
+				}
+				start = offset + 1
+			}
+			output.WriteString(f.escape(token.Text[start:]))
+		case TokenUser, TokenChannel, TokenUserGroup, TokenSpecial:
+			output.WriteString(f.mention(mentionText(token, lookup)))
+		case TokenDate:
+			if len(token.URL) > 0 {
+				output.WriteString(f.link(token.URL, token.Label))
+			} else {
+				output.WriteString(f.escape(token.Label))
+			}
+		case TokenLink:
+			output.WriteString(f.link(token.URL, token.Label))
+		case TokenEmoji:
+			output.WriteString(f.escape(":" + token.Text + ":"))
+		case TokenCode:
+			output.WriteString(f.code(token.Text))
+		case TokenCodeBlock:
+			output.WriteString(f.code(token.Text))
+		}
+	}
+	return output.String()
+}
+
+type markerPosition struct {
+	token  int
+	offset int
+}
+
+// pairStyleMarkers finds the `*`, `_` and `~` characters in a line that open (true) or close (false) a style.
+// Like slack, a style opens after a word boundary and closes before one, so `snake_case` and `2*3*4` are left alone.
+func pairStyleMarkers(line []Token) map[markerPosition]bool {
+	paired := map[markerPosition]bool{}
+	open := map[byte]markerPosition{}
+
+	for index, token := range line {
+		if token.Type != TokenText {
+			continue
+		}
+		for offset := 0; offset < len(token.Text); offset++ {
+			c := token.Text[offset]
+			if c != '*' && c != '_' && c != '~' {
+				continue
+			}
+
+			before, after := charBefore(line, index, offset), charAfter(line, index, offset)
+			if opener, isOpen := open[c]; isOpen && !isSpace(before) && before != c && isBoundary(after) {
+				paired[opener] = true
+				paired[markerPosition{index, offset}] = false
+				delete(open, c)
+				continue
+			}
+			if _, isOpen := open[c]; !isOpen && isBoundary(before) && !isSpace(after) && after != c {
+				open[c] = markerPosition{index, offset}
+			}
+		}
+	}
+	return paired
+}
+
+// charBefore returns the character before a position in a line; other tokens read as a letter and the start of the line as 0.
+func charBefore(line []Token, index, offset int) byte {
+	if offset > 0 {
+		return line[index].Text[offset-1]
+	}
+	if index > 0 {
+		return 'a'
+	}
+	return 0
+}
+
+// charAfter returns the character after a position in a line; other tokens read as a letter and the end of the line as 0.
+func charAfter(line []Token, index, offset int) byte {
+	if offset+1 < len(line[index].Text) {
+		return line[index].Text[offset+1]
+	}
+	if index+1 < len(line) {
+		return 'a'
+	}
+	return 0
+}
+
+func isSpace(c byte) bool {
+	return c == 0 || c == ' ' || c == '\t' || c == '\n'
+}
+
+func isBoundary(c byte) bool {
+	return c < 0x80 && !isWordByte(c)
+}
@@ -0,0 +1,187 @@
+package format
+
+import (
+	"strings"
+)
+
+// TokenType is the type of a piece of parsed message text.
+type TokenType string
+
+// Token types
+const (
+	// TokenText is plain (unescaped) text.
+	TokenText TokenType = "text"
+	// TokenUser is a user mention, i.e. `<@U0KMCE0MC>`; the token ID is the user id.
+	TokenUser TokenType = "user"
+	// TokenChannel is a channel link, i.e. `<#C024BE7LR|general>`; the token ID is the channel id.
+	TokenChannel TokenType = "channel"
+	// TokenUserGroup is a user group mention, i.e. `<!subteam^S0614TZR7|@oncall>`; the token ID is the user group id.
+	TokenUserGroup TokenType = "usergroup"
+	// TokenSpecial is a special mention, i.e. `<!here>`; the token ID is the mention (`here`, `channel`, `everyone`).
+	TokenSpecial TokenType = "special"
+	// TokenDate is a date, i.e. `<!date^1392734382^{date_short}|Feb 18, 2014>`; the token ID is the unix time.
+	TokenDate TokenType = "date"
+	// TokenLink is a url, i.e. `<https://example.com|example>`.
+	TokenLink TokenType = "link"
+	// TokenEmoji is an emoji, i.e. `:shipit:`; the token Text is the emoji name.
+	TokenEmoji TokenType = "emoji"
+	// TokenCode is inline code; the token Text is the (unescaped) code.
+	TokenCode TokenType = "code"
+	// TokenCodeBlock is a preformatted block; the token Text is the (unescaped) contents.
+	TokenCodeBlock TokenType = "code_block"
+)
+
+// Token is a piece of parsed message text.
+type Token struct {
+	Type TokenType
+	// Text is the unescaped text of text and code tokens, and the name of emoji.
+	Text string
+	// ID is the id of a mentioned user, channel or user group, the name of a special mention or the unix time of a date.
+	ID string
+	// Label is the label of a link, mention or date as sent by slack (optional).
+	Label string
+	// URL is the url of a link (or a date that links somewhere).
+	URL string
+	// Raw is the markup the token was parsed from.
+	Raw string
+}
+
+// Parse splits message text (i.e. `Message.Text`) into tokens.
+func Parse(text string) []Token {
+	p := &parser{text: text}
+	p.parse()
+	return p.tokens
+}
+
+type parser struct {
+	text   string
+	tokens []Token
+	start  int
+}
+
+func (p *parser) parse() {
+	for index := 0; index < len(p.text); {
+		switch p.text[index] {
+		case '`':
+			if next, ok := p.code(index); ok {
+				index = next
+				continue
+			}
+		case '<':
+			if end := strings.IndexByte(p.text[index:], '>'); end > 0 {
+				p.flush(index)
+				raw := p.text[index : index+end+1]
+				p.tokens = append(p.tokens, markup(raw))
+				index = index + end + 1
+				p.start = index
+				continue
+			}
+		case ':':
+			if end := emojiEnd(p.text, index); end > 0 {
+				p.flush(index)
+				raw := p.text[index : end+1]
+				p.tokens = append(p.tokens, Token{Type: TokenEmoji, Text: raw[1 : len(raw)-1], Raw: raw})
+				index = end + 1
+				p.start = index
+				continue
+			}
+		}
+		index++
+	}
+	p.flush(len(p.text))
+}
+
+// code parses a code block or inline code span starting at index.
+func (p *parser) code(index int) (int, bool) {
+	if strings.HasPrefix(p.text[index:], "```") {
+		end := strings.Index(p.text[index+3:], "```")
+		if end < 0 {
+			return index, false
+		}
+		p.flush(index)
+		raw := p.text[index : index+3+end+3]
+		contents := strings.TrimPrefix(strings.TrimSuffix(raw[3:len(raw)-3], "\n"), "\n")
+		p.tokens = append(p.tokens, Token{Type: TokenCodeBlock, Text: Unescape(contents), Raw: raw})
+		p.start = index + len(raw)
+		return p.start, true
+	}
+
+	end := strings.IndexByte(p.text[index+1:], '`')
+	if end <= 0 || strings.Contains(p.text[index+1:index+1+end], "\n") {
+		return index, false
+	}
+	p.flush(index)
+	raw := p.text[index : index+end+2]
+	p.tokens = append(p.tokens, Token{Type: TokenCode, Text: Unescape(raw[1 : len(raw)-1]), Raw: raw})
+	p.start = index + len(raw)
+	return p.start, true
+}
+
+// flush emits the plain text between the last token and index.
+func (p *parser) flush(index int) {
+	if index > p.start {
+		raw := p.text[p.start:index]
+		if count := len(p.tokens); count > 0 && p.tokens[count-1].Type == TokenText {
+			p.tokens[count-1].Raw += raw
+			p.tokens[count-1].Text = Unescape(p.tokens[count-1].Raw)
+		} else {
+			p.tokens = append(p.tokens, Token{Type: TokenText, Text: Unescape(raw), Raw: raw})
+		}
+	}
+	p.start = index
+}
+
+// markup parses a `<...>` sequence.
+func markup(raw string) Token {
+	body := raw[1 : len(raw)-1]
+	target, labelText := body, ""
+	if pipe := strings.IndexByte(body, '|'); pipe >= 0 {
+		target, labelText = body[:pipe], Unescape(body[pipe+1:])
+	}
+
+	switch {
+	case strings.HasPrefix(target, "@"):
+		return Token{Type: TokenUser, ID: target[1:], Label: labelText, Raw: raw}
+	case strings.HasPrefix(target, "#"):
+		return Token{Type: TokenChannel, ID: target[1:], Label: labelText, Raw: raw}
+	case strings.HasPrefix(target, "!subteam^"):
+		return Token{Type: TokenUserGroup, ID: strings.TrimPrefix(target, "!subteam^"), Label: labelText, Raw: raw}
+	case strings.HasPrefix(target, "!date^"):
+		pieces := strings.Split(target, "^")
+		token := Token{Type: TokenDate, ID: pieces[1], Label: labelText, Raw: raw}
+		if len(pieces) > 3 {
+			token.URL = Unescape(pieces[3])
+		}
+		return token
+	case strings.HasPrefix(target, "!"):
+		return Token{Type: TokenSpecial, ID: target[1:], Label: labelText, Raw: raw}
+	default:
+		return Token{Type: TokenLink, URL: Unescape(target), Label: labelText, Raw: raw}
+	}
+}
+
+// emojiEnd returns the index of the colon closing an emoji that starts at index, or -1.
+// Emoji can't directly follow a letter or digit, so times like `12:30:45` aren't emoji.
+func emojiEnd(text string, index int) int {
+	if index > 0 && isWordByte(text[index-1]) {
+		return -1
+	}
+	for end := index + 1; end < len(text); end++ {
+		c := text[end]
+		switch {
+		case c == ':':
+			if end == index+1 {
+				return -1
+			}
+			return end
+		case c >= 'a' && c <= 'z', c >= '0' && c <= '9', c == '_', c == '-', c == '+', c == '\'':
+		default:
+			return -1
+		}
+	}
+	return -1
+}
+
+func isWordByte(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
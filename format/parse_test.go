@@ -0,0 +1,99 @@
+package format
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blendlabs/go-assert"
+)
+
+type testNames map[string]string
+
+func (tn testNames) UserName(userID string) string       { return tn[userID] }
+func (tn testNames) ChannelName(channelID string) string { return tn[channelID] }
+
+type testUserGroupNames struct{ testNames }
+
+func (tn testUserGroupNames) UserGroupName(userGroupID string) string {
+	return tn.testNames[userGroupID]
+}
+
+func TestParse(t *testing.T) {
+	a := assert.New(t)
+
+	tokens := Parse("hey <@U0KMCE0MC>, see <#C024BE7LR|general> &amp; <https://example.com?a=1&amp;b=2|the docs> :shipit: <!here>")
+	a.Len(tokens, 10)
+	a.Equal(Token{Type: TokenText, Text: "hey ", Raw: "hey "}, tokens[0])
+	a.Equal(Token{Type: TokenUser, ID: "U0KMCE0MC", Raw: "<@U0KMCE0MC>"}, tokens[1])
+	a.Equal(", see ", tokens[2].Text)
+	a.Equal(TokenChannel, tokens[3].Type)
+	a.Equal("C024BE7LR", tokens[3].ID)
+	a.Equal("general", tokens[3].Label)
+	a.Equal(" & ", tokens[4].Text)
+	a.Equal(TokenLink, tokens[5].Type)
+	a.Equal("https://example.com?a=1&b=2", tokens[5].URL)
+	a.Equal("the docs", tokens[5].Label)
+	a.Equal(Token{Type: TokenEmoji, Text: "shipit", Raw: ":shipit:"}, tokens[7])
+	a.Equal(Token{Type: TokenSpecial, ID: "here", Raw: "<!here>"}, tokens[9])
+}
+
+func TestParseCode(t *testing.T) {
+	a := assert.New(t)
+
+	tokens := Parse("run `make &lt;target&gt;` then\n```\n<@U1> :not_emoji:\n```")
+	a.Len(tokens, 4)
+	a.Equal(TokenCode, tokens[1].Type)
+	a.Equal("make <target>", tokens[1].Text)
+	a.Equal(" then\n", tokens[2].Text)
+	a.Equal(TokenCodeBlock, tokens[3].Type)
+	a.Equal("<@U1> :not_emoji:", tokens[3].Text)
+
+	tokens = Parse("a ` b")
+	a.Len(tokens, 1)
+	a.Equal("a ` b", tokens[0].Text)
+}
+
+func TestParseNotEmoji(t *testing.T) {
+	a := assert.New(t)
+
+	tokens := Parse("at 12:30:45 :: or :Capital:")
+	a.Len(tokens, 1)
+
+	tokens = Parse(":+1::skin-tone-2:")
+	a.Len(tokens, 2)
+	a.Equal("+1", tokens[0].Text)
+	a.Equal("skin-tone-2", tokens[1].Text)
+}
+
+func TestParseDate(t *testing.T) {
+	a := assert.New(t)
+
+	tokens := Parse(DateWithLink(time.Unix(1392734382, 0), DateShort, "https://example.com", "Feb 18, 2014"))
+	a.Len(tokens, 1)
+	a.Equal(TokenDate, tokens[0].Type)
+	a.Equal("1392734382", tokens[0].ID)
+	a.Equal("https://example.com", tokens[0].URL)
+	a.Equal("Feb 18, 2014", tokens[0].Label)
+}
+
+func TestPlainText(t *testing.T) {
+	a := assert.New(t)
+
+	text := "<@U1> moved <#C1> to <#C2|old-name> &lt;3 <mailto:bob@example.com> <!channel> <!here|@here> `x &amp; y`"
+	a.Equal("@bob moved #general to #random <3 bob@example.com @channel @here x & y", PlainText(text, testNames{"U1": "bob", "C1": "general", "C2": "random"}))
+	a.Equal("@U1 moved #C1 to #old-name <3 bob@example.com @channel @here x & y", PlainText(text, nil))
+}
+
+func TestUserGroupMentions(t *testing.T) {
+	a := assert.New(t)
+
+	tokens := Parse("paging <!subteam^S0614TZR7|@oncall> and <!subteam^S2>")
+	a.Len(tokens, 4)
+	a.Equal(Token{Type: TokenUserGroup, ID: "S0614TZR7", Label: "@oncall", Raw: "<!subteam^S0614TZR7|@oncall>"}, tokens[1])
+	a.Equal("S2", tokens[3].ID)
+
+	text := "paging <!subteam^S0614TZR7|@oncall> and <!subteam^S2>"
+	a.Equal("paging @oncall and @S2", PlainText(text, nil))
+	a.Equal("paging @oncall and @S2", PlainText(text, testNames{"S2": "ignored"}))
+	a.Equal("paging @oncall and @dba", PlainText(text, testUserGroupNames{testNames{"S2": "dba"}}))
+}
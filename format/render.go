@@ -0,0 +1,107 @@
+package format
+
+import (
+	"strings"
+)
+
+// Names resolves user and channel ids to names; it returns an empty string for unknown ids.
+// `*slack.State` implements it with the names cached from the rtm session.
+type Names interface {
+	UserName(userID string) string
+	ChannelName(channelID string) string
+}
+
+// UserGroupNames resolves user group ids to handles; Names that also implement it resolve user group mentions.
+type UserGroupNames interface {
+	UserGroupName(userGroupID string) string
+}
+
+// Lookup resolves the id of a mentioned user (TokenUser), channel (TokenChannel) or user group (TokenUserGroup) to a name.
+// It returns an empty string for unknown ids.
+type Lookup func(tokenType TokenType, id string) string
+
+// NamesLookup returns a Lookup backed by Names (i.e. `*slack.State`), or nil if names is nil.
+func NamesLookup(names Names) Lookup {
+	if names == nil {
+		return nil
+	}
+	return func(tokenType TokenType, id string) string {
+		switch tokenType {
+		case TokenUser:
+			return names.UserName(id)
+		case TokenChannel:
+			return names.ChannelName(id)
+		case TokenUserGroup:
+			if userGroups, ok := names.(UserGroupNames); ok {
+				return userGroups.UserGroupName(id)
+			}
+			return ""
+		default:
+			return ""
+		}
+	}
+}
+
+// PlainText renders message text as plain text, i.e. `<@U0KMCE0MC> see <#C024BE7LR>` becomes `@bob see #general`.
+// Names can be nil, in which case mentions use the label slack sent, or the id.
+func PlainText(text string, names Names) string {
+	return RenderPlainText(Parse(text), names)
+}
+
+// RenderPlainText renders parsed tokens as plain text (see PlainText).
+func RenderPlainText(tokens []Token, names Names) string {
+	lookup := NamesLookup(names)
+
+	var output strings.Builder
+	for _, token := range tokens {
+		switch token.Type {
+		case TokenUser, TokenChannel, TokenUserGroup, TokenSpecial, TokenDate:
+			output.WriteString(mentionText(token, lookup))
+		case TokenLink:
+			output.WriteString(linkText(token))
+		case TokenEmoji:
+			output.WriteString(":" + token.Text + ":")
+		default:
+			output.WriteString(token.Text)
+		}
+	}
+	return output.String()
+}
+
+// mentionText returns how a mention or date reads, i.e. `@bob`, `#general`, `@oncall`, `@here` or `Feb 18, 2014`.
+func mentionText(token Token, lookup Lookup) string {
+	switch token.Type {
+	case TokenUser, TokenUserGroup:
+		return "@" + mentionName(token, lookup)
+	case TokenChannel:
+		return "#" + mentionName(token, lookup)
+	case TokenSpecial:
+		if len(token.Label) > 0 {
+			return token.Label
+		}
+		return "@" + token.ID
+	default:
+		return token.Label
+	}
+}
+
+// mentionName returns the looked up name for a mention, then the label slack sent, then the id.
+func mentionName(token Token, lookup Lookup) string {
+	if lookup != nil {
+		if name := lookup(token.Type, token.ID); len(name) > 0 {
+			return name
+		}
+	}
+	if len(token.Label) > 0 {
+		return strings.TrimLeft(token.Label, "@#")
+	}
+	return token.ID
+}
+
+// linkText returns the label of a link, or the url without a `mailto:` prefix.
+func linkText(token Token) string {
+	if len(token.Label) > 0 {
+		return token.Label
+	}
+	return strings.TrimPrefix(token.URL, "mailto:")
+}
@@ -0,0 +1,111 @@
+package slack
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/blendlabs/go-exception"
+	"github.com/blendlabs/go-request"
+)
+
+// MessageRef refers to a message for reactions, pins and stars calls.
+func MessageRef(channelID string, ts Timestamp) ItemRef {
+	return ItemRef{Type: ItemTypeMessage, Channel: channelID, Timestamp: &ts}
+}
+
+// FileRef refers to a file for reactions, pins and stars calls.
+func FileRef(fileID string) ItemRef {
+	return ItemRef{Type: ItemTypeFile, File: fileID}
+}
+
+// FileCommentRef refers to a file comment for reactions, pins and stars calls.
+func FileCommentRef(fileCommentID string) ItemRef {
+	return ItemRef{Type: ItemTypeFileComment, FileComment: fileCommentID}
+}
+
+// ChannelRef refers to a channel, direct message or private channel for stars calls.
+func ChannelRef(channelID string) ItemRef {
+	return ItemRef{Type: ItemTypeChannel, Channel: channelID}
+}
+
+// ItemRef refers to a message, file, file comment or channel.
+// It is also the `item` of reaction rtm events, which only reference the item reacted to.
+type ItemRef struct {
+	Type        string     `json:"type"`
+	Channel     string     `json:"channel,omitempty"`
+	Timestamp   *Timestamp `json:"ts,omitempty"`
+	File        string     `json:"file,omitempty"`
+	FileComment string     `json:"file_comment,omitempty"`
+}
+
+// IsZero returns if the ref doesn't refer to anything.
+func (ir ItemRef) IsZero() bool {
+	return IsEmpty(ir.Channel) && IsEmpty(ir.File) && IsEmpty(ir.FileComment)
+}
+
+// withPostData adds the form values that identify the item to a request.
+func (ir ItemRef) withPostData(req *request.Request) (*request.Request, error) {
+	switch {
+	case !IsEmpty(ir.FileComment):
+		return req.WithPostData("file_comment", ir.FileComment), nil
+	case !IsEmpty(ir.File):
+		return req.WithPostData("file", ir.File), nil
+	case !IsEmpty(ir.Channel):
+		req = req.WithPostData("channel", ir.Channel)
+		if ir.Timestamp != nil {
+			req = req.WithPostData("timestamp", ir.Timestamp.String())
+		}
+		return req, nil
+	default:
+		return nil, exception.New("the item ref must have a `File`, `FileComment` or `Channel` (and `Timestamp`).")
+	}
+}
+
+// Ref returns a reference to the item.
+func (i Item) Ref() ItemRef {
+	ref := ItemRef{Type: i.Type, Channel: i.Channel, Timestamp: i.Timestamp}
+	if ref.Timestamp == nil && i.Message != nil {
+		ref.Timestamp = i.Message.Timestamp
+	}
+	if i.File != nil {
+		ref.File = i.File.ID
+	}
+	if i.Comment != nil {
+		ref.FileComment = i.Comment.ID
+	}
+	return ref
+}
+
+// UnmarshalJSON decodes an item, including the reference shaped items of reaction events
+// (where `file` and `file_comment` are ids instead of objects).
+func (i *Item) UnmarshalJSON(data []byte) error {
+	type item Item
+	var raw struct {
+		item
+		File        json.RawMessage `json:"file"`
+		FileComment string          `json:"file_comment"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*i = Item(raw.item)
+	if len(raw.File) > 0 && !bytes.Equal(raw.File, []byte("null")) {
+		if raw.File[0] == '"' {
+			var fileID string
+			if err := json.Unmarshal(raw.File, &fileID); err != nil {
+				return err
+			}
+			i.File = &File{ID: fileID}
+		} else {
+			i.File = &File{}
+			if err := json.Unmarshal(raw.File, i.File); err != nil {
+				return err
+			}
+		}
+	}
+	if !IsEmpty(raw.FileComment) && i.Comment == nil {
+		i.Comment = &FileComment{ID: raw.FileComment}
+	}
+	return nil
+}
@@ -1,5 +1,11 @@
 package slack
 
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+)
+
 // NewChatMessage instantiates a ChatMessage for use with ChatPostMessage.
 func NewChatMessage(channelID, text string) *ChatMessage {
 	return &ChatMessage{Channel: channelID, Text: text, Parse: OptionalString("full")}
@@ -8,11 +14,18 @@ func NewChatMessage(channelID, text string) *ChatMessage {
 // User is the struct that represents a Slack user.
 type User struct {
 	ID                string       `json:"id"`
+	TeamID            string       `json:"team_id"`
 	Name              string       `json:"name"`
-	Deleted           bool         `json:"deletd"`
+	RealName          string       `json:"real_name"`
+	Deleted           bool         `json:"deleted"`
 	Color             string       `json:"color"`
 	Profile           *UserProfile `json:"profile"`
+	TZ                string       `json:"tz"`
+	TZLabel           string       `json:"tz_label"`
+	TZOffset          int          `json:"tz_offset"`
+	Locale            string       `json:"locale"`
 	IsBot             bool         `json:"is_bot"`
+	IsAppUser         bool         `json:"is_app_user"`
 	IsAdmin           bool         `json:"is_admin"`
 	IsOwner           bool         `json:"is_owner"`
 	IsPrimaryOwner    bool         `json:"is_primary_owner"`
@@ -21,21 +34,105 @@ type User struct {
 	Has2FA            bool         `json:"has_2fa"`
 	TwoFactorType     string       `json:"two_factor_type"`
 	HasFiles          bool         `json:"has_files"`
+	Presence          string       `json:"presence,omitempty"`
+	Updated           Timestamp    `json:"updated"`
 }
 
 // UserProfile represents additional information about a Slack user.
 type UserProfile struct {
-	FirstName string `json:"first_name"`
-	LastName  string `json:"last_name"`
-	RealName  string `json:"real_name"`
-	Email     string `json:"email"`
-	Skype     string `json:"skype"`
-	Phone     string `json:"phone"`
-	Image24   string `json:"image_24"`
-	Image32   string `json:"image_32"`
-	Image48   string `json:"image_48"`
-	Image72   string `json:"image_72"`
-	Image192  string `json:"image_192"`
+	FirstName             string                      `json:"first_name"`
+	LastName              string                      `json:"last_name"`
+	RealName              string                      `json:"real_name"`
+	RealNameNormalized    string                      `json:"real_name_normalized"`
+	DisplayName           string                      `json:"display_name"`
+	DisplayNameNormalized string                      `json:"display_name_normalized"`
+	Title                 string                      `json:"title"`
+	Email                 string                      `json:"email"`
+	Skype                 string                      `json:"skype"`
+	Phone                 string                      `json:"phone"`
+	StatusText            string                      `json:"status_text"`
+	StatusEmoji           string                      `json:"status_emoji"`
+	StatusExpiration      int64                       `json:"status_expiration"`
+	Team                  string                      `json:"team"`
+	BotID                 string                      `json:"bot_id,omitempty"`
+	AvatarHash            string                      `json:"avatar_hash"`
+	Image24               string                      `json:"image_24"`
+	Image32               string                      `json:"image_32"`
+	Image48               string                      `json:"image_48"`
+	Image72               string                      `json:"image_72"`
+	Image192              string                      `json:"image_192"`
+	Image512              string                      `json:"image_512"`
+	Image1024             string                      `json:"image_1024"`
+	ImageOriginal         string                      `json:"image_original"`
+	Fields                map[string]UserProfileField `json:"fields"`
+}
+
+// UnmarshalJSON decodes a profile; slack sends `fields` as an empty array (or null) when a user has none,
+// which decodes to an empty map.
+func (up *UserProfile) UnmarshalJSON(data []byte) error {
+	type userProfile UserProfile
+	var raw struct {
+		userProfile
+		Fields json.RawMessage `json:"fields"`
+	}
+	// start from the current values so decoding into an existing profile updates it, like the default decoding.
+	raw.userProfile = userProfile(*up)
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*up = UserProfile(raw.userProfile)
+	fields := bytes.TrimSpace(raw.Fields)
+	if len(fields) == 0 {
+		return nil
+	}
+	if bytes.Equal(fields, []byte("null")) || fields[0] == '[' {
+		up.Fields = map[string]UserProfileField{}
+		return nil
+	}
+	return json.Unmarshal(fields, &up.Fields)
+}
+
+// UserProfileField is the value of a custom profile field, keyed by the field id (i.e. `Xf06054BBB`).
+type UserProfileField struct {
+	Value string `json:"value"`
+	Alt   string `json:"alt"`
+}
+
+// UserPresence is a user's presence, returned by users.getPresence.
+type UserPresence struct {
+	Presence        string    `json:"presence"`
+	Online          bool      `json:"online"`
+	AutoAway        bool      `json:"auto_away"`
+	ManualAway      bool      `json:"manual_away"`
+	ConnectionCount int       `json:"connection_count"`
+	LastActivity    Timestamp `json:"last_activity"`
+}
+
+// UserIdentity is the user and team returned by users.identity (for "Sign in with Slack" tokens).
+type UserIdentity struct {
+	User IdentityUser `json:"user"`
+	Team IdentityTeam `json:"team"`
+}
+
+// IdentityUser is the user in a UserIdentity; fields beyond the id and name depend on the token's scopes.
+type IdentityUser struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Image24  string `json:"image_24"`
+	Image32  string `json:"image_32"`
+	Image48  string `json:"image_48"`
+	Image72  string `json:"image_72"`
+	Image192 string `json:"image_192"`
+	Image512 string `json:"image_512"`
+}
+
+// IdentityTeam is the team in a UserIdentity.
+type IdentityTeam struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Domain string `json:"domain"`
 }
 
 // Channel is the struct that represents a Slack channel.
@@ -84,18 +181,23 @@ type Group struct {
 
 // InstantMessage represents a Slack instant message.
 type InstantMessage struct {
-	ID            string    `json:"id"`
-	IsIM          bool      `json:"is_im"`
-	User          string    `json:"user"`
-	Created       Timestamp `json:"created"`
-	IsUserDeleted bool      `json:"is_user_deleted"`
-	Latest        Message   `json:"latest"`
+	ID                 string    `json:"id"`
+	IsIM               bool      `json:"is_im"`
+	User               string    `json:"user"`
+	Created            Timestamp `json:"created"`
+	IsUserDeleted      bool      `json:"is_user_deleted"`
+	IsOpen             bool      `json:"is_open,omitempty"`
+	LastRead           Timestamp `json:"last_read"`
+	UnreadCount        int       `json:"unread_count"`
+	UnreadCountDisplay int       `json:"unread_count_display"`
+	Latest             Message   `json:"latest"`
 }
 
 // Icon represents a Slack icon.
 type Icon struct {
 	Image24  string `json:"image_24"`
 	Image32  string `json:"image_32"`
+	Image36  string `json:"image_36,omitempty"`
 	Image48  string `json:"image_48"`
 	Image72  string `json:"image_72"`
 	Image192 string `json:"image_192"`
@@ -103,9 +205,13 @@ type Icon struct {
 
 // Bot represents a Slack bot.
 type Bot struct {
-	ID    string `json:"id"`
-	Name  string `json:"name"`
-	Icons Icon   `json:"icons"`
+	ID      string    `json:"id"`
+	Name    string    `json:"name"`
+	Icons   Icon      `json:"icons"`
+	Deleted bool      `json:"deleted,omitempty"`
+	Updated Timestamp `json:"updated"`
+	AppID   string    `json:"app_id,omitempty"`
+	UserID  string    `json:"user_id,omitempty"`
 }
 
 // MessageType is an intermediate type used to figure out what final type to deserialize a message as.
@@ -117,18 +223,38 @@ type MessageType struct {
 // It is a mutt of a bunch of different types, events, pings/pongs, acks, actual messages.
 // As such not all fields are necessary for ~sending~ messages.
 type Message struct {
-	OK        *bool      `json:"ok,omitempty"`
-	ID        int64      `json:"id"`
-	ReplyTo   int64      `json:"reply_to"`
-	Type      Event      `json:"type"`
-	SubType   string     `json:"subtype,omitempty"`
-	Hidden    bool       `json:"hidden,omitempty"`
-	Timestamp *Timestamp `json:"ts,omitempty"`
-	Channel   string     `json:"channel,omitempty"`
-	User      string     `json:"user"`
-	Text      string     `json:"text"`
-	Reactions []Reaction `json:"reactions,omitempty"`
-	Error     *Error     `json:"error,omitempty"`
+	OK              *bool      `json:"ok,omitempty"`
+	ID              int64      `json:"id"`
+	ReplyTo         int64      `json:"reply_to"`
+	Type            Event      `json:"type"`
+	SubType         string     `json:"subtype,omitempty"`
+	Hidden          bool       `json:"hidden,omitempty"`
+	Timestamp       *Timestamp `json:"ts,omitempty"`
+	ThreadTimestamp *Timestamp `json:"thread_ts,omitempty"`
+	Channel         string     `json:"channel,omitempty"`
+	User            string     `json:"user"`
+	Text            string     `json:"text"`
+	Reactions       []Reaction `json:"reactions,omitempty"`
+	Item            *Item      `json:"item,omitempty"`
+	Error           *Error     `json:"error,omitempty"`
+
+	// BotID, Username, Attachments, Files, ReplyCount and ParentUserID are set on messages from history calls.
+	BotID        string                  `json:"bot_id,omitempty"`
+	Username     string                  `json:"username,omitempty"`
+	Attachments  []ChatMessageAttachment `json:"attachments,omitempty"`
+	Files        []File                  `json:"files,omitempty"`
+	ReplyCount   int                     `json:"reply_count,omitempty"`
+	ParentUserID string                  `json:"parent_user_id,omitempty"`
+
+	// Subteam is set on `subteam_created` and `subteam_updated` events.
+	Subteam *UserGroup `json:"subteam,omitempty"`
+	// SubteamID, AddedUsers and RemovedUsers are set on `subteam_members_changed` events.
+	SubteamID    string   `json:"subteam_id,omitempty"`
+	AddedUsers   []string `json:"added_users,omitempty"`
+	RemovedUsers []string `json:"removed_users,omitempty"`
+
+	// DNDStatus is set on `dnd_updated` and `dnd_updated_user` events.
+	DNDStatus *DNDStatus `json:"dnd_status,omitempty"`
 }
 
 // Error is a *sometimes* common datatype.
@@ -154,11 +280,27 @@ type Self struct {
 
 // Team represents information about a Slack team.
 type Team struct {
-	ID                string `json:"id"`
-	Name              string `json:"name"`
-	EmailDomain       string `json:"email_domain"`
-	MsgEditWindowMins int    `json:"msg_edit_window_mins"`
-	OverStorageLimit  bool   `json:"over_storage_limit"`
+	ID                string    `json:"id"`
+	Name              string    `json:"name"`
+	Domain            string    `json:"domain,omitempty"`
+	EmailDomain       string    `json:"email_domain"`
+	Icon              *TeamIcon `json:"icon,omitempty"`
+	EnterpriseID      string    `json:"enterprise_id,omitempty"`
+	EnterpriseName    string    `json:"enterprise_name,omitempty"`
+	MsgEditWindowMins int       `json:"msg_edit_window_mins"`
+	OverStorageLimit  bool      `json:"over_storage_limit"`
+}
+
+// TeamIcon is a team's icon in various sizes.
+type TeamIcon struct {
+	Image34      string `json:"image_34"`
+	Image44      string `json:"image_44"`
+	Image68      string `json:"image_68"`
+	Image88      string `json:"image_88"`
+	Image102     string `json:"image_102"`
+	Image132     string `json:"image_132"`
+	Image230     string `json:"image_230,omitempty"`
+	ImageDefault bool   `json:"image_default"`
 }
 
 // Session represents information about a Slack session and is returned by APIStart.
@@ -183,7 +325,8 @@ type basicResponse struct {
 // ChatMessage is a struct that represents an outgoing chat message for the Slack chat message api.
 type ChatMessage struct {
 	// Channel is the channelID you'll be posting to.
-	Channel string `json:"channel"`
+	// It is optional for incoming webhooks, which post to their configured channel.
+	Channel string `json:"channel,omitempty"`
 
 	// Text is the basic payload of the message.
 	Text string `json:"text"`
@@ -215,10 +358,38 @@ type ChatMessage struct {
 	// NOTES: as_user must be set to false or omitted.
 	IconEmoji *string `json:"icon_emoji,omitempty"`
 
+	// ThreadTimestamp is the `ts` of the parent message to reply to in a thread (optional).
+	ThreadTimestamp *Timestamp `json:"thread_ts,omitempty"`
+
+	// ReplyBroadcast also posts a thread reply to the channel (optional, default false).
+	ReplyBroadcast *bool `json:"reply_broadcast,omitempty"`
+
+	// Blocks are the block kit layout blocks for the message.
+	Blocks []Block `json:"blocks,omitempty"`
+
 	// Attachments are the chat message attachments for the message.
 	Attachments []ChatMessageAttachment `json:"attachments,omitempty"`
 }
 
+// Block is a block kit layout block (`section`, `divider`, `context`, `image` etc.) for a chat message.
+type Block struct {
+	Type     string      `json:"type"`
+	BlockID  string      `json:"block_id,omitempty"`
+	Text     *BlockText  `json:"text,omitempty"`
+	Fields   []BlockText `json:"fields,omitempty"`
+	Elements []BlockText `json:"elements,omitempty"`
+	ImageURL string      `json:"image_url,omitempty"`
+	AltText  string      `json:"alt_text,omitempty"`
+}
+
+// BlockText is a block kit text object.
+// Type is either `mrkdwn` or `plain_text`.
+type BlockText struct {
+	Type  string `json:"type"`
+	Text  string `json:"text"`
+	Emoji *bool  `json:"emoji,omitempty"`
+}
+
 // ChatMessageAttachment is a struct that represents an attachment to a chat message for the Slack chat message api.
 type ChatMessageAttachment struct {
 	Fallback      *string `json:"fallback,omitempty"`
@@ -284,6 +455,50 @@ type File struct {
 	CommentsCount      int        `json:"comments_count"`
 }
 
+// FileUpload describes a file for files.upload.
+type FileUpload struct {
+	// Channels are the channel ids to share the file to (optional).
+	Channels []string
+	// Filename is the name of the file.
+	Filename string
+	// FileType is a slack file type, i.e. `text` or `go` (optional, detected by default).
+	FileType string
+	// Title is the title of the file (optional).
+	Title string
+	// InitialComment is the text of the message the file is shared with (optional).
+	InitialComment string
+	// ThreadTimestamp shares the file as a thread reply (optional).
+	ThreadTimestamp *Timestamp
+}
+
+// FileComment is a comment on a file.
+type FileComment struct {
+	ID        string     `json:"id"`
+	Created   Timestamp  `json:"created"`
+	Timestamp Timestamp  `json:"timestamp"`
+	User      string     `json:"user"`
+	Comment   string     `json:"comment"`
+	Reactions []Reaction `json:"reactions,omitempty"`
+}
+
+// Item is a pinned, starred or reacted to message, file, file comment or channel.
+// It is returned by pins.list, stars.list and reactions.list, and is the `Item` of pin, star and reaction rtm events.
+type Item struct {
+	// Type is one of ItemTypeMessage, ItemTypeFile, ItemTypeFileComment, ItemTypeChannel, ItemTypeIM or ItemTypeGroup.
+	Type      string       `json:"type"`
+	Channel   string       `json:"channel,omitempty"`
+	Timestamp *Timestamp   `json:"ts,omitempty"`
+	Message   *Message     `json:"message,omitempty"`
+	File      *File        `json:"file,omitempty"`
+	Comment   *FileComment `json:"comment,omitempty"`
+
+	// Created and CreatedBy are set on pinned items.
+	Created   *Timestamp `json:"created,omitempty"`
+	CreatedBy string     `json:"created_by,omitempty"`
+	// DateCreate is set on starred items.
+	DateCreate *Timestamp `json:"date_create,omitempty"`
+}
+
 // Field represents a field on a Slack ChatMessageAttachment.
 type Field struct {
 	Title string `json:"title"`
@@ -323,10 +538,19 @@ type ChannelsHistoryResponse struct {
 	Messages           []Message `json:"messages"`
 }
 
-type channelsListResponse struct {
+type repliesResponse struct {
 	OK       bool      `json:"ok"`
 	Error    string    `json:"error"`
-	Channels []Channel `json:"channels"`
+	HasMore  bool      `json:"has_more"`
+	Messages []Message `json:"messages"`
+}
+
+// ChannelsListResponse is a response to channels.list.
+type ChannelsListResponse struct {
+	OK               bool             `json:"ok"`
+	Error            string           `json:"error"`
+	Channels         []Channel        `json:"channels"`
+	ResponseMetadata ResponseMetadata `json:"response_metadata"`
 }
 
 type channelsInfoResponse struct {
@@ -335,30 +559,495 @@ type channelsInfoResponse struct {
 	Channel *Channel `json:"channel"`
 }
 
+type groupResponse struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+	Group *Group `json:"group"`
+}
+
+// GroupsListResponse is a response to groups.list (and mpim.list).
+type GroupsListResponse struct {
+	OK               bool             `json:"ok"`
+	Error            string           `json:"error"`
+	Groups           []Group          `json:"groups"`
+	ResponseMetadata ResponseMetadata `json:"response_metadata"`
+}
+
+type imListResponse struct {
+	OK    bool             `json:"ok"`
+	Error string           `json:"error"`
+	IMs   []InstantMessage `json:"ims"`
+}
+
+type imOpenResponse struct {
+	OK          bool           `json:"ok"`
+	Error       string         `json:"error"`
+	NoOp        bool           `json:"no_op"`
+	AlreadyOpen bool           `json:"already_open"`
+	Channel     InstantMessage `json:"channel"`
+}
+
 type emojiResponse struct {
 	OK    bool              `json:"ok"`
 	Error string            `json:"error"`
 	Emoji map[string]string `json:"emoji"`
 }
 
-type usersListResponse struct {
+// UsersListResponse is a response to users.list.
+type UsersListResponse struct {
+	OK               bool             `json:"ok"`
+	Error            string           `json:"error"`
+	Users            []User           `json:"members"`
+	ResponseMetadata ResponseMetadata `json:"response_metadata"`
+}
+
+type usersInfoResponse struct {
 	OK    bool   `json:"ok"`
 	Error string `json:"error"`
-	Users []User `json:"members"`
+	User  *User  `json:"user"`
 }
 
-type usersInfoResponse struct {
+type usersGetPresenceResponse struct {
+	UserPresence
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+}
+
+type usersProfileResponse struct {
+	OK      bool         `json:"ok"`
+	Error   string       `json:"error"`
+	Profile *UserProfile `json:"profile"`
+}
+
+type usersIdentityResponse struct {
+	UserIdentity
 	OK    bool   `json:"ok"`
 	Error string `json:"error"`
-	User  *User  `json:"users"`
+}
+
+// UsersConversationsResponse is a response to users.conversations.
+type UsersConversationsResponse struct {
+	OK               bool             `json:"ok"`
+	Error            string           `json:"error"`
+	Channels         []Channel        `json:"channels"`
+	ResponseMetadata ResponseMetadata `json:"response_metadata"`
 }
 
 // ChatMessageResponse is a response to chat.postMessage
 type ChatMessageResponse struct {
 	OK          bool      `json:"ok"`
-	Timestamp   Timestamp `json:"timestamp"`
+	Channel     string    `json:"channel"`
+	Timestamp   Timestamp `json:"ts"`
 	Message     *Message  `json:"message,omitempty"`
 	File        *File     `json:"file,omitempty"`
 	FileComment *File     `json:"file_comment,omitempty"`
 	Error       string    `json:"error"`
 }
+
+// ResponseMetadata is the pagination metadata on cursor paginated responses.
+type ResponseMetadata struct {
+	NextCursor string `json:"next_cursor"`
+}
+
+// Paging is the pagination metadata on page numbered responses.
+type Paging struct {
+	Count int `json:"count"`
+	Total int `json:"total"`
+	Page  int `json:"page"`
+	Pages int `json:"pages"`
+}
+
+type fileResponse struct {
+	OK    bool   `json:"ok"`
+	File  *File  `json:"file"`
+	Error string `json:"error"`
+}
+
+type pinsListResponse struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+	Items []Item `json:"items"`
+}
+
+// StarsListResponse is a response to stars.list.
+type StarsListResponse struct {
+	OK               bool             `json:"ok"`
+	Error            string           `json:"error"`
+	Items            []Item           `json:"items"`
+	Paging           *Paging          `json:"paging,omitempty"`
+	ResponseMetadata ResponseMetadata `json:"response_metadata"`
+}
+
+// ReactionsGetResponse is a response to reactions.get.
+type ReactionsGetResponse struct {
+	OK      bool         `json:"ok"`
+	Error   string       `json:"error"`
+	Type    string       `json:"type"`
+	Channel string       `json:"channel,omitempty"`
+	Message *Message     `json:"message,omitempty"`
+	File    *File        `json:"file,omitempty"`
+	Comment *FileComment `json:"comment,omitempty"`
+}
+
+// Reactions returns the reactions on the message, file or file comment.
+func (rgr ReactionsGetResponse) Reactions() []Reaction {
+	switch {
+	case rgr.Comment != nil:
+		return rgr.Comment.Reactions
+	case rgr.File != nil && rgr.Message == nil:
+		return rgr.File.Reactions
+	case rgr.Message != nil:
+		return rgr.Message.Reactions
+	default:
+		return nil
+	}
+}
+
+// ReactionsListResponse is a response to reactions.list.
+type ReactionsListResponse struct {
+	OK               bool             `json:"ok"`
+	Error            string           `json:"error"`
+	Items            []Item           `json:"items"`
+	Paging           *Paging          `json:"paging,omitempty"`
+	ResponseMetadata ResponseMetadata `json:"response_metadata"`
+}
+
+// Reminder is a reminder created with reminders.add (or `/remind`).
+type Reminder struct {
+	ID         string              `json:"id"`
+	Creator    string              `json:"creator"`
+	User       string              `json:"user"`
+	Text       string              `json:"text"`
+	Recurring  bool                `json:"recurring"`
+	Recurrence *ReminderRecurrence `json:"recurrence,omitempty"`
+	// Time is when a one-off reminder fires; it isn't set on recurring reminders.
+	Time *Timestamp `json:"time,omitempty"`
+	// CompleteTS is when a one-off reminder was completed, or zero.
+	CompleteTS *Timestamp `json:"complete_ts,omitempty"`
+}
+
+// IsComplete returns if a one-off reminder has been marked complete.
+func (r Reminder) IsComplete() bool {
+	return r.CompleteTS != nil && r.CompleteTS.Time().Unix() > 0
+}
+
+// ReminderRecurrence is how often a reminder repeats.
+type ReminderRecurrence struct {
+	// Frequency is one of RecurrenceDaily, RecurrenceWeekly, RecurrenceMonthly or RecurrenceYearly.
+	Frequency string `json:"frequency"`
+	// Weekdays are lowercase day names (i.e. `monday`) for weekly reminders.
+	Weekdays []string `json:"weekdays,omitempty"`
+}
+
+type reminderResponse struct {
+	OK       bool      `json:"ok"`
+	Error    string    `json:"error"`
+	Reminder *Reminder `json:"reminder"`
+}
+
+type remindersListResponse struct {
+	OK        bool       `json:"ok"`
+	Error     string     `json:"error"`
+	Reminders []Reminder `json:"reminders"`
+}
+
+// UserGroup is a user group (a `subteam`), mentioned with its handle, i.e. `@oncall`.
+type UserGroup struct {
+	ID          string          `json:"id"`
+	TeamID      string          `json:"team_id"`
+	IsUserGroup bool            `json:"is_usergroup"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Handle      string          `json:"handle"`
+	IsExternal  bool            `json:"is_external"`
+	AutoType    string          `json:"auto_type,omitempty"`
+	DateCreate  Timestamp       `json:"date_create"`
+	DateUpdate  Timestamp       `json:"date_update"`
+	DateDelete  Timestamp       `json:"date_delete"`
+	CreatedBy   string          `json:"created_by"`
+	UpdatedBy   string          `json:"updated_by"`
+	DeletedBy   string          `json:"deleted_by,omitempty"`
+	Prefs       *UserGroupPrefs `json:"prefs,omitempty"`
+	// Users is only set when users are requested, i.e. by UsergroupsList with `includeUsers`.
+	Users []string `json:"users,omitempty"`
+	// UserCount is sent as a string by some methods and a number by others.
+	UserCount json.Number `json:"user_count,omitempty"`
+}
+
+// IsEnabled returns if the user group hasn't been disabled.
+func (ug UserGroup) IsEnabled() bool {
+	return ug.DateDelete.Time().Unix() <= 0
+}
+
+// UserGroupPrefs are the default channels and private channels of a user group.
+type UserGroupPrefs struct {
+	Channels []string `json:"channels"`
+	Groups   []string `json:"groups"`
+}
+
+type userGroupResponse struct {
+	OK        bool       `json:"ok"`
+	Error     string     `json:"error"`
+	UserGroup *UserGroup `json:"usergroup"`
+}
+
+type userGroupsListResponse struct {
+	OK         bool        `json:"ok"`
+	Error      string      `json:"error"`
+	UserGroups []UserGroup `json:"usergroups"`
+}
+
+type userGroupsUsersListResponse struct {
+	OK    bool     `json:"ok"`
+	Error string   `json:"error"`
+	Users []string `json:"users"`
+}
+
+type teamInfoResponse struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+	Team  *Team  `json:"team"`
+}
+
+// AccessLog is a user's logins from one ip address and user agent.
+type AccessLog struct {
+	UserID    string    `json:"user_id"`
+	Username  string    `json:"username"`
+	DateFirst Timestamp `json:"date_first"`
+	DateLast  Timestamp `json:"date_last"`
+	Count     int       `json:"count"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	ISP       string    `json:"isp"`
+	Country   string    `json:"country"`
+	Region    string    `json:"region"`
+}
+
+// TeamAccessLogsResponse is a response to team.accessLogs.
+type TeamAccessLogsResponse struct {
+	OK     bool        `json:"ok"`
+	Error  string      `json:"error"`
+	Logins []AccessLog `json:"logins"`
+	Paging *Paging     `json:"paging,omitempty"`
+}
+
+// BillableInfo is whether a user is billed for.
+type BillableInfo struct {
+	BillingActive bool `json:"billing_active"`
+}
+
+type teamBillableInfoResponse struct {
+	OK           bool                    `json:"ok"`
+	Error        string                  `json:"error"`
+	BillableInfo map[string]BillableInfo `json:"billable_info"`
+}
+
+// TeamProfile is the set of custom profile fields for a team.
+type TeamProfile struct {
+	Fields []TeamProfileField `json:"fields"`
+}
+
+// TeamProfileField is a custom profile field; users' values are in `UserProfile.Fields`, keyed by the field id.
+type TeamProfileField struct {
+	ID             string   `json:"id"`
+	Ordering       int      `json:"ordering"`
+	Label          string   `json:"label"`
+	Hint           string   `json:"hint"`
+	Type           string   `json:"type"`
+	PossibleValues []string `json:"possible_values,omitempty"`
+	IsHidden       bool     `json:"is_hidden,omitempty"`
+}
+
+type teamProfileGetResponse struct {
+	OK      bool         `json:"ok"`
+	Error   string       `json:"error"`
+	Profile *TeamProfile `json:"profile"`
+}
+
+type botsInfoResponse struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+	Bot   *Bot   `json:"bot"`
+}
+
+// DNDStatus is a user's do not disturb settings.
+// The snooze fields are only returned for the calling user.
+type DNDStatus struct {
+	DNDEnabled      bool      `json:"dnd_enabled"`
+	NextDNDStartTS  Timestamp `json:"next_dnd_start_ts"`
+	NextDNDEndTS    Timestamp `json:"next_dnd_end_ts"`
+	SnoozeEnabled   bool      `json:"snooze_enabled,omitempty"`
+	SnoozeEndTime   Timestamp `json:"snooze_endtime"`
+	SnoozeRemaining int       `json:"snooze_remaining,omitempty"`
+}
+
+// IsActive returns if notifications are paused at a given time, by a snooze or the do not disturb schedule.
+func (dnd DNDStatus) IsActive(at time.Time) bool {
+	if dnd.SnoozeEnabled && at.Before(dnd.SnoozeEndTime.Time()) {
+		return true
+	}
+	return dnd.DNDEnabled && !at.Before(dnd.NextDNDStartTS.Time()) && at.Before(dnd.NextDNDEndTS.Time())
+}
+
+type dndStatusResponse struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+	DNDStatus
+}
+
+type dndTeamInfoResponse struct {
+	OK    bool                 `json:"ok"`
+	Error string               `json:"error"`
+	Users map[string]DNDStatus `json:"users"`
+}
+
+// SearchMessagesResponse is a response to search.messages.
+type SearchMessagesResponse struct {
+	OK       bool           `json:"ok"`
+	Error    string         `json:"error"`
+	Query    string         `json:"query"`
+	Messages SearchMessages `json:"messages"`
+}
+
+// SearchFilesResponse is a response to search.files.
+type SearchFilesResponse struct {
+	OK    bool        `json:"ok"`
+	Error string      `json:"error"`
+	Query string      `json:"query"`
+	Files SearchFiles `json:"files"`
+}
+
+// SearchAllResponse is a response to search.all.
+type SearchAllResponse struct {
+	OK       bool           `json:"ok"`
+	Error    string         `json:"error"`
+	Query    string         `json:"query"`
+	Messages SearchMessages `json:"messages"`
+	Files    SearchFiles    `json:"files"`
+}
+
+// SearchMessages is a page of message search results.
+type SearchMessages struct {
+	Total      int              `json:"total"`
+	Matches    []SearchMessage  `json:"matches"`
+	Paging     Paging           `json:"paging"`
+	Pagination SearchPagination `json:"pagination"`
+}
+
+// SearchFiles is a page of file search results.
+type SearchFiles struct {
+	Total      int              `json:"total"`
+	Matches    []File           `json:"matches"`
+	Paging     Paging           `json:"paging"`
+	Pagination SearchPagination `json:"pagination"`
+}
+
+// SearchPagination is where a page of search results is in the results.
+type SearchPagination struct {
+	TotalCount int `json:"total_count"`
+	Page       int `json:"page"`
+	PerPage    int `json:"per_page"`
+	PageCount  int `json:"page_count"`
+	First      int `json:"first"`
+	Last       int `json:"last"`
+}
+
+// SearchMessage is a message matching a search, with up to two messages of context either side.
+type SearchMessage struct {
+	Type      string        `json:"type"`
+	IID       string        `json:"iid"`
+	Team      string        `json:"team,omitempty"`
+	Channel   SearchChannel `json:"channel"`
+	User      string        `json:"user"`
+	Username  string        `json:"username"`
+	Timestamp *Timestamp    `json:"ts"`
+	Text      string        `json:"text"`
+	Permalink string        `json:"permalink"`
+
+	Previous2 *SearchContextMessage `json:"previous_2,omitempty"`
+	Previous  *SearchContextMessage `json:"previous,omitempty"`
+	Next      *SearchContextMessage `json:"next,omitempty"`
+	Next2     *SearchContextMessage `json:"next_2,omitempty"`
+}
+
+// ContextBefore returns the messages before the match, oldest first.
+func (sm SearchMessage) ContextBefore() []SearchContextMessage {
+	return searchContext(sm.Previous2, sm.Previous)
+}
+
+// ContextAfter returns the messages after the match, oldest first.
+func (sm SearchMessage) ContextAfter() []SearchContextMessage {
+	return searchContext(sm.Next, sm.Next2)
+}
+
+func searchContext(messages ...*SearchContextMessage) []SearchContextMessage {
+	var context []SearchContextMessage
+	for _, m := range messages {
+		if m != nil {
+			context = append(context, *m)
+		}
+	}
+	return context
+}
+
+// SearchChannel is the channel of a search result.
+type SearchChannel struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	IsChannel bool   `json:"is_channel"`
+	IsGroup   bool   `json:"is_group"`
+	IsIM      bool   `json:"is_im"`
+	IsMPIM    bool   `json:"is_mpim"`
+	IsPrivate bool   `json:"is_private"`
+}
+
+// SearchContextMessage is a message before or after a search match.
+type SearchContextMessage struct {
+	Type      string     `json:"type"`
+	IID       string     `json:"iid"`
+	User      string     `json:"user"`
+	Username  string     `json:"username"`
+	Timestamp *Timestamp `json:"ts"`
+	Text      string     `json:"text"`
+}
+
+// ScheduledMessage is a message scheduled with chat.scheduleMessage.
+type ScheduledMessage struct {
+	ID          string    `json:"id"`
+	ChannelID   string    `json:"channel_id"`
+	PostAt      Timestamp `json:"post_at"`
+	DateCreated Timestamp `json:"date_created"`
+	Text        string    `json:"text"`
+}
+
+// ChatScheduleMessageResponse is a response to chat.scheduleMessage.
+type ChatScheduleMessageResponse struct {
+	OK                 bool      `json:"ok"`
+	Error              string    `json:"error"`
+	Channel            string    `json:"channel"`
+	ScheduledMessageID string    `json:"scheduled_message_id"`
+	PostAt             Timestamp `json:"post_at"`
+	Message            *Message  `json:"message,omitempty"`
+}
+
+// ChatScheduledMessagesListResponse is a response to chat.scheduledMessages.list.
+type ChatScheduledMessagesListResponse struct {
+	OK                bool               `json:"ok"`
+	Error             string             `json:"error"`
+	ScheduledMessages []ScheduledMessage `json:"scheduled_messages"`
+	ResponseMetadata  ResponseMetadata   `json:"response_metadata"`
+}
+
+// ChatPostEphemeralResponse is a response to chat.postEphemeral.
+type ChatPostEphemeralResponse struct {
+	OK               bool      `json:"ok"`
+	Error            string    `json:"error"`
+	MessageTimestamp Timestamp `json:"message_ts"`
+}
+
+type chatGetPermalinkResponse struct {
+	OK        bool   `json:"ok"`
+	Error     string `json:"error"`
+	Channel   string `json:"channel"`
+	Permalink string `json:"permalink"`
+}
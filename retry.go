@@ -0,0 +1,93 @@
+package slack
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	request "github.com/blendlabs/go-request"
+)
+
+const (
+	// DefaultRetryMaxAttempts is the number of times a rate limited request is attempted before giving up.
+	DefaultRetryMaxAttempts = 3
+
+	// DefaultRetryDelay is the delay used when slack rate limits a request but doesn't send a `Retry-After` header.
+	DefaultRetryDelay = 1 * time.Second
+
+	// DefaultRetryMaxDelay is the longest we'll wait on a single `Retry-After`.
+	DefaultRetryMaxDelay = 30 * time.Second
+)
+
+// DefaultRetryPolicy returns the retry policy used when one isn't provided.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: DefaultRetryMaxAttempts,
+		Delay:       DefaultRetryDelay,
+		MaxDelay:    DefaultRetryMaxDelay,
+	}
+}
+
+// RetryPolicy governs how requests that slack rate limits (HTTP 429) are retried.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	MaxAttempts int
+
+	// Delay is the wait between attempts if slack doesn't tell us how long to wait.
+	Delay time.Duration
+
+	// MaxDelay caps the `Retry-After` value slack sends us.
+	MaxDelay time.Duration
+
+	// sleep is swapped out in tests.
+	sleep func(time.Duration)
+}
+
+// Do runs an action, retrying it while slack responds with `429 Too Many Requests`.
+// The response meta from the last attempt is returned.
+func (rp RetryPolicy) Do(action func() (*request.ResponseMeta, error)) (*request.ResponseMeta, error) {
+	maxAttempts := rp.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	var meta *request.ResponseMeta
+	var err error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		meta, err = action()
+		if err != nil {
+			return meta, err
+		}
+		if !IsRateLimited(meta) || attempt == maxAttempts {
+			return meta, nil
+		}
+		rp.wait(rp.delay(meta))
+	}
+	return meta, err
+}
+
+func (rp RetryPolicy) delay(meta *request.ResponseMeta) time.Duration {
+	delay := rp.Delay
+	if meta != nil && meta.Headers != nil {
+		if retryAfter, err := strconv.Atoi(meta.Headers.Get("Retry-After")); err == nil && retryAfter >= 0 {
+			delay = time.Duration(retryAfter) * time.Second
+		}
+	}
+	if rp.MaxDelay > 0 && delay > rp.MaxDelay {
+		delay = rp.MaxDelay
+	}
+	return delay
+}
+
+func (rp RetryPolicy) wait(d time.Duration) {
+	if rp.sleep != nil {
+		rp.sleep(d)
+		return
+	}
+	time.Sleep(d)
+}
+
+// IsRateLimited returns if a response meta indicates slack rate limited the request.
+func IsRateLimited(meta *request.ResponseMeta) bool {
+	return meta != nil && meta.StatusCode == http.StatusTooManyRequests
+}
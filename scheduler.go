@@ -0,0 +1,148 @@
+package slack
+
+import (
+	"time"
+
+	exception "github.com/blendlabs/go-exception"
+)
+
+const (
+	// DefaultSchedulerInterval is how often the local scheduler checks for due posts.
+	DefaultSchedulerInterval = 1 * time.Second
+)
+
+// ScheduledPost is a recurring chat message posted by the client's local scheduler.
+type ScheduledPost struct {
+	ID        string
+	Schedule  Schedule
+	Message   ChatMessage
+	NextRun   time.Time
+	LastRun   time.Time
+	LastError error
+}
+
+// SchedulePost registers a chat message that is posted on a recurring schedule, i.e. a daily standup reminder:
+//
+//	schedule, _ := slack.ParseCron("30 9 * * 1-5")
+//	client.SchedulePost(schedule, slack.NewChatMessage(channelID, "standup time!"))
+//
+// Posts are sent with the web api (`chat.postMessage`) rather than the socket,
+// so they keep running while the rtm connection is cycled. The returned id can be passed to `Unschedule`.
+func (rtm *Client) SchedulePost(schedule Schedule, m *ChatMessage) (string, error) {
+	if schedule == nil {
+		return "", exception.New("`schedule` cannot be nil.")
+	}
+	if m == nil {
+		return "", exception.New("`m` cannot be nil.")
+	}
+
+	nextRun := schedule.Next(time.Now())
+	if nextRun.IsZero() {
+		return "", exception.New("schedule never fires.")
+	}
+
+	post := &ScheduledPost{
+		ID:       UUIDv4().ToShortString(),
+		Schedule: schedule,
+		Message:  *m,
+		NextRun:  nextRun,
+	}
+
+	rtm.schedulerLock.Lock()
+	rtm.scheduledPosts[post.ID] = post
+	rtm.schedulerLock.Unlock()
+
+	rtm.startScheduler()
+	return post.ID, nil
+}
+
+// Unschedule removes a scheduled post.
+func (rtm *Client) Unschedule(id string) {
+	rtm.schedulerLock.Lock()
+	defer rtm.schedulerLock.Unlock()
+	delete(rtm.scheduledPosts, id)
+}
+
+// ScheduledPosts returns a snapshot of the scheduled posts.
+func (rtm *Client) ScheduledPosts() []ScheduledPost {
+	rtm.schedulerLock.Lock()
+	defer rtm.schedulerLock.Unlock()
+
+	posts := make([]ScheduledPost, 0, len(rtm.scheduledPosts))
+	for _, post := range rtm.scheduledPosts {
+		posts = append(posts, *post)
+	}
+	return posts
+}
+
+//--------------------------------------------------------------------------------
+// INTERNAL METHODS
+//--------------------------------------------------------------------------------
+
+func (rtm *Client) startScheduler() {
+	rtm.schedulerLock.Lock()
+	defer rtm.schedulerLock.Unlock()
+
+	if rtm.schedulerDone != nil || len(rtm.scheduledPosts) == 0 {
+		return
+	}
+	rtm.schedulerDone = make(chan struct{})
+	rtm.schedulerExited = make(chan struct{})
+	go rtm.schedulerLoop(rtm.schedulerDone, rtm.schedulerExited)
+}
+
+// stopScheduler ends the scheduler loop and waits for it to exit.
+func (rtm *Client) stopScheduler() {
+	rtm.schedulerLock.Lock()
+	done, exited := rtm.schedulerDone, rtm.schedulerExited
+	rtm.schedulerDone, rtm.schedulerExited = nil, nil
+	rtm.schedulerLock.Unlock()
+
+	if done == nil {
+		return
+	}
+	close(done)
+	<-exited
+}
+
+func (rtm *Client) schedulerLoop(done, exited chan struct{}) {
+	defer close(exited)
+	for {
+		select {
+		case <-done:
+			return
+		case <-time.After(rtm.schedulerInterval):
+			rtm.runScheduledPosts(time.Now())
+		}
+	}
+}
+
+// runScheduledPosts posts any messages that are due; a post that was missed (i.e. while stopped) runs once.
+func (rtm *Client) runScheduledPosts(now time.Time) {
+	rtm.schedulerLock.Lock()
+	var due []*ScheduledPost
+	for id, post := range rtm.scheduledPosts {
+		if post.NextRun.After(now) {
+			continue
+		}
+		due = append(due, post)
+		post.LastRun = now
+		post.NextRun = post.Schedule.Next(now)
+		if post.NextRun.IsZero() {
+			delete(rtm.scheduledPosts, id)
+		}
+	}
+	rtm.schedulerLock.Unlock()
+
+	for _, post := range due {
+		message := post.Message
+		_, err := rtm.ChatPostMessage(&message)
+		if err != nil {
+			rtm.logf("runScheduledPosts() :: error => %v", err)
+		}
+
+		rtm.schedulerLock.Lock()
+		post.LastError = err
+		rtm.schedulerLock.Unlock()
+	}
+}
@@ -0,0 +1,72 @@
+package slack
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blendlabs/go-assert"
+)
+
+func TestClientSchedulePost(t *testing.T) {
+	a := assert.New(t)
+	defer ClearMockedResponses()
+	MockResponseFromFile("POST", "https://slack.com/api/chat.postMessage", 200, "testdata/chat.postMessage.json")
+
+	c := NewClient(getSlackToken(a))
+	c.SetDebug(false)
+	c.schedulerInterval = time.Hour
+
+	id, err := c.SchedulePost(Every(time.Minute), NewChatMessage("CTESTCHANNEL", "standup time!"))
+	a.Nil(err)
+	a.NotEmpty(id)
+	defer c.stopScheduler()
+
+	posts := c.ScheduledPosts()
+	a.Len(posts, 1)
+	a.True(posts[0].LastRun.IsZero())
+
+	c.runScheduledPosts(time.Now())
+	a.True(c.ScheduledPosts()[0].LastRun.IsZero())
+
+	now := time.Now().Add(2 * time.Minute)
+	c.runScheduledPosts(now)
+	posts = c.ScheduledPosts()
+	a.Equal(now, posts[0].LastRun)
+	a.Nil(posts[0].LastError)
+	a.Equal(now.Add(time.Minute), posts[0].NextRun)
+
+	c.Unschedule(id)
+	a.Empty(c.ScheduledPosts())
+
+	_, err = c.SchedulePost(nil, NewChatMessage("CTESTCHANNEL", "standup time!"))
+	a.NotNil(err)
+}
+
+func TestClientStopScheduler(t *testing.T) {
+	a := assert.New(t)
+
+	c := NewClient(getSlackToken(a))
+	c.SetDebug(false)
+	c.schedulerInterval = time.Hour
+
+	_, err := c.SchedulePost(Every(time.Minute), NewChatMessage("CTESTCHANNEL", "standup time!"))
+	a.Nil(err)
+
+	// stopping ends the loop without waiting for the next interval, and it can be started again.
+	for x := 0; x < 2; x++ {
+		stopped := make(chan struct{})
+		go func() {
+			c.stopScheduler()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-time.After(time.Second):
+			a.FailNow("timed out waiting for the scheduler to stop")
+		}
+		a.Nil(c.schedulerDone)
+		c.startScheduler()
+		a.NotNil(c.schedulerDone)
+	}
+	c.stopScheduler()
+}
@@ -0,0 +1,171 @@
+package slack
+
+import (
+	"strings"
+	"time"
+)
+
+// searchDateFormat is the date format of the `before:`, `after:` and `on:` search modifiers.
+const searchDateFormat = "2006-01-02"
+
+// NewSearchQuery returns a search query builder, starting with free text terms.
+func NewSearchQuery(terms ...string) *SearchQuery {
+	return new(SearchQuery).Terms(terms...)
+}
+
+// SearchQuery builds a search query with slack's search modifiers,
+// i.e. `NewSearchQuery("outage").In("incidents").From("bob").After(lastWeek).Has(SearchHasLink)`.
+type SearchQuery struct {
+	parts []string
+}
+
+// Terms adds free text terms.
+func (sq *SearchQuery) Terms(terms ...string) *SearchQuery {
+	for _, term := range terms {
+		if term = strings.TrimSpace(term); len(term) > 0 {
+			sq.parts = append(sq.parts, term)
+		}
+	}
+	return sq
+}
+
+// Phrase adds an exact phrase.
+func (sq *SearchQuery) Phrase(phrase string) *SearchQuery {
+	sq.parts = append(sq.parts, `"`+strings.Replace(phrase, `"`, "", -1)+`"`)
+	return sq
+}
+
+// Exclude excludes results containing a term.
+func (sq *SearchQuery) Exclude(term string) *SearchQuery {
+	sq.parts = append(sq.parts, "-"+term)
+	return sq
+}
+
+// In limits results to a channel by name (i.e. `general` or `#general`).
+func (sq *SearchQuery) In(channelName string) *SearchQuery {
+	sq.parts = append(sq.parts, "in:#"+strings.TrimPrefix(channelName, "#"))
+	return sq
+}
+
+// InChannelID limits results to a channel, private channel or direct message by id.
+func (sq *SearchQuery) InChannelID(channelID string) *SearchQuery {
+	sq.parts = append(sq.parts, "in:<#"+channelID+">")
+	return sq
+}
+
+// InDirectMessage limits results to the direct message with a user by name (i.e. `bob` or `@bob`).
+func (sq *SearchQuery) InDirectMessage(userName string) *SearchQuery {
+	sq.parts = append(sq.parts, "in:@"+strings.TrimPrefix(userName, "@"))
+	return sq
+}
+
+// From limits results to a sender by name (i.e. `bob` or `@bob`).
+func (sq *SearchQuery) From(userName string) *SearchQuery {
+	sq.parts = append(sq.parts, "from:@"+strings.TrimPrefix(userName, "@"))
+	return sq
+}
+
+// FromUserID limits results to a sender by id.
+func (sq *SearchQuery) FromUserID(userID string) *SearchQuery {
+	sq.parts = append(sq.parts, "from:<@"+userID+">")
+	return sq
+}
+
+// Before limits results to before a day.
+func (sq *SearchQuery) Before(day time.Time) *SearchQuery {
+	sq.parts = append(sq.parts, "before:"+day.Format(searchDateFormat))
+	return sq
+}
+
+// After limits results to after a day.
+func (sq *SearchQuery) After(day time.Time) *SearchQuery {
+	sq.parts = append(sq.parts, "after:"+day.Format(searchDateFormat))
+	return sq
+}
+
+// On limits results to a day.
+func (sq *SearchQuery) On(day time.Time) *SearchQuery {
+	sq.parts = append(sq.parts, "on:"+day.Format(searchDateFormat))
+	return sq
+}
+
+// Has limits results to messages with a link, star, pin or reaction (i.e. SearchHasLink or `:shipit:`).
+func (sq *SearchQuery) Has(what string) *SearchQuery {
+	sq.parts = append(sq.parts, "has:"+what)
+	return sq
+}
+
+// String returns the query.
+func (sq *SearchQuery) String() string {
+	return strings.Join(sq.parts, " ")
+}
+
+// SearchParameters are the sort and paging options of search calls; the zero value uses slack's defaults.
+type SearchParameters struct {
+	// Sort is SearchSortScore (the default) or SearchSortTimestamp.
+	Sort string
+	// SortDir is SearchSortDesc (the default) or SearchSortAsc.
+	SortDir string
+	// Highlight marks matching terms in results.
+	Highlight bool
+	// Count is the number of results per page, up to 100.
+	Count int
+	// Page is the page to return, starting at 1.
+	Page int
+}
+
+// SearchMessagesEach calls the handler with every message matching a query, fetching pages as needed,
+// until the handler returns false. `params.Page` is the page to start from.
+func (rtm *Client) SearchMessagesEach(query string, params *SearchParameters, handler func(SearchMessage) bool) error {
+	return searchEach(params, func(page SearchParameters) (*SearchPagination, bool, error) {
+		res, err := rtm.SearchMessages(query, &page)
+		if err != nil {
+			return nil, false, err
+		}
+		for _, match := range res.Messages.Matches {
+			if !handler(match) {
+				return nil, false, nil
+			}
+		}
+		return &res.Messages.Pagination, true, nil
+	})
+}
+
+// SearchFilesEach calls the handler with every file matching a query, fetching pages as needed,
+// until the handler returns false. `params.Page` is the page to start from.
+func (rtm *Client) SearchFilesEach(query string, params *SearchParameters, handler func(File) bool) error {
+	return searchEach(params, func(page SearchParameters) (*SearchPagination, bool, error) {
+		res, err := rtm.SearchFiles(query, &page)
+		if err != nil {
+			return nil, false, err
+		}
+		for _, match := range res.Files.Matches {
+			if !handler(match) {
+				return nil, false, nil
+			}
+		}
+		return &res.Files.Pagination, true, nil
+	})
+}
+
+// searchEach fetches pages until the last page, or until fetch returns false.
+func searchEach(params *SearchParameters, fetch func(SearchParameters) (*SearchPagination, bool, error)) error {
+	page := SearchParameters{}
+	if params != nil {
+		page = *params
+	}
+	if page.Page < 1 {
+		page.Page = 1
+	}
+
+	for {
+		pagination, more, err := fetch(page)
+		if err != nil || !more {
+			return err
+		}
+		if pagination.Page >= pagination.PageCount {
+			return nil
+		}
+		page.Page = pagination.Page + 1
+	}
+}
@@ -0,0 +1,26 @@
+package slack
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blendlabs/go-assert"
+)
+
+func TestSearchQuery(t *testing.T) {
+	a := assert.New(t)
+
+	day := time.Date(2017, 8, 22, 12, 0, 0, 0, time.UTC)
+	query := NewSearchQuery("db", " outage ").
+		Phrase(`failing "over"`).
+		Exclude("test").
+		In("#incidents").
+		From("@bob").
+		After(day).
+		Before(day.AddDate(0, 0, 7)).
+		Has(SearchHasLink)
+	a.Equal(`db outage "failing over" -test in:#incidents from:@bob after:2017-08-22 before:2017-08-29 has:link`, query.String())
+
+	query = NewSearchQuery().InChannelID("C1").FromUserID("U1").InDirectMessage("alice").On(day).Has(":shipit:")
+	a.Equal("in:<#C1> from:<@U1> in:@alice on:2017-08-22 has::shipit:", query.String())
+}
@@ -0,0 +1,2047 @@
+package slacktest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	slack "github.com/wcharczuk/go-slack"
+)
+
+// response is a generic api response body.
+type response map[string]interface{}
+
+func okResponse() response {
+	return response{"ok": true}
+}
+
+func errorResponse(code string) response {
+	return response{"ok": false, "error": code}
+}
+
+func defaultHandlers() map[string]APIHandler {
+	return map[string]APIHandler{
+		"api.test":                    apiTest,
+		"auth.test":                   authTest,
+		"rtm.start":                   rtmStart,
+		"rtm.connect":                 rtmConnect,
+		"bots.info":                   botsInfo,
+		"channels.archive":            channelsArchive,
+		"channels.create":             channelsCreate,
+		"channels.history":            channelsHistory,
+		"channels.info":               channelsInfo,
+		"channels.invite":             channelsInvite,
+		"channels.join":               channelsJoin,
+		"channels.kick":               channelsKick,
+		"channels.leave":              channelsLeave,
+		"channels.list":               channelsList,
+		"channels.mark":               channelsMark,
+		"channels.rename":             channelsRename,
+		"channels.replies":            channelsReplies,
+		"channels.setPurpose":         channelsSetPurpose,
+		"channels.setTopic":           channelsSetTopic,
+		"channels.unarchive":          channelsUnarchive,
+		"chat.delete":                 chatDelete,
+		"chat.deleteScheduledMessage": chatDeleteScheduledMessage,
+		"chat.getPermalink":           chatGetPermalink,
+		"chat.meMessage":              chatMeMessage,
+		"chat.postEphemeral":          chatPostEphemeral,
+		"chat.postMessage":            chatPostMessage,
+		"chat.scheduleMessage":        chatScheduleMessage,
+		"chat.scheduledMessages.list": chatScheduledMessagesList,
+		"chat.unfurl":                 chatUnfurl,
+		"chat.update":                 chatUpdate,
+		"dnd.endDnd":                  dndEndDnd,
+		"dnd.endSnooze":               dndEndSnooze,
+		"dnd.info":                    dndInfo,
+		"dnd.setSnooze":               dndSetSnooze,
+		"dnd.teamInfo":                dndTeamInfo,
+		"emoji.list":                  emojiList,
+		"files.upload":                filesUpload,
+		"groups.archive":              groupsArchive,
+		"groups.close":                groupsClose,
+		"groups.create":               groupsCreate,
+		"groups.history":              groupsHistory,
+		"groups.info":                 groupsInfo,
+		"groups.invite":               groupsInvite,
+		"groups.kick":                 groupsKick,
+		"groups.leave":                groupsLeave,
+		"groups.list":                 groupsList,
+		"groups.mark":                 groupsMark,
+		"groups.open":                 groupsOpen,
+		"groups.rename":               groupsRename,
+		"groups.replies":              groupsReplies,
+		"groups.setPurpose":           groupsSetPurpose,
+		"groups.setTopic":             groupsSetTopic,
+		"groups.unarchive":            groupsUnarchive,
+		"im.close":                    imClose,
+		"im.history":                  imHistory,
+		"im.list":                     imList,
+		"im.mark":                     imMark,
+		"im.open":                     imOpen,
+		"im.replies":                  imReplies,
+		"mpim.close":                  mpimClose,
+		"mpim.history":                mpimHistory,
+		"mpim.list":                   mpimList,
+		"mpim.mark":                   mpimMark,
+		"mpim.open":                   mpimOpen,
+		"mpim.replies":                mpimReplies,
+		"pins.add":                    pinsAdd,
+		"pins.list":                   pinsList,
+		"pins.remove":                 pinsRemove,
+		"reactions.add":               reactionsAdd,
+		"reactions.get":               reactionsGet,
+		"reactions.list":              reactionsList,
+		"reactions.remove":            reactionsRemove,
+		"reminders.add":               remindersAdd,
+		"reminders.complete":          remindersComplete,
+		"reminders.delete":            remindersDelete,
+		"reminders.info":              remindersInfo,
+		"reminders.list":              remindersList,
+		"search.messages":             searchMessages,
+		"stars.add":                   starsAdd,
+		"stars.list":                  starsList,
+		"stars.remove":                starsRemove,
+		"team.billableInfo":           teamBillableInfo,
+		"team.info":                   teamInfo,
+		"usergroups.create":           usergroupsCreate,
+		"usergroups.disable":          usergroupsDisable,
+		"usergroups.enable":           usergroupsEnable,
+		"usergroups.list":             usergroupsList,
+		"usergroups.update":           usergroupsUpdate,
+		"usergroups.users.list":       usergroupsUsersList,
+		"usergroups.users.update":     usergroupsUsersUpdate,
+		"users.getPresence":           usersGetPresence,
+		"users.info":                  usersInfo,
+		"users.list":                  usersList,
+		"users.lookupByEmail":         usersLookupByEmail,
+		"users.profile.get":           usersProfileGet,
+		"users.profile.set":           usersProfileSet,
+		"users.setPresence":           usersSetPresence,
+	}
+}
+
+func apiTest(s *Server, form url.Values) interface{} {
+	args := response{}
+	for key := range form {
+		args[key] = form.Get(key)
+	}
+	return response{"ok": true, "args": args}
+}
+
+func authTest(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+	return slack.AuthTestResponse{
+		OK:     true,
+		URL:    s.URL() + "/",
+		Team:   w.Team.Name,
+		User:   w.Self.Name,
+		TeamID: w.Team.ID,
+		UserID: w.Self.ID,
+	}
+}
+
+func rtmStart(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+	return w.session(s.socketURL())
+}
+
+func rtmConnect(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+	return response{
+		"ok":   true,
+		"url":  s.socketURL(),
+		"self": response{"id": w.Self.ID, "name": w.Self.Name},
+		"team": response{"id": w.Team.ID, "name": w.Team.Name, "domain": w.Team.Name},
+	}
+}
+
+func botsInfo(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	bot, hasBot := w.Bots[form.Get("bot")]
+	if !hasBot {
+		return errorResponse("bot_not_found")
+	}
+	return response{"ok": true, "bot": bot}
+}
+
+func channelsArchive(s *Server, form url.Values) interface{} {
+	return setChannelArchived(s, form, true)
+}
+
+func channelsUnarchive(s *Server, form url.Values) interface{} {
+	return setChannelArchived(s, form, false)
+}
+
+func setChannelArchived(s *Server, form url.Values, archived bool) interface{} {
+	w := s.Workspace
+	w.Lock()
+	channel, hasChannel := w.Channels[form.Get("channel")]
+	if !hasChannel {
+		w.Unlock()
+		return errorResponse(slack.ErrorChannelNotFound)
+	}
+	if channel.IsArchived == archived {
+		w.Unlock()
+		if archived {
+			return errorResponse(slack.ErrorAlreadyArchived)
+		}
+		return errorResponse(slack.ErrorNotArchived)
+	}
+	if channel.IsGeneral {
+		w.Unlock()
+		return errorResponse("cant_archive_general")
+	}
+	channel.IsArchived = archived
+	if !archived && !containsString(channel.Members, w.Self.ID) {
+		channel.Members = append(channel.Members, w.Self.ID)
+		channel.IsMember = true
+	}
+	w.Unlock()
+
+	eventType := slack.EventChannelArchive
+	if !archived {
+		eventType = slack.EventChannelUnArchive
+	}
+	s.SendEvent(slack.Message{Type: eventType, Channel: form.Get("channel"), User: w.Self.ID})
+	return okResponse()
+}
+
+func channelsCreate(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+
+	name := form.Get("name")
+	if len(name) == 0 {
+		w.Unlock()
+		return errorResponse(slack.ErrorInvalidName)
+	}
+	if w.conversationNamed(name) {
+		w.Unlock()
+		return errorResponse(slack.ErrorNameTaken)
+	}
+	channel := w.addChannel(name)
+	w.Unlock()
+
+	s.SendEvent(response{"type": slack.EventChannelCreated, "channel": response{"id": channel.ID, "name": channel.Name, "created": channel.Created, "creator": channel.Creator}})
+	return response{"ok": true, "channel": channel}
+}
+
+func channelsHistory(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	if _, hasChannel := w.Channels[form.Get("channel")]; !hasChannel {
+		return errorResponse(slack.ErrorChannelNotFound)
+	}
+	return w.history(form)
+}
+
+func channelsInfo(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	channel, hasChannel := w.Channels[form.Get("channel")]
+	if !hasChannel {
+		return errorResponse(slack.ErrorChannelNotFound)
+	}
+	return response{"ok": true, "channel": channel}
+}
+
+func channelsInvite(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	channel, hasChannel := w.Channels[form.Get("channel")]
+	if !hasChannel {
+		return errorResponse(slack.ErrorChannelNotFound)
+	}
+	userID := form.Get("user")
+	if _, hasUser := w.Users[userID]; !hasUser {
+		return errorResponse(slack.ErrorUserNotFound)
+	}
+	if userID == w.Self.ID {
+		return errorResponse(slack.ErrorCantInviteSelf)
+	}
+	for _, member := range channel.Members {
+		if member == userID {
+			return errorResponse(slack.ErrorAlreadyInChannel)
+		}
+	}
+	channel.Members = append(channel.Members, userID)
+	return response{"ok": true, "channel": channel}
+}
+
+func channelsJoin(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+
+	name := strings.TrimPrefix(form.Get("name"), "#")
+	if len(name) == 0 {
+		w.Unlock()
+		return errorResponse(slack.ErrorInvalidName)
+	}
+	for _, id := range w.channelIDs() {
+		channel := w.Channels[id]
+		if channel.Name != name {
+			continue
+		}
+		if channel.IsArchived {
+			w.Unlock()
+			return errorResponse(slack.ErrorChannelIsArchived)
+		}
+		alreadyInChannel := containsString(channel.Members, w.Self.ID)
+		if !alreadyInChannel {
+			channel.Members = append(channel.Members, w.Self.ID)
+			channel.IsMember = true
+		}
+		joined := *channel
+		w.Unlock()
+		return response{"ok": true, "already_in_channel": alreadyInChannel, "channel": joined}
+	}
+	if w.conversationNamed(name) {
+		w.Unlock()
+		return errorResponse(slack.ErrorNameTaken)
+	}
+	channel := w.addChannel(name)
+	w.Unlock()
+
+	s.SendEvent(response{"type": slack.EventChannelCreated, "channel": response{"id": channel.ID, "name": channel.Name, "created": channel.Created, "creator": channel.Creator}})
+	return response{"ok": true, "channel": channel}
+}
+
+func channelsKick(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	channel, hasChannel := w.Channels[form.Get("channel")]
+	if !hasChannel {
+		return errorResponse(slack.ErrorChannelNotFound)
+	}
+	userID := form.Get("user")
+	if _, hasUser := w.Users[userID]; !hasUser {
+		return errorResponse(slack.ErrorUserNotFound)
+	}
+	if userID == w.Self.ID {
+		return errorResponse("cant_kick_self")
+	}
+	if channel.IsGeneral {
+		return errorResponse("cant_kick_from_general")
+	}
+	if !containsString(channel.Members, userID) {
+		return errorResponse("not_in_channel")
+	}
+	channel.Members = difference(channel.Members, []string{userID})
+	return okResponse()
+}
+
+func channelsLeave(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	channel, hasChannel := w.Channels[form.Get("channel")]
+	if !hasChannel {
+		w.Unlock()
+		return errorResponse(slack.ErrorChannelNotFound)
+	}
+	if channel.IsGeneral {
+		w.Unlock()
+		return errorResponse("cant_leave_general")
+	}
+	if !containsString(channel.Members, w.Self.ID) {
+		w.Unlock()
+		return response{"ok": true, "not_in_channel": true}
+	}
+	channel.Members = difference(channel.Members, []string{w.Self.ID})
+	channel.IsMember = false
+	w.Unlock()
+
+	s.SendEvent(slack.Message{Type: slack.EventChannelLeft, Channel: form.Get("channel")})
+	return okResponse()
+}
+
+func channelsList(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	channels := []slack.Channel{}
+	for _, id := range w.channelIDs() {
+		channel := w.Channels[id]
+		if channel.IsArchived && form.Get("exclude_archived") == "1" {
+			continue
+		}
+		channels = append(channels, *channel)
+	}
+	start, end, next, ok := page(form, len(channels))
+	if !ok {
+		return errorResponse(slack.ErrorInvalidCursor)
+	}
+	return response{"ok": true, "channels": channels[start:end], "response_metadata": slack.ResponseMetadata{NextCursor: next}}
+}
+
+func channelsMark(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	channel, hasChannel := w.Channels[form.Get("channel")]
+	if !hasChannel {
+		return errorResponse(slack.ErrorChannelNotFound)
+	}
+	var ts slack.Timestamp
+	ts.UnmarshalJSON([]byte(form.Get("ts")))
+	channel.LastRead = ts
+	return okResponse()
+}
+
+func channelsRename(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	channel, hasChannel := w.Channels[form.Get("channel")]
+	if !hasChannel {
+		w.Unlock()
+		return errorResponse(slack.ErrorChannelNotFound)
+	}
+	name := form.Get("name")
+	if len(name) == 0 {
+		w.Unlock()
+		return errorResponse(slack.ErrorInvalidName)
+	}
+	if name != channel.Name && w.conversationNamed(name) {
+		w.Unlock()
+		return errorResponse(slack.ErrorNameTaken)
+	}
+	channel.Name = name
+	renamed := response{"id": channel.ID, "is_channel": true, "name": channel.Name, "created": channel.Created}
+	w.Unlock()
+
+	s.SendEvent(response{"type": slack.EventChannelRename, "channel": renamed})
+	return response{"ok": true, "channel": renamed}
+}
+
+func channelsReplies(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	if _, hasChannel := w.Channels[form.Get("channel")]; !hasChannel {
+		return errorResponse(slack.ErrorChannelNotFound)
+	}
+	return w.replies(form)
+}
+
+func channelsSetPurpose(s *Server, form url.Values) interface{} {
+	return setChannelTopic(s, form, "purpose")
+}
+
+func channelsSetTopic(s *Server, form url.Values) interface{} {
+	return setChannelTopic(s, form, "topic")
+}
+
+func setChannelTopic(s *Server, form url.Values, field string) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	channel, hasChannel := w.Channels[form.Get("channel")]
+	if !hasChannel {
+		return errorResponse(slack.ErrorChannelNotFound)
+	}
+	topic := &slack.Topic{Value: form.Get(field), Creator: w.Self.ID, LastSet: slack.NewTimestamp(w.now())}
+	if field == "purpose" {
+		channel.Purpose = topic
+	} else {
+		channel.Topic = topic
+	}
+	return response{"ok": true, field: topic.Value}
+}
+
+func chatDelete(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	channelID := form.Get("channel")
+	index, found := w.findMessage(channelID, form.Get("ts"))
+	if !found {
+		return errorResponse(slack.ErrorMessageNotFound)
+	}
+	w.Messages[channelID] = append(w.Messages[channelID][:index], w.Messages[channelID][index+1:]...)
+	return response{"ok": true, "channel": channelID, "ts": form.Get("ts")}
+}
+
+func chatPostMessage(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	channelID := form.Get("channel")
+	if !w.hasConversation(channelID) {
+		w.Unlock()
+		return errorResponse(slack.ErrorChannelNotFound)
+	}
+	if len(form.Get("text")) == 0 && len(form.Get("attachments")) == 0 && len(form.Get("blocks")) == 0 {
+		w.Unlock()
+		return errorResponse(slack.ErrorNoText)
+	}
+
+	m := slack.Message{Type: slack.EventMessage, User: w.Self.ID, Text: form.Get("text")}
+	if threadTS := form.Get("thread_ts"); len(threadTS) > 0 {
+		var ts slack.Timestamp
+		ts.UnmarshalJSON([]byte(threadTS))
+		m.ThreadTimestamp = &ts
+	}
+	m = w.addMessage(channelID, m)
+	w.Unlock()
+
+	// slack echoes the bot's own messages over the socket.
+	s.SendEvent(m)
+	return slack.ChatMessageResponse{OK: true, Channel: channelID, Timestamp: *m.Timestamp, Message: &m}
+}
+
+func chatMeMessage(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	channelID := form.Get("channel")
+	if !w.hasConversation(channelID) {
+		return errorResponse(slack.ErrorChannelNotFound)
+	}
+	m := w.addMessage(channelID, slack.Message{Type: slack.EventMessage, SubType: string(slack.EventSubtypeMeMessage), User: w.Self.ID, Text: form.Get("text")})
+	return response{"ok": true, "channel": channelID, "ts": m.Timestamp}
+}
+
+func chatPostEphemeral(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	if !w.hasConversation(form.Get("channel")) {
+		return errorResponse(slack.ErrorChannelNotFound)
+	}
+	if _, hasUser := w.Users[form.Get("user")]; !hasUser {
+		return errorResponse(slack.ErrorUserNotFound)
+	}
+	// ephemeral messages aren't part of the channel history.
+	return response{"ok": true, "message_ts": w.nextTimestamp()}
+}
+
+func chatGetPermalink(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	channelID := form.Get("channel")
+	ts := form.Get("message_ts")
+	if _, found := w.findMessage(channelID, ts); !found {
+		return errorResponse(slack.ErrorMessageNotFound)
+	}
+	permalink := fmt.Sprintf("%s/archives/%s/p%s", s.URL(), channelID, strings.Replace(ts, ".", "", -1))
+	return response{"ok": true, "channel": channelID, "permalink": permalink}
+}
+
+func chatUnfurl(s *Server, form url.Values) interface{} {
+	var unfurls map[string]interface{}
+	if err := json.Unmarshal([]byte(form.Get("unfurls")), &unfurls); err != nil {
+		return errorResponse(slack.ErrorInvalidPayload)
+	}
+	return okResponse()
+}
+
+func chatUpdate(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	channelID := form.Get("channel")
+	index, found := w.findMessage(channelID, form.Get("ts"))
+	if !found {
+		return errorResponse(slack.ErrorMessageNotFound)
+	}
+	m := &w.Messages[channelID][index]
+	m.Text = form.Get("text")
+	return slack.ChatMessageResponse{OK: true, Channel: channelID, Timestamp: *m.Timestamp, Message: m}
+}
+
+func chatScheduleMessage(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	channelID := form.Get("channel")
+	if _, hasChannel := w.Channels[channelID]; !hasChannel {
+		return errorResponse(slack.ErrorChannelNotFound)
+	}
+	var postAt slack.Timestamp
+	postAt.UnmarshalJSON([]byte(form.Get("post_at")))
+	if !postAt.Time().After(w.now()) {
+		return errorResponse("time_in_past")
+	}
+
+	w.sequence++
+	scheduled := slack.ScheduledMessage{
+		ID:          fmt.Sprintf("Q%08d", w.sequence),
+		ChannelID:   channelID,
+		PostAt:      postAt,
+		DateCreated: slack.NewTimestamp(w.now()),
+		Text:        form.Get("text"),
+	}
+	w.ScheduledMessages[scheduled.ID] = scheduled
+	return slack.ChatScheduleMessageResponse{
+		OK:                 true,
+		Channel:            channelID,
+		ScheduledMessageID: scheduled.ID,
+		PostAt:             postAt,
+		Message:            &slack.Message{Type: slack.EventMessage, User: w.Self.ID, Text: scheduled.Text},
+	}
+}
+
+func chatScheduledMessagesList(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	scheduled := []slack.ScheduledMessage{}
+	for _, message := range w.ScheduledMessages {
+		if channelID := form.Get("channel"); len(channelID) > 0 && message.ChannelID != channelID {
+			continue
+		}
+		scheduled = append(scheduled, message)
+	}
+	return slack.ChatScheduledMessagesListResponse{OK: true, ScheduledMessages: scheduled}
+}
+
+func chatDeleteScheduledMessage(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	id := form.Get("scheduled_message_id")
+	if scheduled, hasScheduled := w.ScheduledMessages[id]; !hasScheduled || scheduled.ChannelID != form.Get("channel") {
+		return errorResponse("invalid_scheduled_message_id")
+	}
+	delete(w.ScheduledMessages, id)
+	return okResponse()
+}
+
+func dndEndDnd(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	status := w.DND[w.Self.ID]
+	status.DNDEnabled = false
+	w.DND[w.Self.ID] = status
+	return okResponse()
+}
+
+func dndEndSnooze(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	status := w.DND[w.Self.ID]
+	if !status.SnoozeEnabled {
+		return errorResponse("snooze_not_active")
+	}
+	status.SnoozeEnabled = false
+	status.SnoozeEndTime = slack.Timestamp{}
+	status.SnoozeRemaining = 0
+	w.DND[w.Self.ID] = status
+	return dndResponse(status)
+}
+
+func dndInfo(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	userID := form.Get("user")
+	if len(userID) == 0 {
+		userID = w.Self.ID
+	}
+	if _, hasUser := w.Users[userID]; !hasUser {
+		return errorResponse(slack.ErrorUserNotFound)
+	}
+	status := w.DND[userID]
+	if userID != w.Self.ID {
+		status.SnoozeEnabled, status.SnoozeEndTime, status.SnoozeRemaining = false, slack.Timestamp{}, 0
+	} else if status.SnoozeEnabled {
+		status.SnoozeRemaining = int(status.SnoozeEndTime.Time().Sub(w.now()).Seconds())
+	}
+	return dndResponse(status)
+}
+
+func dndSetSnooze(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	minutes, err := strconv.Atoi(form.Get("num_minutes"))
+	if err != nil || minutes <= 0 {
+		return errorResponse("invalid_num_minutes")
+	}
+	status := w.DND[w.Self.ID]
+	status.SnoozeEnabled = true
+	status.SnoozeEndTime = slack.NewTimestamp(w.now().Add(time.Duration(minutes) * time.Minute))
+	status.SnoozeRemaining = minutes * 60
+	w.DND[w.Self.ID] = status
+	return response{"ok": true, "snooze_enabled": true, "snooze_endtime": status.SnoozeEndTime, "snooze_remaining": status.SnoozeRemaining}
+}
+
+func dndTeamInfo(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	users := map[string]slack.DNDStatus{}
+	for _, userID := range splitList(form.Get("users")) {
+		status := w.DND[userID]
+		users[userID] = slack.DNDStatus{DNDEnabled: status.DNDEnabled, NextDNDStartTS: status.NextDNDStartTS, NextDNDEndTS: status.NextDNDEndTS}
+	}
+	return response{"ok": true, "users": users}
+}
+
+// dndResponse flattens a do not disturb status into a response, like dnd.info.
+func dndResponse(status slack.DNDStatus) response {
+	res := response{
+		"ok":                true,
+		"dnd_enabled":       status.DNDEnabled,
+		"next_dnd_start_ts": status.NextDNDStartTS,
+		"next_dnd_end_ts":   status.NextDNDEndTS,
+		"snooze_enabled":    status.SnoozeEnabled,
+	}
+	if status.SnoozeEnabled {
+		res["snooze_endtime"] = status.SnoozeEndTime
+		res["snooze_remaining"] = status.SnoozeRemaining
+	}
+	return res
+}
+
+func emojiList(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+	return response{"ok": true, "emoji": w.Emoji}
+}
+
+func filesUpload(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	contents, hasContents := form["file"]
+	if !hasContents {
+		w.Unlock()
+		return errorResponse(slack.ErrorNoFileData)
+	}
+	var channelIDs []string
+	if channels := form.Get("channels"); len(channels) > 0 {
+		channelIDs = strings.Split(channels, ",")
+	}
+	for _, channelID := range channelIDs {
+		if !w.hasConversation(channelID) {
+			w.Unlock()
+			return errorResponse(slack.ErrorChannelNotFound)
+		}
+	}
+
+	w.sequence++
+	file := slack.File{
+		ID:       fmt.Sprintf("F%08d", w.sequence),
+		Created:  slack.NewTimestamp(w.now()),
+		Name:     form.Get("filename"),
+		Title:    form.Get("title"),
+		FileType: form.Get("filetype"),
+		UserID:   w.Self.ID,
+		Size:     len(contents[0]),
+		Preview:  contents[0],
+		Channels: channelIDs,
+	}
+	if len(file.Title) == 0 {
+		file.Title = file.Name
+	}
+	w.Files[file.ID] = file
+
+	var shared []slack.Message
+	for _, channelID := range channelIDs {
+		m := slack.Message{SubType: string(slack.EventSubtypeFileShare), User: w.Self.ID, Text: form.Get("initial_comment"), Files: []slack.File{file}}
+		if threadTS := form.Get("thread_ts"); len(threadTS) > 0 {
+			var ts slack.Timestamp
+			ts.UnmarshalJSON([]byte(threadTS))
+			m.ThreadTimestamp = &ts
+		}
+		shared = append(shared, w.addMessage(channelID, m))
+	}
+	w.Unlock()
+
+	for _, m := range shared {
+		s.SendEvent(m)
+	}
+	return response{"ok": true, "file": file}
+}
+
+func groupsArchive(s *Server, form url.Values) interface{} {
+	return setGroupArchived(s, form, true)
+}
+
+func groupsUnarchive(s *Server, form url.Values) interface{} {
+	return setGroupArchived(s, form, false)
+}
+
+func setGroupArchived(s *Server, form url.Values, archived bool) interface{} {
+	w := s.Workspace
+	w.Lock()
+	group, hasGroup := w.group(form.Get("channel"))
+	if !hasGroup {
+		w.Unlock()
+		return errorResponse(slack.ErrorChannelNotFound)
+	}
+	if group.IsArchived == archived {
+		w.Unlock()
+		if archived {
+			return errorResponse(slack.ErrorAlreadyArchived)
+		}
+		return errorResponse(slack.ErrorNotArchived)
+	}
+	group.IsArchived = archived
+	w.Unlock()
+
+	eventType := slack.EventGroupArchive
+	if !archived {
+		eventType = slack.EventGroupUnarchive
+	}
+	s.SendEvent(slack.Message{Type: eventType, Channel: form.Get("channel"), User: w.Self.ID})
+	return okResponse()
+}
+
+func groupsClose(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	if _, hasGroup := w.group(form.Get("channel")); !hasGroup {
+		return errorResponse(slack.ErrorChannelNotFound)
+	}
+	return okResponse()
+}
+
+func groupsCreate(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+
+	name := form.Get("name")
+	if len(name) == 0 {
+		w.Unlock()
+		return errorResponse(slack.ErrorInvalidName)
+	}
+	if w.conversationNamed(name) {
+		w.Unlock()
+		return errorResponse(slack.ErrorNameTaken)
+	}
+
+	w.sequence++
+	group := &slack.Group{
+		ID:      fmt.Sprintf("G%08d", w.sequence),
+		Name:    name,
+		IsGroup: true,
+		Created: slack.NewTimestamp(w.now()),
+		Creator: w.Self.ID,
+		Members: []string{w.Self.ID},
+	}
+	w.Groups[group.ID] = group
+	created := *group
+	w.Unlock()
+
+	s.SendEvent(response{"type": slack.EventGroupJoined, "channel": created})
+	return response{"ok": true, "group": created}
+}
+
+func groupsHistory(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	if _, hasGroup := w.group(form.Get("channel")); !hasGroup {
+		return errorResponse(slack.ErrorChannelNotFound)
+	}
+	return w.history(form)
+}
+
+func groupsInfo(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	group, hasGroup := w.group(form.Get("channel"))
+	if !hasGroup {
+		return errorResponse(slack.ErrorChannelNotFound)
+	}
+	return response{"ok": true, "group": group}
+}
+
+func groupsInvite(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	group, hasGroup := w.group(form.Get("channel"))
+	if !hasGroup {
+		return errorResponse(slack.ErrorChannelNotFound)
+	}
+	userID := form.Get("user")
+	if _, hasUser := w.Users[userID]; !hasUser {
+		return errorResponse(slack.ErrorUserNotFound)
+	}
+	alreadyInGroup := containsString(group.Members, userID)
+	if !alreadyInGroup {
+		group.Members = append(group.Members, userID)
+	}
+	return response{"ok": true, "group": group, "already_in_group": alreadyInGroup}
+}
+
+func groupsKick(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	group, hasGroup := w.group(form.Get("channel"))
+	if !hasGroup {
+		return errorResponse(slack.ErrorChannelNotFound)
+	}
+	userID := form.Get("user")
+	if _, hasUser := w.Users[userID]; !hasUser {
+		return errorResponse(slack.ErrorUserNotFound)
+	}
+	if userID == w.Self.ID {
+		return errorResponse("cant_kick_self")
+	}
+	members := difference(group.Members, []string{userID})
+	if len(members) == len(group.Members) {
+		return errorResponse("not_in_group")
+	}
+	group.Members = members
+	return okResponse()
+}
+
+func groupsLeave(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	group, hasGroup := w.group(form.Get("channel"))
+	if !hasGroup {
+		w.Unlock()
+		return errorResponse(slack.ErrorChannelNotFound)
+	}
+	members := difference(group.Members, []string{w.Self.ID})
+	if len(members) == 0 {
+		w.Unlock()
+		return errorResponse("last_member")
+	}
+	group.Members = members
+	w.Unlock()
+
+	s.SendEvent(slack.Message{Type: slack.EventGroupLeft, Channel: form.Get("channel")})
+	return okResponse()
+}
+
+func groupsList(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	groups := []slack.Group{}
+	for _, group := range w.memberGroups(false) {
+		if group.IsArchived && form.Get("exclude_archived") == "1" {
+			continue
+		}
+		groups = append(groups, group)
+	}
+	start, end, next, ok := page(form, len(groups))
+	if !ok {
+		return errorResponse(slack.ErrorInvalidCursor)
+	}
+	return response{"ok": true, "groups": groups[start:end], "response_metadata": slack.ResponseMetadata{NextCursor: next}}
+}
+
+func groupsMark(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	group, hasGroup := w.group(form.Get("channel"))
+	if !hasGroup {
+		return errorResponse(slack.ErrorChannelNotFound)
+	}
+	var ts slack.Timestamp
+	ts.UnmarshalJSON([]byte(form.Get("ts")))
+	group.LastRead = ts
+	return okResponse()
+}
+
+func groupsOpen(s *Server, form url.Values) interface{} {
+	return groupsClose(s, form)
+}
+
+func groupsRename(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	group, hasGroup := w.group(form.Get("channel"))
+	if !hasGroup {
+		w.Unlock()
+		return errorResponse(slack.ErrorChannelNotFound)
+	}
+	name := form.Get("name")
+	if len(name) == 0 {
+		w.Unlock()
+		return errorResponse(slack.ErrorInvalidName)
+	}
+	if name != group.Name && w.conversationNamed(name) {
+		w.Unlock()
+		return errorResponse(slack.ErrorNameTaken)
+	}
+	group.Name = name
+	renamed := *group
+	w.Unlock()
+
+	s.SendEvent(response{"type": slack.EventGroupRename, "channel": response{"id": renamed.ID, "name": renamed.Name, "created": renamed.Created}})
+	return response{"ok": true, "channel": renamed}
+}
+
+func groupsReplies(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	if _, hasGroup := w.group(form.Get("channel")); !hasGroup {
+		return errorResponse(slack.ErrorChannelNotFound)
+	}
+	return w.replies(form)
+}
+
+func groupsSetPurpose(s *Server, form url.Values) interface{} {
+	return setGroupTopic(s, form, "purpose")
+}
+
+func groupsSetTopic(s *Server, form url.Values) interface{} {
+	return setGroupTopic(s, form, "topic")
+}
+
+func setGroupTopic(s *Server, form url.Values, field string) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	group, hasGroup := w.group(form.Get("channel"))
+	if !hasGroup {
+		return errorResponse(slack.ErrorChannelNotFound)
+	}
+	topic := &slack.Topic{Value: form.Get(field), Creator: w.Self.ID, LastSet: slack.NewTimestamp(w.now())}
+	if field == "purpose" {
+		group.Purpose = topic
+	} else {
+		group.Topic = topic
+	}
+	return response{"ok": true, field: topic.Value}
+}
+
+func imClose(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	im, hasIM := w.IMs[form.Get("channel")]
+	if !hasIM {
+		return errorResponse(slack.ErrorChannelNotFound)
+	}
+	if !im.IsOpen {
+		return response{"ok": true, "no_op": true, "already_closed": true}
+	}
+	im.IsOpen = false
+	return okResponse()
+}
+
+func imHistory(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	if _, hasIM := w.IMs[form.Get("channel")]; !hasIM {
+		return errorResponse(slack.ErrorChannelNotFound)
+	}
+	return w.history(form)
+}
+
+func imList(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	ims := []slack.InstantMessage{}
+	for _, id := range w.imIDs() {
+		ims = append(ims, *w.IMs[id])
+	}
+	return response{"ok": true, "ims": ims}
+}
+
+func imMark(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	im, hasIM := w.IMs[form.Get("channel")]
+	if !hasIM {
+		return errorResponse(slack.ErrorChannelNotFound)
+	}
+	var ts slack.Timestamp
+	ts.UnmarshalJSON([]byte(form.Get("ts")))
+	im.LastRead = ts
+	return okResponse()
+}
+
+func imOpen(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+
+	userID := form.Get("user")
+	if _, hasUser := w.Users[userID]; !hasUser {
+		w.Unlock()
+		return errorResponse(slack.ErrorUserNotFound)
+	}
+	for _, id := range w.imIDs() {
+		if im := w.IMs[id]; im.User == userID {
+			alreadyOpen := im.IsOpen
+			im.IsOpen = true
+			w.Unlock()
+			return response{"ok": true, "no_op": alreadyOpen, "already_open": alreadyOpen, "channel": im}
+		}
+	}
+	im := w.addIM(userID)
+	w.Unlock()
+
+	s.SendEvent(response{"type": slack.EventIMCreated, "user": userID, "channel": im})
+	return response{"ok": true, "channel": im}
+}
+
+func imReplies(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	if _, hasIM := w.IMs[form.Get("channel")]; !hasIM {
+		return errorResponse(slack.ErrorChannelNotFound)
+	}
+	return w.replies(form)
+}
+
+func mpimClose(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	if _, hasMPIM := w.mpim(form.Get("channel")); !hasMPIM {
+		return errorResponse(slack.ErrorChannelNotFound)
+	}
+	return okResponse()
+}
+
+func mpimHistory(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	if _, hasMPIM := w.mpim(form.Get("channel")); !hasMPIM {
+		return errorResponse(slack.ErrorChannelNotFound)
+	}
+	return w.history(form)
+}
+
+func mpimList(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+	return response{"ok": true, "groups": w.memberGroups(true)}
+}
+
+func mpimMark(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	mpim, hasMPIM := w.mpim(form.Get("channel"))
+	if !hasMPIM {
+		return errorResponse(slack.ErrorChannelNotFound)
+	}
+	var ts slack.Timestamp
+	ts.UnmarshalJSON([]byte(form.Get("ts")))
+	mpim.LastRead = ts
+	return okResponse()
+}
+
+func mpimOpen(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	members := []string{w.Self.ID}
+	for _, userID := range splitList(form.Get("users")) {
+		if _, hasUser := w.Users[userID]; !hasUser {
+			return errorResponse(slack.ErrorUsersNotFound)
+		}
+		if !containsString(members, userID) {
+			members = append(members, userID)
+		}
+	}
+	if len(members) < 3 {
+		return errorResponse("not_enough_users")
+	}
+
+	for _, mpim := range w.memberGroups(true) {
+		if len(mpim.Members) == len(members) && len(difference(members, mpim.Members)) == 0 {
+			return response{"ok": true, "group": mpim}
+		}
+	}
+
+	var names []string
+	for _, userID := range members {
+		names = append(names, w.Users[userID].Name)
+	}
+	w.sequence++
+	mpim := &slack.Group{
+		ID:      fmt.Sprintf("G%08d", w.sequence),
+		Name:    "mpdm-" + strings.Join(names, "--") + "-1",
+		IsGroup: true,
+		IsMPIM:  true,
+		Created: slack.NewTimestamp(w.now()),
+		Creator: w.Self.ID,
+		Members: members,
+	}
+	w.Groups[mpim.ID] = mpim
+	return response{"ok": true, "group": *mpim}
+}
+
+func mpimReplies(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	if _, hasMPIM := w.mpim(form.Get("channel")); !hasMPIM {
+		return errorResponse(slack.ErrorChannelNotFound)
+	}
+	return w.replies(form)
+}
+
+func pinsAdd(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	channelID := form.Get("channel")
+	if _, hasChannel := w.Channels[channelID]; !hasChannel {
+		return errorResponse(slack.ErrorChannelNotFound)
+	}
+	item, code := w.item(form)
+	if item.Type == slack.ItemTypeChannel {
+		code = slack.ErrorNoItemSpecified
+	}
+	if len(code) > 0 {
+		return errorResponse(code)
+	}
+	for _, pinned := range w.Pins[channelID] {
+		if sameItem(pinned, item) {
+			return errorResponse("already_pinned")
+		}
+	}
+
+	created := slack.NewTimestamp(w.now())
+	item.Created = &created
+	item.CreatedBy = w.Self.ID
+	w.Pins[channelID] = append(w.Pins[channelID], item)
+	return okResponse()
+}
+
+func pinsList(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	channelID := form.Get("channel")
+	if _, hasChannel := w.Channels[channelID]; !hasChannel {
+		return errorResponse(slack.ErrorChannelNotFound)
+	}
+	return response{"ok": true, "items": append([]slack.Item{}, w.Pins[channelID]...)}
+}
+
+func pinsRemove(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	channelID := form.Get("channel")
+	item, code := w.item(form)
+	if len(code) > 0 {
+		return errorResponse(code)
+	}
+	for index, pinned := range w.Pins[channelID] {
+		if sameItem(pinned, item) {
+			w.Pins[channelID] = append(w.Pins[channelID][:index], w.Pins[channelID][index+1:]...)
+			return okResponse()
+		}
+	}
+	return errorResponse("no_pin")
+}
+
+func reactionsAdd(s *Server, form url.Values) interface{} {
+	return updateReaction(s, form, true)
+}
+
+func reactionsRemove(s *Server, form url.Values) interface{} {
+	return updateReaction(s, form, false)
+}
+
+func updateReaction(s *Server, form url.Values, add bool) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	channelID := form.Get("channel")
+	index, found := w.findMessage(channelID, form.Get("timestamp"))
+	if !found {
+		return errorResponse(slack.ErrorMessageNotFound)
+	}
+	m := &w.Messages[channelID][index]
+	name := form.Get("name")
+
+	for reactionIndex := range m.Reactions {
+		reaction := &m.Reactions[reactionIndex]
+		if reaction.Name != name {
+			continue
+		}
+		for userIndex, user := range reaction.Users {
+			if user != w.Self.ID {
+				continue
+			}
+			if add {
+				return errorResponse(slack.ErrorAlreadyReacted)
+			}
+			reaction.Users = append(reaction.Users[:userIndex], reaction.Users[userIndex+1:]...)
+			reaction.Count--
+			if reaction.Count == 0 {
+				m.Reactions = append(m.Reactions[:reactionIndex], m.Reactions[reactionIndex+1:]...)
+			}
+			return okResponse()
+		}
+		if add {
+			reaction.Users = append(reaction.Users, w.Self.ID)
+			reaction.Count++
+			return okResponse()
+		}
+	}
+
+	if !add {
+		return errorResponse("no_reaction")
+	}
+	m.Reactions = append(m.Reactions, slack.Reaction{Name: name, Count: 1, Users: []string{w.Self.ID}})
+	return okResponse()
+}
+
+func reactionsGet(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	channelID := form.Get("channel")
+	index, found := w.findMessage(channelID, form.Get("timestamp"))
+	if !found {
+		return errorResponse(slack.ErrorMessageNotFound)
+	}
+	m := w.Messages[channelID][index]
+	return response{"ok": true, "type": "message", "channel": channelID, "message": m}
+}
+
+func reactionsList(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	userID := form.Get("user")
+	if len(userID) == 0 {
+		userID = w.Self.ID
+	}
+
+	var channelIDs []string
+	for channelID := range w.Messages {
+		channelIDs = append(channelIDs, channelID)
+	}
+	sort.Strings(channelIDs)
+
+	items := []slack.Item{}
+	for _, channelID := range channelIDs {
+		for _, m := range w.Messages[channelID] {
+			if hasReacted(m.Reactions, userID) {
+				message := m
+				items = append(items, slack.Item{Type: slack.ItemTypeMessage, Channel: channelID, Message: &message})
+			}
+		}
+	}
+	return response{"ok": true, "items": items}
+}
+
+// hasReacted returns if a user is one of the users of any of the reactions.
+func hasReacted(reactions []slack.Reaction, userID string) bool {
+	for _, reaction := range reactions {
+		for _, user := range reaction.Users {
+			if user == userID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func remindersAdd(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	if len(form.Get("text")) == 0 {
+		return errorResponse(slack.ErrorNoText)
+	}
+	userID := form.Get("user")
+	if len(userID) == 0 {
+		userID = w.Self.ID
+	}
+	if _, hasUser := w.Users[userID]; !hasUser {
+		return errorResponse(slack.ErrorUserNotFound)
+	}
+
+	w.sequence++
+	reminder := slack.Reminder{
+		ID:      fmt.Sprintf("Rm%08d", w.sequence),
+		Creator: w.Self.ID,
+		User:    userID,
+		Text:    form.Get("text"),
+	}
+
+	when := form.Get("time")
+	if recurrence := form.Get("recurrence"); len(recurrence) > 0 {
+		reminder.Recurrence = &slack.ReminderRecurrence{}
+		if err := json.Unmarshal([]byte(recurrence), reminder.Recurrence); err != nil {
+			return errorResponse("invalid_recurrence")
+		}
+		reminder.Recurring = true
+	} else if strings.HasPrefix(when, "every ") {
+		reminder.Recurring = true
+	} else {
+		at, ok := reminderTime(when, w.now())
+		if !ok {
+			return errorResponse("cannot_parse")
+		}
+		fires := slack.NewTimestamp(at)
+		reminder.Time = &fires
+		complete := slack.NewTimestamp(time.Unix(0, 0))
+		reminder.CompleteTS = &complete
+	}
+
+	w.Reminders[reminder.ID] = reminder
+	return response{"ok": true, "reminder": reminder}
+}
+
+// reminderTime parses the `time` of a one-off reminder: a unix timestamp, seconds from now, or `in <n> <unit>`.
+func reminderTime(when string, now time.Time) (time.Time, bool) {
+	if value, err := strconv.ParseInt(when, 10, 64); err == nil {
+		if value < 24*60*60 {
+			return now.Add(time.Duration(value) * time.Second), true
+		}
+		return time.Unix(value, 0), true
+	}
+
+	fields := strings.Fields(when)
+	if len(fields) != 3 || fields[0] != "in" {
+		return time.Time{}, false
+	}
+	count, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return time.Time{}, false
+	}
+	units := map[string]time.Duration{"second": time.Second, "minute": time.Minute, "hour": time.Hour, "day": 24 * time.Hour}
+	unit, ok := units[strings.TrimSuffix(fields[2], "s")]
+	if !ok {
+		return time.Time{}, false
+	}
+	return now.Add(time.Duration(count) * unit), true
+}
+
+func remindersComplete(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	reminder, found := w.Reminders[form.Get("reminder")]
+	if !found {
+		return errorResponse(slack.ErrorNotFound)
+	}
+	if reminder.Recurring {
+		return errorResponse(slack.ErrorCannotCompleteRecurring)
+	}
+	if reminder.IsComplete() {
+		return errorResponse("already_complete")
+	}
+	complete := slack.NewTimestamp(w.now())
+	reminder.CompleteTS = &complete
+	w.Reminders[reminder.ID] = reminder
+	return okResponse()
+}
+
+func remindersDelete(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	if _, found := w.Reminders[form.Get("reminder")]; !found {
+		return errorResponse(slack.ErrorNotFound)
+	}
+	delete(w.Reminders, form.Get("reminder"))
+	return okResponse()
+}
+
+func remindersInfo(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	reminder, found := w.Reminders[form.Get("reminder")]
+	if !found {
+		return errorResponse(slack.ErrorNotFound)
+	}
+	return response{"ok": true, "reminder": reminder}
+}
+
+func remindersList(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	reminders := []slack.Reminder{}
+	for _, reminder := range w.Reminders {
+		reminders = append(reminders, reminder)
+	}
+	sort.Slice(reminders, func(i, j int) bool { return reminders[i].ID < reminders[j].ID })
+	return response{"ok": true, "reminders": reminders}
+}
+
+// searchMessages supports free text terms and the `in:#channel`, `from:@user`, `before:`, `after:` and `has:link` modifiers.
+func searchMessages(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	query := form.Get("query")
+	if len(strings.TrimSpace(query)) == 0 {
+		return errorResponse("no_query")
+	}
+
+	var matches []slack.SearchMessage
+	for channelID, history := range w.Messages {
+		channel := w.Channels[channelID]
+		for index, m := range history {
+			if !w.searchMatches(query, channel, m) {
+				continue
+			}
+			match := slack.SearchMessage{
+				Type:      "message",
+				IID:       fmt.Sprintf("%s-%s", channelID, m.Timestamp.String()),
+				Team:      w.Team.ID,
+				Channel:   slack.SearchChannel{ID: channelID, IsChannel: true},
+				User:      m.User,
+				Timestamp: m.Timestamp,
+				Text:      m.Text,
+				Permalink: fmt.Sprintf("%s/archives/%s/p%s", s.URL(), channelID, strings.Replace(m.Timestamp.String(), ".", "", -1)),
+			}
+			if channel != nil {
+				match.Channel.Name = channel.Name
+			}
+			if user, hasUser := w.Users[m.User]; hasUser {
+				match.Username = user.Name
+			}
+			if index > 0 {
+				match.Previous = w.searchContext(history[index-1])
+			}
+			if index+1 < len(history) {
+				match.Next = w.searchContext(history[index+1])
+			}
+			matches = append(matches, match)
+		}
+	}
+
+	ascending := form.Get("sort_dir") == slack.SearchSortAsc
+	sort.Slice(matches, func(i, j int) bool {
+		before := matches[i].Timestamp.Time().Before(matches[j].Timestamp.Time()) ||
+			(matches[i].Timestamp.Time().Equal(matches[j].Timestamp.Time()) && matches[i].Timestamp.UUID() < matches[j].Timestamp.UUID())
+		return before == ascending
+	})
+
+	count, _ := strconv.Atoi(form.Get("count"))
+	if count <= 0 {
+		count = 20
+	}
+	page, _ := strconv.Atoi(form.Get("page"))
+	if page <= 0 {
+		page = 1
+	}
+	pageCount := (len(matches) + count - 1) / count
+	if pageCount == 0 {
+		pageCount = 1
+	}
+
+	first, last := (page-1)*count, page*count
+	if first > len(matches) {
+		first = len(matches)
+	}
+	if last > len(matches) {
+		last = len(matches)
+	}
+
+	return slack.SearchMessagesResponse{
+		OK:    true,
+		Query: query,
+		Messages: slack.SearchMessages{
+			Total:      len(matches),
+			Matches:    append([]slack.SearchMessage{}, matches[first:last]...),
+			Paging:     slack.Paging{Total: len(matches), Page: page, Pages: pageCount},
+			Pagination: slack.SearchPagination{TotalCount: len(matches), Page: page, PerPage: count, PageCount: pageCount, First: first + 1, Last: last},
+		},
+	}
+}
+
+func starsAdd(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	item, code := w.item(form)
+	if len(code) > 0 {
+		return errorResponse(code)
+	}
+	for _, starred := range w.Stars {
+		if sameItem(starred, item) {
+			return errorResponse("already_starred")
+		}
+	}
+
+	created := slack.NewTimestamp(w.now())
+	item.DateCreate = &created
+	w.Stars = append(w.Stars, item)
+	return okResponse()
+}
+
+func starsList(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+	return response{"ok": true, "items": append([]slack.Item{}, w.Stars...)}
+}
+
+func starsRemove(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	item, code := w.item(form)
+	if len(code) > 0 {
+		return errorResponse(code)
+	}
+	for index, starred := range w.Stars {
+		if sameItem(starred, item) {
+			w.Stars = append(w.Stars[:index], w.Stars[index+1:]...)
+			return okResponse()
+		}
+	}
+	return errorResponse("not_starred")
+}
+
+// sameItem returns if two items refer to the same message, file, file comment or channel.
+func sameItem(a, b slack.Item) bool {
+	if a.Type != b.Type || a.Channel != b.Channel {
+		return false
+	}
+	switch a.Type {
+	case slack.ItemTypeMessage:
+		return a.Message.Timestamp.String() == b.Message.Timestamp.String()
+	case slack.ItemTypeFile:
+		return a.File.ID == b.File.ID
+	case slack.ItemTypeFileComment:
+		return a.Comment.ID == b.Comment.ID
+	default:
+		return true
+	}
+}
+
+func teamBillableInfo(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	billable := map[string]slack.BillableInfo{}
+	for _, user := range w.Users {
+		if userID := form.Get("user"); len(userID) > 0 && user.ID != userID {
+			continue
+		}
+		billable[user.ID] = slack.BillableInfo{BillingActive: !user.IsBot && !user.Deleted}
+	}
+	return response{"ok": true, "billable_info": billable}
+}
+
+func teamInfo(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+	return response{"ok": true, "team": w.Team}
+}
+
+func usergroupsCreate(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+
+	name := form.Get("name")
+	if len(name) == 0 {
+		w.Unlock()
+		return errorResponse("invalid_name")
+	}
+	for _, existing := range w.UserGroups {
+		if existing.Name == name || (len(form.Get("handle")) > 0 && existing.Handle == form.Get("handle")) {
+			w.Unlock()
+			return errorResponse("name_already_exists")
+		}
+	}
+
+	w.sequence++
+	now := slack.NewTimestamp(w.now())
+	userGroup := &slack.UserGroup{
+		ID:          fmt.Sprintf("S%08d", w.sequence),
+		TeamID:      w.Team.ID,
+		IsUserGroup: true,
+		Name:        name,
+		Handle:      form.Get("handle"),
+		Description: form.Get("description"),
+		DateCreate:  now,
+		DateUpdate:  now,
+		CreatedBy:   w.Self.ID,
+		UpdatedBy:   w.Self.ID,
+		Prefs:       &slack.UserGroupPrefs{Channels: splitList(form.Get("channels")), Groups: []string{}},
+		Users:       []string{},
+		UserCount:   "0",
+	}
+	w.UserGroups[userGroup.ID] = userGroup
+	created := *userGroup
+	w.Unlock()
+
+	s.SendEvent(slack.Message{Type: slack.EventSubteamCreated, Subteam: &created})
+	return response{"ok": true, "usergroup": created}
+}
+
+func usergroupsDisable(s *Server, form url.Values) interface{} {
+	return updateUserGroup(s, form, func(w *Workspace, userGroup *slack.UserGroup) string {
+		userGroup.DateDelete = slack.NewTimestamp(w.now())
+		userGroup.DeletedBy = w.Self.ID
+		return ""
+	})
+}
+
+func usergroupsEnable(s *Server, form url.Values) interface{} {
+	return updateUserGroup(s, form, func(w *Workspace, userGroup *slack.UserGroup) string {
+		userGroup.DateDelete = slack.Timestamp{}
+		userGroup.DeletedBy = ""
+		return ""
+	})
+}
+
+func usergroupsUpdate(s *Server, form url.Values) interface{} {
+	return updateUserGroup(s, form, func(w *Workspace, userGroup *slack.UserGroup) string {
+		if _, hasName := form["name"]; hasName {
+			userGroup.Name = form.Get("name")
+		}
+		if _, hasHandle := form["handle"]; hasHandle {
+			userGroup.Handle = form.Get("handle")
+		}
+		if _, hasDescription := form["description"]; hasDescription {
+			userGroup.Description = form.Get("description")
+		}
+		if _, hasChannels := form["channels"]; hasChannels {
+			userGroup.Prefs.Channels = splitList(form.Get("channels"))
+		}
+		return ""
+	})
+}
+
+func usergroupsUsersUpdate(s *Server, form url.Values) interface{} {
+	var added, removed []string
+	res := updateUserGroup(s, form, func(w *Workspace, userGroup *slack.UserGroup) string {
+		users := splitList(form.Get("users"))
+		if len(users) == 0 {
+			return "no_users_provided"
+		}
+		for _, userID := range users {
+			if _, hasUser := w.Users[userID]; !hasUser {
+				return slack.ErrorUserNotFound
+			}
+		}
+		added, removed = difference(users, userGroup.Users), difference(userGroup.Users, users)
+		userGroup.Users = users
+		userGroup.UserCount = json.Number(strconv.Itoa(len(users)))
+		return ""
+	})
+
+	if userGroup, ok := res.(response)["usergroup"].(slack.UserGroup); ok {
+		s.SendEvent(slack.Message{Type: slack.EventSubteamMembersChanged, SubteamID: userGroup.ID, AddedUsers: added, RemovedUsers: removed})
+	}
+	return res
+}
+
+// updateUserGroup applies a change to the user group in the `usergroup` form value and sends `subteam_updated`.
+// The change returns an error code, or an empty string.
+func updateUserGroup(s *Server, form url.Values, change func(*Workspace, *slack.UserGroup) string) interface{} {
+	w := s.Workspace
+	w.Lock()
+
+	userGroup, hasUserGroup := w.UserGroups[form.Get("usergroup")]
+	if !hasUserGroup {
+		w.Unlock()
+		return errorResponse("no_such_subteam")
+	}
+	if code := change(w, userGroup); len(code) > 0 {
+		w.Unlock()
+		return errorResponse(code)
+	}
+	userGroup.DateUpdate = slack.NewTimestamp(w.now())
+	userGroup.UpdatedBy = w.Self.ID
+	updated := *userGroup
+	w.Unlock()
+
+	s.SendEvent(slack.Message{Type: slack.EventSubteamUpdated, Subteam: &updated})
+	return response{"ok": true, "usergroup": updated}
+}
+
+func usergroupsList(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	userGroups := []slack.UserGroup{}
+	for _, userGroup := range w.UserGroups {
+		if !userGroup.IsEnabled() && form.Get("include_disabled") != "true" {
+			continue
+		}
+		listed := *userGroup
+		if form.Get("include_users") != "true" {
+			listed.Users = nil
+		}
+		if form.Get("include_count") != "true" {
+			listed.UserCount = ""
+		}
+		userGroups = append(userGroups, listed)
+	}
+	sort.Slice(userGroups, func(i, j int) bool { return userGroups[i].ID < userGroups[j].ID })
+	return response{"ok": true, "usergroups": userGroups}
+}
+
+func usergroupsUsersList(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	userGroup, hasUserGroup := w.UserGroups[form.Get("usergroup")]
+	if !hasUserGroup {
+		return errorResponse("no_such_subteam")
+	}
+	if !userGroup.IsEnabled() && form.Get("include_disabled") != "true" {
+		return errorResponse("usergroup_disabled")
+	}
+	return response{"ok": true, "users": append([]string{}, userGroup.Users...)}
+}
+
+// splitList splits a comma separated form value.
+func splitList(value string) []string {
+	values := []string{}
+	for _, piece := range strings.Split(value, ",") {
+		if piece = strings.TrimSpace(piece); len(piece) > 0 {
+			values = append(values, piece)
+		}
+	}
+	return values
+}
+
+func containsString(values []string, value string) bool {
+	for _, other := range values {
+		if other == value {
+			return true
+		}
+	}
+	return false
+}
+
+// difference returns the values in a that aren't in b.
+func difference(a, b []string) []string {
+	var values []string
+	for _, value := range a {
+		found := false
+		for _, other := range b {
+			found = found || other == value
+		}
+		if !found {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func usersInfo(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	user, hasUser := w.Users[form.Get("user")]
+	if !hasUser {
+		return errorResponse(slack.ErrorUserNotFound)
+	}
+	return response{"ok": true, "user": user}
+}
+
+func usersGetPresence(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	user, hasUser := w.Users[form.Get("user")]
+	if !hasUser {
+		return errorResponse(slack.ErrorUserNotFound)
+	}
+	presence := slack.PresenceActive
+	if user.Presence == slack.PresenceAway {
+		presence = slack.PresenceAway
+	}
+	return response{"ok": true, "presence": presence, "online": presence == slack.PresenceActive, "manual_away": user.Presence == slack.PresenceAway}
+}
+
+func usersSetPresence(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	switch form.Get("presence") {
+	case slack.PresenceAuto:
+		w.Users[w.Self.ID].Presence = slack.PresenceActive
+	case slack.PresenceAway:
+		w.Users[w.Self.ID].Presence = slack.PresenceAway
+	default:
+		return errorResponse("invalid_presence")
+	}
+	return okResponse()
+}
+
+func usersLookupByEmail(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	for _, id := range w.userIDs() {
+		if user := w.Users[id]; user.Profile != nil && strings.EqualFold(user.Profile.Email, form.Get("email")) {
+			return response{"ok": true, "user": user}
+		}
+	}
+	return errorResponse(slack.ErrorUsersNotFound)
+}
+
+func usersProfileGet(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	user, hasUser := w.Users[userOrSelf(w, form)]
+	if !hasUser {
+		return errorResponse(slack.ErrorUserNotFound)
+	}
+	profile := slack.UserProfile{}
+	if user.Profile != nil {
+		profile = *user.Profile
+	}
+	return response{"ok": true, "profile": profile}
+}
+
+func usersProfileSet(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	user, hasUser := w.Users[userOrSelf(w, form)]
+	if !hasUser {
+		return errorResponse(slack.ErrorUserNotFound)
+	}
+	if user.Profile == nil {
+		user.Profile = &slack.UserProfile{}
+	}
+	if err := json.Unmarshal([]byte(form.Get("profile")), user.Profile); err != nil {
+		return errorResponse(slack.ErrorInvalidPayload)
+	}
+	return response{"ok": true, "profile": user.Profile}
+}
+
+// userOrSelf returns the `user` form value, defaulting to the bot; the caller must hold the workspace lock.
+func userOrSelf(w *Workspace, form url.Values) string {
+	if userID := form.Get("user"); len(userID) > 0 {
+		return userID
+	}
+	return w.Self.ID
+}
+
+func usersList(s *Server, form url.Values) interface{} {
+	w := s.Workspace
+	w.Lock()
+	defer w.Unlock()
+
+	users := []slack.User{}
+	for _, id := range w.userIDs() {
+		users = append(users, *w.Users[id])
+	}
+	start, end, next, ok := page(form, len(users))
+	if !ok {
+		return errorResponse(slack.ErrorInvalidCursor)
+	}
+	return response{"ok": true, "members": users[start:end], "response_metadata": slack.ResponseMetadata{NextCursor: next}}
+}
+
+// page returns the bounds of the page of `total` items selected by the `limit` and `cursor` form values,
+// and the cursor of the page after it (empty on the last page). Cursors are offsets into the list.
+func page(form url.Values, total int) (start, end int, next string, ok bool) {
+	if cursor := form.Get("cursor"); len(cursor) > 0 {
+		offset, err := strconv.Atoi(cursor)
+		if err != nil || offset < 0 || offset > total {
+			return 0, 0, "", false
+		}
+		start = offset
+	}
+	end = total
+	if limit, err := strconv.Atoi(form.Get("limit")); err == nil && limit > 0 && start+limit < total {
+		end = start + limit
+		next = strconv.Itoa(end)
+	}
+	return start, end, next, true
+}
+
+func parseFloat(value string) float64 {
+	parsed, _ := strconv.ParseFloat(value, 64)
+	return parsed
+}
@@ -0,0 +1,456 @@
+package slacktest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	exception "github.com/blendlabs/go-exception"
+	slack "github.com/wcharczuk/go-slack"
+)
+
+const (
+	// EnvRecord is the environment variable that switches OpenFixture to record mode.
+	EnvRecord = "SLACKTEST_RECORD"
+
+	// EnvToken is the environment variable OpenFixture reads the token to record with from.
+	EnvToken = "SLACK_TOKEN"
+
+	// ScrubbedToken replaces tokens in recorded fixtures, and is the token replayed clients use.
+	ScrubbedToken = "xoxb-scrubbed"
+
+	// Scrubbed replaces personal information in recorded fixtures.
+	Scrubbed = "scrubbed"
+
+	// DefaultUpstream is the api recorded against.
+	DefaultUpstream = "https://slack.com"
+)
+
+var (
+	// DefaultScrubbedFields are the request and response fields that are replaced when recording:
+	// personal details, tokens (and `rtm.start`'s websocket `url`, which carries a ticket), and access log details.
+	DefaultScrubbedFields = []string{
+		"email", "phone", "skype", "first_name", "last_name", "real_name",
+		"real_name_normalized", "display_name", "display_name_normalized", "title",
+		"token", "access_token", "bot_access_token", "url",
+		"ip", "user_agent",
+	}
+)
+
+// Interaction is a recorded web api request and its response.
+type Interaction struct {
+	Method     string          `json:"method"`
+	Form       url.Values      `json:"form"`
+	StatusCode int             `json:"status_code"`
+	Response   json.RawMessage `json:"response"`
+}
+
+// Cassette is a fixture file of recorded interactions.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// LoadCassette reads a cassette from a file.
+func LoadCassette(path string) (*Cassette, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, exception.Wrap(err)
+	}
+	var cassette Cassette
+	if err := json.Unmarshal(contents, &cassette); err != nil {
+		return nil, exception.Wrap(err)
+	}
+	return &cassette, nil
+}
+
+// Save writes a cassette to a file, creating the directory if needed.
+func (c *Cassette) Save(path string) error {
+	contents, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return exception.Wrap(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return exception.Wrap(err)
+	}
+	return exception.Wrap(ioutil.WriteFile(path, contents, 0644))
+}
+
+// --------------------------------------------------------------------------------
+// Recorder
+// --------------------------------------------------------------------------------
+
+// NewRecorder starts a proxy that forwards web api calls to an upstream (i.e. `DefaultUpstream`) and records them.
+func NewRecorder(upstream string) *Recorder {
+	r := &Recorder{
+		Upstream:       strings.TrimSuffix(upstream, "/"),
+		ScrubbedFields: DefaultScrubbedFields,
+		client:         &http.Client{},
+	}
+	r.server = httptest.NewServer(http.HandlerFunc(r.handle))
+	return r
+}
+
+// Recorder is a recording proxy in front of the slack web api.
+type Recorder struct {
+	// Upstream is the base url calls are forwarded to.
+	Upstream string
+	// ScrubbedFields are request form values and response fields (at any depth) that are replaced
+	// with `Scrubbed` when saved.
+	ScrubbedFields []string
+
+	server *httptest.Server
+	client *http.Client
+
+	lock         sync.Mutex
+	interactions []Interaction
+}
+
+// Client returns a client for a (real) token that calls slack through the recorder.
+func (r *Recorder) Client(token string) *slack.Client {
+	client := slack.NewClient(token)
+	client.SetDebug(false)
+	client.SetAPIEndpoint("http", strings.TrimPrefix(r.server.URL, "http://"))
+	return client
+}
+
+// Cassette returns the interactions recorded so far, with tokens and personal information scrubbed.
+func (r *Recorder) Cassette() *Cassette {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	cassette := &Cassette{}
+	for _, interaction := range r.interactions {
+		cassette.Interactions = append(cassette.Interactions, r.scrub(interaction))
+	}
+	return cassette
+}
+
+// Save writes the scrubbed interactions to a fixture file.
+func (r *Recorder) Save(path string) error {
+	return r.Cassette().Save(path)
+}
+
+// Close shuts the proxy down.
+func (r *Recorder) Close() {
+	r.server.Close()
+}
+
+func (r *Recorder) handle(w http.ResponseWriter, req *http.Request) {
+	// the request is forwarded as is (it can be multipart, i.e. for `files.upload`), so parse a copy of it.
+	requestBody, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse(slack.ErrorInvalidFormData))
+		return
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(requestBody))
+	if err := parseForm(req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse(slack.ErrorInvalidFormData))
+		return
+	}
+
+	upstream, err := http.NewRequest(req.Method, r.Upstream+req.URL.RequestURI(), bytes.NewReader(requestBody))
+	if err != nil {
+		writeJSON(w, http.StatusBadGateway, errorResponse(err.Error()))
+		return
+	}
+	upstream.Header.Set("Content-Type", req.Header.Get("Content-Type"))
+	res, err := r.client.Do(upstream)
+	if err != nil {
+		writeJSON(w, http.StatusBadGateway, errorResponse(err.Error()))
+		return
+	}
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		writeJSON(w, http.StatusBadGateway, errorResponse(err.Error()))
+		return
+	}
+
+	r.lock.Lock()
+	r.interactions = append(r.interactions, Interaction{
+		Method:     strings.TrimPrefix(req.URL.Path, "/api/"),
+		Form:       copyValues(req.Form),
+		StatusCode: res.StatusCode,
+		Response:   json.RawMessage(body),
+	})
+	r.lock.Unlock()
+
+	w.Header().Set("Content-Type", res.Header.Get("Content-Type"))
+	w.WriteHeader(res.StatusCode)
+	w.Write(body)
+}
+
+func (r *Recorder) scrub(interaction Interaction) Interaction {
+	fields := fieldSet(r.ScrubbedFields)
+	interaction.Form = scrubForm(interaction.Form, fields)
+
+	decoder := json.NewDecoder(bytes.NewReader(interaction.Response))
+	decoder.UseNumber()
+	var body interface{}
+	if err := decoder.Decode(&body); err != nil {
+		return interaction
+	}
+
+	if scrubbed, err := json.Marshal(scrubValue(body, fields)); err == nil {
+		interaction.Response = scrubbed
+	}
+	return interaction
+}
+
+// scrubForm returns a copy of a request form with the token and the scrubbed fields replaced.
+func scrubForm(form url.Values, fields map[string]bool) url.Values {
+	scrubbed := copyValues(form)
+	if _, hasToken := scrubbed["token"]; hasToken {
+		scrubbed.Set("token", ScrubbedToken)
+	}
+	for key, values := range scrubbed {
+		if key == "token" || !fields[key] {
+			continue
+		}
+		for index, value := range values {
+			if len(value) > 0 {
+				values[index] = Scrubbed
+			}
+		}
+	}
+	return scrubbed
+}
+
+func fieldSet(fields []string) map[string]bool {
+	set := map[string]bool{}
+	for _, field := range fields {
+		set[field] = true
+	}
+	return set
+}
+
+func scrubValue(value interface{}, fields map[string]bool) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for key, child := range typed {
+			if _, isString := child.(string); isString && fields[key] && len(child.(string)) > 0 {
+				typed[key] = Scrubbed
+				continue
+			}
+			typed[key] = scrubValue(child, fields)
+		}
+		return typed
+	case []interface{}:
+		for index, child := range typed {
+			typed[index] = scrubValue(child, fields)
+		}
+		return typed
+	default:
+		return value
+	}
+}
+
+// --------------------------------------------------------------------------------
+// Replayer
+// --------------------------------------------------------------------------------
+
+// NewReplayer starts a server that serves the interactions in a cassette.
+// Requests must match a recorded interaction's method and form values (other than the token) exactly,
+// after scrubbing them the way they were recorded; each interaction is served once, in recorded order.
+func NewReplayer(cassette *Cassette) *Replayer {
+	r := &Replayer{
+		ScrubbedFields: DefaultScrubbedFields,
+		cassette:       cassette,
+		used:           make([]bool, len(cassette.Interactions)),
+	}
+	r.server = httptest.NewServer(http.HandlerFunc(r.handle))
+	return r
+}
+
+// Replayer serves recorded interactions.
+type Replayer struct {
+	// ScrubbedFields are the request form values scrubbed before matching; they should be the fields
+	// the cassette was recorded with.
+	ScrubbedFields []string
+
+	server   *httptest.Server
+	cassette *Cassette
+
+	lock       sync.Mutex
+	used       []bool
+	mismatches []string
+}
+
+// Client returns a client that calls the replayer.
+func (r *Replayer) Client() *slack.Client {
+	client := slack.NewClient(ScrubbedToken)
+	client.SetDebug(false)
+	client.SetAPIEndpoint("http", strings.TrimPrefix(r.server.URL, "http://"))
+	return client
+}
+
+// Mismatches returns a description of every request that didn't match an interaction.
+func (r *Replayer) Mismatches() []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]string{}, r.mismatches...)
+}
+
+// Unused returns the interactions that haven't been replayed.
+func (r *Replayer) Unused() []Interaction {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var unused []Interaction
+	for index, interaction := range r.cassette.Interactions {
+		if !r.used[index] {
+			unused = append(unused, interaction)
+		}
+	}
+	return unused
+}
+
+// Close shuts the server down.
+func (r *Replayer) Close() {
+	r.server.Close()
+}
+
+func (r *Replayer) handle(w http.ResponseWriter, req *http.Request) {
+	if err := parseForm(req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse(slack.ErrorInvalidFormData))
+		return
+	}
+	method := strings.TrimPrefix(req.URL.Path, "/api/")
+	form := scrubForm(req.Form, fieldSet(r.ScrubbedFields))
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for index, interaction := range r.cassette.Interactions {
+		if r.used[index] || interaction.Method != method || !formsMatch(interaction.Form, form) {
+			continue
+		}
+		r.used[index] = true
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(interaction.StatusCode)
+		w.Write(interaction.Response)
+		return
+	}
+
+	r.mismatches = append(r.mismatches, fmt.Sprintf("%s %s", method, describeForm(form)))
+	writeJSON(w, http.StatusNotFound, errorResponse("slacktest_no_fixture"))
+}
+
+// --------------------------------------------------------------------------------
+// Fixture
+// --------------------------------------------------------------------------------
+
+// OpenFixture returns a fixture for a file that replays it, or records it against slack
+// when the `SLACKTEST_RECORD` environment variable is set (using the token in `SLACK_TOKEN`).
+// Close the fixture to save a recording, or to check a replay used every interaction.
+func OpenFixture(path string) (*Fixture, error) {
+	if len(os.Getenv(EnvRecord)) > 0 {
+		token := os.Getenv(EnvToken)
+		if len(token) == 0 {
+			return nil, exception.Newf("`%s` must be set to record fixtures.", EnvToken)
+		}
+		recorder := NewRecorder(DefaultUpstream)
+		return &Fixture{Path: path, recorder: recorder, client: recorder.Client(token)}, nil
+	}
+
+	cassette, err := LoadCassette(path)
+	if err != nil {
+		return nil, err
+	}
+	replayer := NewReplayer(cassette)
+	return &Fixture{Path: path, replayer: replayer, client: replayer.Client()}, nil
+}
+
+// Fixture is a recorded or replayed fixture file.
+type Fixture struct {
+	Path string
+
+	client   *slack.Client
+	recorder *Recorder
+	replayer *Replayer
+}
+
+// Client returns the client to run the test with.
+func (f *Fixture) Client() *slack.Client {
+	return f.client
+}
+
+// IsRecording returns if the fixture is recording.
+func (f *Fixture) IsRecording() bool {
+	return f.recorder != nil
+}
+
+// Close saves a recording, or returns an error if a replay had unmatched requests or unused interactions.
+func (f *Fixture) Close() error {
+	if f.recorder != nil {
+		defer f.recorder.Close()
+		return f.recorder.Save(f.Path)
+	}
+
+	defer f.replayer.Close()
+	if mismatches := f.replayer.Mismatches(); len(mismatches) > 0 {
+		return exception.Newf("%s: no fixture for %s", f.Path, strings.Join(mismatches, ", "))
+	}
+	if unused := f.replayer.Unused(); len(unused) > 0 {
+		return exception.Newf("%s: %d interactions weren't replayed, starting with `%s`", f.Path, len(unused), unused[0].Method)
+	}
+	return nil
+}
+
+func formsMatch(recorded, actual url.Values) bool {
+	keys := map[string]bool{}
+	for key := range recorded {
+		keys[key] = true
+	}
+	for key := range actual {
+		keys[key] = true
+	}
+	delete(keys, "token")
+
+	for key := range keys {
+		expected, given := recorded[key], actual[key]
+		if len(expected) != len(given) {
+			return false
+		}
+		for index := range expected {
+			if expected[index] != given[index] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func describeForm(form url.Values) string {
+	var keys []string
+	for key := range form {
+		if key != "token" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var pieces []string
+	for _, key := range keys {
+		pieces = append(pieces, fmt.Sprintf("%s=%s", key, strings.Join(form[key], ",")))
+	}
+	return "{" + strings.Join(pieces, " ") + "}"
+}
+
+func copyValues(values url.Values) url.Values {
+	copied := url.Values{}
+	for key, value := range values {
+		copied[key] = append([]string{}, value...)
+	}
+	return copied
+}
@@ -0,0 +1,119 @@
+package slacktest
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/blendlabs/go-assert"
+	slack "github.com/wcharczuk/go-slack"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	a := assert.New(t)
+
+	server := New()
+	defer server.Close()
+
+	recorder := NewRecorder(server.URL())
+	client := recorder.Client(server.Token)
+	users, err := client.UsersList()
+	a.Nil(err)
+	a.NotEmpty(users)
+	_, err = client.ChatPostMessage(slack.NewChatMessage(DefaultChannelID, "recorded"))
+	a.Nil(err)
+	_, err = client.UsersLookupByEmail("user@example.com")
+	a.Nil(err)
+	_, err = client.FilesUpload(&slack.FileUpload{Channels: []string{DefaultChannelID}, Filename: "notes.txt"}, strings.NewReader("notes"))
+	a.Nil(err)
+	recorder.Close()
+
+	dir, err := ioutil.TempDir("", "slacktest")
+	a.Nil(err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "fixture.json")
+	a.Nil(recorder.Save(path))
+
+	contents, err := ioutil.ReadFile(path)
+	a.Nil(err)
+	a.NotContains(server.Token, string(contents))
+	a.NotContains("user@example.com", string(contents))
+	a.Contains(ScrubbedToken, string(contents))
+
+	cassette, err := LoadCassette(path)
+	a.Nil(err)
+	a.Len(cassette.Interactions, 4)
+	a.Equal(Scrubbed, cassette.Interactions[2].Form.Get("email"))
+	a.Equal("notes", cassette.Interactions[3].Form.Get("file"))
+
+	replayer := NewReplayer(cassette)
+	defer replayer.Close()
+	replayed := replayer.Client()
+
+	// form values must match exactly.
+	_, err = replayed.ChatPostMessage(slack.NewChatMessage(DefaultChannelID, "different"))
+	a.NotNil(err)
+	a.Len(replayer.Mismatches(), 1)
+
+	replayedUsers, err := replayed.UsersList()
+	a.Nil(err)
+	a.Equal(len(users), len(replayedUsers))
+	_, err = replayed.ChatPostMessage(slack.NewChatMessage(DefaultChannelID, "recorded"))
+	a.Nil(err)
+	// scrubbed form values match whatever was recorded.
+	_, err = replayed.UsersLookupByEmail("user@example.com")
+	a.Nil(err)
+	file, err := replayed.FilesUpload(&slack.FileUpload{Channels: []string{DefaultChannelID}, Filename: "notes.txt"}, strings.NewReader("notes"))
+	a.Nil(err)
+	a.Equal("notes.txt", file.Name)
+	a.Empty(replayer.Unused())
+}
+
+func TestScrubValue(t *testing.T) {
+	a := assert.New(t)
+
+	var body interface{}
+	a.Nil(json.Unmarshal([]byte(`{
+		"ok": true,
+		"url": "wss://example.com/websocket/ticket",
+		"access_token": "xoxp-1234",
+		"bot": {"bot_access_token": "xoxb-1234"},
+		"logins": [{"user_id": "U0USER", "ip": "127.0.0.1", "user_agent": "SlackWeb"}]
+	}`), &body))
+	scrubbed, err := json.Marshal(scrubValue(body, fieldSet(DefaultScrubbedFields)))
+	a.Nil(err)
+	for _, secret := range []string{"ticket", "xoxp-1234", "xoxb-1234", "127.0.0.1", "SlackWeb"} {
+		a.NotContains(secret, string(scrubbed))
+	}
+	a.Contains("U0USER", string(scrubbed))
+
+	form := scrubForm(url.Values{"token": {"xoxb-1234"}, "access_token": {"xoxp-1234"}}, fieldSet(DefaultScrubbedFields))
+	a.Equal(ScrubbedToken, form.Get("token"))
+	a.Equal(Scrubbed, form.Get("access_token"))
+}
+
+func TestOpenFixture(t *testing.T) {
+	a := assert.New(t)
+	if len(os.Getenv(EnvRecord)) > 0 {
+		t.Skip("recording")
+	}
+
+	fixture, err := OpenFixture("testdata/channels.info.json")
+	a.Nil(err)
+	a.False(fixture.IsRecording())
+
+	channel, err := fixture.Client().ChannelsInfo("CTESTCHANNEL")
+	a.Nil(err)
+	a.Equal("test-channel", channel.Name)
+	a.Nil(fixture.Close())
+
+	fixture, err = OpenFixture("testdata/channels.info.json")
+	a.Nil(err)
+	err = fixture.Close()
+	a.NotNil(err)
+	a.True(strings.Contains(err.Error(), "channels.info"))
+}
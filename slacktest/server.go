@@ -0,0 +1,409 @@
+// Package slacktest is an in-process fake Slack server for integration testing go-slack clients and bots.
+//
+// It implements `rtm.start` / `rtm.connect`, the rtm websocket and the web api methods the client supports,
+// backed by an in memory Workspace. Tests can script incoming events, assert on the messages the client sends,
+// and simulate disconnects and api errors:
+//
+//	server := slacktest.New()
+//	defer server.Close()
+//
+//	client := server.Client()
+//	client.AddEventListener(slack.EventMessage, func(c *slack.Client, m *slack.Message) {
+//	    c.Say(m.Channel, "pong")
+//	})
+//	client.Connect()
+//
+//	server.SendMessage(slacktest.DefaultChannelID, slacktest.DefaultUserID, "ping")
+//	sent, err := server.WaitForSentMessages(1, time.Second)
+package slacktest
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	exception "github.com/blendlabs/go-exception"
+	"github.com/gorilla/websocket"
+	slack "github.com/wcharczuk/go-slack"
+)
+
+// APIHandler handles a web api method. Form values (including the token) are parsed before it's called.
+type APIHandler func(s *Server, form url.Values) interface{}
+
+// New starts a new fake server with the default workspace.
+func New() *Server {
+	return NewWithWorkspace(NewWorkspace())
+}
+
+// NewWithWorkspace starts a new fake server backed by a given workspace.
+func NewWithWorkspace(workspace *Workspace) *Server {
+	s := &Server{
+		Token:       DefaultToken,
+		Workspace:   workspace,
+		handlers:    map[string]APIHandler{},
+		errors:      map[string]string{},
+		calls:       map[string][]url.Values{},
+		connections: map[*websocket.Conn]*sync.Mutex{},
+		upgrader:    websocket.Upgrader{CheckOrigin: func(r *http.Request) bool { return true }},
+		changed:     make(chan struct{}, 1),
+	}
+	for method, handler := range defaultHandlers() {
+		s.handlers[method] = handler
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/", s.handleAPI)
+	mux.HandleFunc("/ws", s.handleSocket)
+	s.server = httptest.NewServer(mux)
+	return s
+}
+
+// Server is a fake Slack server.
+type Server struct {
+	// Token is the token the server accepts; other tokens get `invalid_auth`.
+	Token string
+	// Workspace is the in memory model behind the api.
+	Workspace *Workspace
+
+	server   *httptest.Server
+	upgrader websocket.Upgrader
+
+	lock        sync.Mutex
+	handlers    map[string]APIHandler
+	errors      map[string]string
+	calls       map[string][]url.Values
+	connections map[*websocket.Conn]*sync.Mutex
+	sent        []slack.Message
+	pings       int
+	rejectRTM   bool
+	rejectDials bool
+
+	// changed is signaled whenever a socket connects or the client sends something.
+	changed chan struct{}
+}
+
+// URL returns the base url of the server, i.e. `http://127.0.0.1:1234`.
+func (s *Server) URL() string {
+	return s.server.URL
+}
+
+// Host returns the host (and port) of the server.
+func (s *Server) Host() string {
+	return strings.TrimPrefix(s.server.URL, "http://")
+}
+
+// Close disconnects any sockets and shuts the server down.
+func (s *Server) Close() {
+	s.Disconnect()
+	s.server.Close()
+}
+
+// Client returns a new client for the server's token that calls the server instead of slack.
+func (s *Server) Client() *slack.Client {
+	client := slack.NewClient(s.Token)
+	client.SetDebug(false)
+	client.SetAPIEndpoint("http", s.Host())
+	return client
+}
+
+// Handle overrides (or adds) the handler for a web api method, i.e. `chat.postMessage`.
+func (s *Server) Handle(method string, handler APIHandler) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.handlers[method] = handler
+}
+
+// SetError makes a web api method fail with a given slack error code, i.e. `slack.ErrorChannelNotFound`.
+// Setting an error on `rtm.start` also makes reconnects fail.
+func (s *Server) SetError(method, code string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.errors[method] = code
+}
+
+// ClearError removes an error set with SetError.
+func (s *Server) ClearError(method string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.errors, method)
+}
+
+// Calls returns the form values of every call made to a web api method.
+func (s *Server) Calls(method string) []url.Values {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	calls := make([]url.Values, len(s.calls[method]))
+	copy(calls, s.calls[method])
+	return calls
+}
+
+// Connections returns the number of open rtm sockets.
+func (s *Server) Connections() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return len(s.connections)
+}
+
+// Pings returns the number of pings the client has sent.
+func (s *Server) Pings() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.pings
+}
+
+// SentMessages returns the messages the client has sent over the socket (excluding pings).
+func (s *Server) SentMessages() []slack.Message {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	sent := make([]slack.Message, len(s.sent))
+	copy(sent, s.sent)
+	return sent
+}
+
+// WaitForConnections blocks until there are a given number of open sockets.
+func (s *Server) WaitForConnections(count int, timeout time.Duration) error {
+	return s.waitFor(timeout, func() bool { return s.Connections() >= count })
+}
+
+// WaitForSentMessages blocks until the client has sent at least a given number of messages, and returns them.
+func (s *Server) WaitForSentMessages(count int, timeout time.Duration) ([]slack.Message, error) {
+	err := s.waitFor(timeout, func() bool { return len(s.SentMessages()) >= count })
+	return s.SentMessages(), err
+}
+
+// WaitForPings blocks until the client has sent at least a given number of pings.
+func (s *Server) WaitForPings(count int, timeout time.Duration) error {
+	return s.waitFor(timeout, func() bool { return s.Pings() >= count })
+}
+
+// SendEvent writes a raw event to every open socket.
+func (s *Server) SendEvent(event interface{}) error {
+	contents, err := json.Marshal(event)
+	if err != nil {
+		return exception.Wrap(err)
+	}
+	return s.broadcast(contents)
+}
+
+// SendMessage records a message from a user in a channel's history and sends the `message` event.
+func (s *Server) SendMessage(channelID, userID, text string) (slack.Message, error) {
+	m := s.Workspace.AddMessage(channelID, slack.Message{Type: slack.EventMessage, User: userID, Text: text})
+	return m, s.SendEvent(m)
+}
+
+// Disconnect closes every open socket without a close handshake, like a dropped connection.
+func (s *Server) Disconnect() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for conn := range s.connections {
+		conn.Close()
+		delete(s.connections, conn)
+	}
+}
+
+// RejectConnections makes `rtm.start` / `rtm.connect` fail (or succeed again), for testing reconnect logic.
+func (s *Server) RejectConnections(reject bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.rejectRTM = reject
+}
+
+// RejectSockets makes websocket dials fail (or succeed again) while `rtm.start` still succeeds,
+// for testing a reconnect that fails after the session is started.
+func (s *Server) RejectSockets(reject bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.rejectDials = reject
+}
+
+//--------------------------------------------------------------------------------
+// INTERNAL METHODS
+//--------------------------------------------------------------------------------
+
+func (s *Server) handleAPI(w http.ResponseWriter, r *http.Request) {
+	method := strings.TrimPrefix(r.URL.Path, "/api/")
+	if err := parseForm(r); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse(slack.ErrorInvalidFormData))
+		return
+	}
+
+	s.lock.Lock()
+	s.calls[method] = append(s.calls[method], r.Form)
+	handler, hasHandler := s.handlers[method]
+	errorCode := s.errors[method]
+	if s.rejectRTM && (method == "rtm.start" || method == "rtm.connect") {
+		errorCode = slack.ErrorInvalidAuth
+	}
+	s.lock.Unlock()
+
+	if !hasHandler {
+		writeJSON(w, http.StatusNotFound, errorResponse("unknown_method"))
+		return
+	}
+	if r.Form.Get("token") != s.Token {
+		writeJSON(w, http.StatusOK, errorResponse(slack.ErrorInvalidAuth))
+		return
+	}
+	if len(errorCode) > 0 {
+		writeJSON(w, http.StatusOK, errorResponse(errorCode))
+		return
+	}
+	writeJSON(w, http.StatusOK, handler(s, r.Form))
+}
+
+// parseForm parses a request's form; the contents of multipart file parts (i.e. files.upload's `file`)
+// are passed to handlers as form values.
+func parseForm(r *http.Request) error {
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		return r.ParseForm()
+	}
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		return err
+	}
+	for name, headers := range r.MultipartForm.File {
+		for _, header := range headers {
+			f, err := header.Open()
+			if err != nil {
+				return err
+			}
+			contents, err := ioutil.ReadAll(f)
+			f.Close()
+			if err != nil {
+				return err
+			}
+			r.Form.Add(name, string(contents))
+		}
+	}
+	return nil
+}
+
+func (s *Server) handleSocket(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	rejectDials := s.rejectDials
+	s.lock.Unlock()
+	if rejectDials {
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	writeLock := &sync.Mutex{}
+	s.lock.Lock()
+	s.connections[conn] = writeLock
+	s.lock.Unlock()
+	s.notify()
+
+	s.write(conn, writeLock, []byte(`{"type":"hello"}`))
+	go s.readLoop(conn, writeLock)
+}
+
+func (s *Server) readLoop(conn *websocket.Conn, writeLock *sync.Mutex) {
+	defer func() {
+		s.lock.Lock()
+		delete(s.connections, conn)
+		s.lock.Unlock()
+		conn.Close()
+		s.notify()
+	}()
+
+	for {
+		_, contents, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var m slack.Message
+		if err := json.Unmarshal(contents, &m); err != nil {
+			continue
+		}
+
+		if m.Type == slack.EventPing {
+			s.lock.Lock()
+			s.pings++
+			s.lock.Unlock()
+			pong, _ := json.Marshal(map[string]interface{}{"type": slack.EventPong, "reply_to": m.ID})
+			s.write(conn, writeLock, pong)
+			s.notify()
+			continue
+		}
+
+		if m.Type == slack.EventMessage {
+			m.User = s.Workspace.Self.ID
+			m = s.Workspace.AddMessage(m.Channel, m)
+		}
+
+		s.lock.Lock()
+		s.sent = append(s.sent, m)
+		s.lock.Unlock()
+
+		ack, _ := json.Marshal(map[string]interface{}{"ok": true, "reply_to": m.ID, "ts": m.Timestamp, "text": m.Text})
+		s.write(conn, writeLock, ack)
+		s.notify()
+	}
+}
+
+func (s *Server) broadcast(contents []byte) error {
+	s.lock.Lock()
+	connections := map[*websocket.Conn]*sync.Mutex{}
+	for conn, writeLock := range s.connections {
+		connections[conn] = writeLock
+	}
+	s.lock.Unlock()
+
+	if len(connections) == 0 {
+		return exception.New("no open connections.")
+	}
+
+	var err error
+	for conn, writeLock := range connections {
+		if writeErr := s.write(conn, writeLock, contents); writeErr != nil {
+			err = writeErr
+		}
+	}
+	return err
+}
+
+func (s *Server) write(conn *websocket.Conn, writeLock *sync.Mutex, contents []byte) error {
+	writeLock.Lock()
+	defer writeLock.Unlock()
+	return conn.WriteMessage(websocket.TextMessage, contents)
+}
+
+func (s *Server) notify() {
+	select {
+	case s.changed <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Server) waitFor(timeout time.Duration, condition func() bool) error {
+	deadline := time.After(timeout)
+	for !condition() {
+		select {
+		case <-s.changed:
+		case <-time.After(10 * time.Millisecond):
+		case <-deadline:
+			return exception.New("timed out.")
+		}
+	}
+	return nil
+}
+
+func (s *Server) socketURL() string {
+	return "ws://" + s.Host() + "/ws"
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, response interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(response)
+}
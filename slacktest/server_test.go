@@ -0,0 +1,586 @@
+package slacktest
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/blendlabs/go-assert"
+	slack "github.com/wcharczuk/go-slack"
+)
+
+func TestServerConnect(t *testing.T) {
+	a := assert.New(t)
+	server := New()
+	defer server.Close()
+
+	client := server.Client()
+	hello := make(chan bool, 1)
+	client.AddEventListener(slack.EventHello, func(c *slack.Client, m *slack.Message) {
+		hello <- true
+	})
+	received := make(chan *slack.Message, 1)
+	client.AddEventListener(slack.EventMessage, func(c *slack.Client, m *slack.Message) {
+		if m.User == DefaultUserID {
+			received <- m
+		}
+	})
+
+	session, err := client.Connect()
+	a.Nil(err)
+	defer client.Stop()
+	a.Equal(DefaultBotID, session.Self.ID)
+	a.Len(session.Channels, 1)
+
+	select {
+	case <-hello:
+	case <-time.After(time.Second):
+		a.FailNow("timed out waiting for hello")
+	}
+
+	_, err = server.SendMessage(DefaultChannelID, DefaultUserID, "ping")
+	a.Nil(err)
+	select {
+	case m := <-received:
+		a.Equal("ping", m.Text)
+		a.Equal(DefaultChannelID, m.Channel)
+	case <-time.After(time.Second):
+		a.FailNow("timed out waiting for message")
+	}
+
+	a.Nil(client.Say(DefaultChannelID, "pong"))
+	sent, err := server.WaitForSentMessages(1, time.Second)
+	a.Nil(err)
+	a.Equal("pong", sent[0].Text)
+	a.Len(server.Workspace.History(DefaultChannelID), 2)
+}
+
+func TestServerPings(t *testing.T) {
+	a := assert.New(t)
+	server := New()
+	defer server.Close()
+
+	client := server.Client()
+	client.SetPingInterval(10 * time.Millisecond)
+	_, err := client.Connect()
+	a.Nil(err)
+	defer client.Stop()
+
+	a.Nil(server.WaitForPings(3, time.Second))
+	a.Empty(server.SentMessages())
+}
+
+func TestServerDisconnect(t *testing.T) {
+	a := assert.New(t)
+	server := New()
+	defer server.Close()
+
+	client := server.Client()
+	_, err := client.Connect()
+	a.Nil(err)
+	defer client.Stop()
+	a.Nil(server.WaitForConnections(1, time.Second))
+
+	server.Disconnect()
+	a.Nil(server.WaitForConnections(1, time.Second))
+	a.Len(server.Calls("rtm.start"), 2)
+
+	a.Nil(client.Say(DefaultChannelID, "still here"))
+	sent, err := server.WaitForSentMessages(1, time.Second)
+	a.Nil(err)
+	a.Equal("still here", sent[0].Text)
+}
+
+func TestServerFailedReconnect(t *testing.T) {
+	a := assert.New(t)
+	server := New()
+	defer server.Close()
+
+	client := server.Client()
+	client.SetReconnectDelay(10 * time.Millisecond)
+	received := make(chan string, 1)
+	client.AddEventListener(slack.EventMessage, func(c *slack.Client, m *slack.Message) {
+		received <- m.Text
+	})
+	_, err := client.Connect()
+	a.Nil(err)
+	defer client.Stop()
+	a.Nil(server.WaitForConnections(1, time.Second))
+
+	// the first reconnect starts a session but can't open the socket.
+	server.RejectSockets(true)
+	server.Disconnect()
+	deadline := time.Now().Add(time.Second)
+	for len(server.Calls("rtm.start")) < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	server.RejectSockets(false)
+	a.Nil(server.WaitForConnections(1, time.Second))
+
+	_, err = server.SendMessage(DefaultChannelID, DefaultUserID, "still listening")
+	a.Nil(err)
+	select {
+	case text := <-received:
+		a.Equal("still listening", text)
+	case <-time.After(time.Second):
+		a.FailNow("timed out waiting for the message")
+	}
+}
+
+func TestServerStopConnect(t *testing.T) {
+	a := assert.New(t)
+	server := New()
+	defer server.Close()
+
+	client := server.Client()
+	client.SetPingInterval(50 * time.Millisecond)
+	var lock sync.Mutex
+	var pings int
+	client.AddEventListener("ping", func(c *slack.Client, m *slack.Message) {
+		lock.Lock()
+		pings++
+		lock.Unlock()
+	})
+
+	_, err := client.Connect()
+	a.Nil(err)
+	a.Nil(client.Stop())
+	_, err = client.Connect()
+	a.Nil(err)
+	defer client.Stop()
+
+	// only the new session's ping loop is running.
+	time.Sleep(260 * time.Millisecond)
+	lock.Lock()
+	defer lock.Unlock()
+	a.True(pings <= 6, "pings", pings)
+}
+
+func TestServerAPI(t *testing.T) {
+	a := assert.New(t)
+	server := New()
+	defer server.Close()
+	client := server.Client()
+
+	auth, err := client.AuthTest()
+	a.Nil(err)
+	a.Equal(DefaultBotID, auth.UserID)
+
+	posted, err := client.ChatPostMessage(slack.NewChatMessage(DefaultChannelID, "hello"))
+	a.Nil(err)
+	a.Equal(DefaultChannelID, posted.Channel)
+	a.Len(server.Calls("chat.postMessage"), 1)
+	a.Equal("hello", server.Calls("chat.postMessage")[0].Get("text"))
+
+	history, err := client.ChannelsHistory(DefaultChannelID, nil, nil, 10, false)
+	a.Nil(err)
+	a.Len(history.Messages, 1)
+	a.Equal(posted.Timestamp.String(), history.Messages[0].Timestamp.String())
+
+	a.Nil(client.ChannelsSetTopic(DefaultChannelID, "testing"))
+	channel, err := client.ChannelsInfo(DefaultChannelID)
+	a.Nil(err)
+	a.Equal("testing", channel.Topic.Value)
+
+	server.SetError("chat.postMessage", slack.ErrorChannelNotFound)
+	_, err = client.ChatPostMessage(slack.NewChatMessage(DefaultChannelID, "hello"))
+	a.NotNil(err)
+	a.Equal(slack.ErrorChannelNotFound, err.Error())
+	server.ClearError("chat.postMessage")
+
+	bad := slack.NewClient("not-the-token")
+	bad.SetAPIEndpoint("http", server.Host())
+	_, err = bad.AuthTest()
+	a.NotNil(err)
+	a.Equal(slack.ErrorInvalidAuth, err.Error())
+}
+
+func TestServerUsers(t *testing.T) {
+	a := assert.New(t)
+	server := New()
+	defer server.Close()
+	client := server.Client()
+
+	user, err := client.UsersInfo(DefaultUserID)
+	a.Nil(err)
+	a.Equal("user", user.Name)
+
+	user, err = client.UsersLookupByEmail("USER@example.com")
+	a.Nil(err)
+	a.Equal(DefaultUserID, user.ID)
+	_, err = client.UsersLookupByEmail("nobody@example.com")
+	a.NotNil(err)
+	a.Equal(slack.ErrorUsersNotFound, err.Error())
+
+	a.Nil(client.UsersSetPresence(slack.PresenceAway))
+	presence, err := client.UsersGetPresence(DefaultBotID)
+	a.Nil(err)
+	a.Equal(slack.PresenceAway, presence.Presence)
+
+	profile, err := client.UsersProfileSet("", map[string]interface{}{"status_text": "testing"}, map[string]slack.UserProfileField{"Xf0TEAM": {Value: "Bots"}})
+	a.Nil(err)
+	a.Equal("testing", profile.StatusText)
+	profile, err = client.UsersProfileGet(DefaultBotID, false)
+	a.Nil(err)
+	a.Equal("Bot", profile.RealName)
+	a.Equal("Bots", profile.Fields["Xf0TEAM"].Value)
+
+	users, err := client.UsersList()
+	a.Nil(err)
+	first, err := client.UsersListPage(1, "")
+	a.Nil(err)
+	a.Len(first.Users, 1)
+	a.Equal(users[0].ID, first.Users[0].ID)
+	a.NotEmpty(first.ResponseMetadata.NextCursor)
+	rest, err := client.UsersListPage(len(users), first.ResponseMetadata.NextCursor)
+	a.Nil(err)
+	a.Len(rest.Users, len(users)-1)
+	a.Empty(rest.ResponseMetadata.NextCursor)
+	_, err = client.UsersListPage(1, "not-a-cursor")
+	a.NotNil(err)
+	a.Equal(slack.ErrorInvalidCursor, err.Error())
+}
+
+func TestServerPinsAndStars(t *testing.T) {
+	a := assert.New(t)
+	server := New()
+	defer server.Close()
+	client := server.Client()
+
+	m := server.Workspace.AddMessage(DefaultChannelID, slack.Message{User: DefaultUserID, Text: "pin me"})
+	a.Nil(client.PinsAdd(DefaultChannelID, slack.MessageRef(DefaultChannelID, *m.Timestamp)))
+	err := client.PinsAdd(DefaultChannelID, slack.MessageRef(DefaultChannelID, *m.Timestamp))
+	a.NotNil(err)
+	a.Equal("already_pinned", err.Error())
+
+	pins, err := client.PinsList(DefaultChannelID)
+	a.Nil(err)
+	a.Len(pins, 1)
+	a.Equal("pin me", pins[0].Message.Text)
+	a.Equal(DefaultBotID, pins[0].CreatedBy)
+	a.Nil(client.PinsRemove(DefaultChannelID, slack.MessageRef(DefaultChannelID, *m.Timestamp)))
+
+	a.Nil(client.StarsAdd(slack.ChannelRef(DefaultChannelID)))
+	a.Nil(client.StarsAdd(slack.FileRef("F0FILE")))
+	stars, err := client.StarsList(0, "")
+	a.Nil(err)
+	a.Len(stars.Items, 2)
+	a.Equal(slack.ItemTypeChannel, stars.Items[0].Type)
+	a.Nil(client.StarsRemove(slack.FileRef("F0FILE")))
+	err = client.StarsRemove(slack.FileRef("F0FILE"))
+	a.NotNil(err)
+	a.Equal("not_starred", err.Error())
+}
+
+func TestServerReactions(t *testing.T) {
+	a := assert.New(t)
+	server := New()
+	defer server.Close()
+	client := server.Client()
+
+	m := server.Workspace.AddMessage(DefaultChannelID, slack.Message{User: DefaultUserID, Text: "react to me"})
+	item := slack.MessageRef(DefaultChannelID, *m.Timestamp)
+	a.Nil(client.ReactionsEnsure("thumbsup", item))
+	a.Nil(client.ReactionsEnsure("thumbsup", item))
+
+	res, err := client.ReactionsGet(item, true)
+	a.Nil(err)
+	a.Equal(slack.ItemTypeMessage, res.Type)
+	a.Len(res.Reactions(), 1)
+	a.Equal(1, res.Reactions()[0].Count)
+
+	list, err := client.ReactionsList("", false, 0, "")
+	a.Nil(err)
+	a.Len(list.Items, 1)
+	a.Equal("react to me", list.Items[0].Message.Text)
+
+	added, err := client.ReactionsToggle("thumbsup", item)
+	a.Nil(err)
+	a.False(added)
+	added, err = client.ReactionsToggle("thumbsup", item)
+	a.Nil(err)
+	a.True(added)
+}
+
+func TestServerReminders(t *testing.T) {
+	a := assert.New(t)
+	server := New()
+	defer server.Close()
+	client := server.Client()
+
+	reminder, err := client.RemindersAdd("hand off on-call", "in 2 hours", DefaultUserID, nil)
+	a.Nil(err)
+	a.Equal(DefaultUserID, reminder.User)
+	a.NotNil(reminder.Time)
+	a.False(reminder.IsComplete())
+	weekly, err := client.RemindersAdd("rotate the pager", "every monday at 9am", "", &slack.ReminderRecurrence{Frequency: slack.RecurrenceWeekly, Weekdays: []string{"monday"}})
+	a.Nil(err)
+	a.True(weekly.Recurring)
+	_, err = client.RemindersAdd("never", "whenever", "", nil)
+	a.NotNil(err)
+
+	a.Nil(client.RemindersComplete(reminder.ID))
+	err = client.RemindersComplete(weekly.ID)
+	a.NotNil(err)
+	a.True(slack.IsAPIError(err, slack.ErrorCannotCompleteRecurring))
+	reminder, err = client.RemindersInfo(reminder.ID)
+	a.Nil(err)
+	a.True(reminder.IsComplete())
+
+	a.Nil(client.RemindersDelete(reminder.ID))
+	reminders, err := client.RemindersList()
+	a.Nil(err)
+	a.Len(reminders, 1)
+	a.Equal("rotate the pager", reminders[0].Text)
+}
+
+func TestServerUsergroups(t *testing.T) {
+	a := assert.New(t)
+	server := New()
+	defer server.Close()
+
+	client := server.Client()
+	changed := make(chan *slack.Message, 1)
+	client.AddEventListener(slack.EventSubteamMembersChanged, func(c *slack.Client, m *slack.Message) {
+		changed <- m
+	})
+	_, err := client.Connect()
+	a.Nil(err)
+	defer client.Stop()
+
+	userGroup, err := client.UsergroupsCreate("On-call", "oncall", "", []string{DefaultChannelID})
+	a.Nil(err)
+	_, err = client.UsergroupsCreate("On-call", "", "", nil)
+	a.NotNil(err)
+
+	_, err = client.UsergroupsUsersUpdate(userGroup.ID, []string{DefaultUserID})
+	a.Nil(err)
+	select {
+	case m := <-changed:
+		a.Equal(userGroup.ID, m.SubteamID)
+		a.Equal([]string{DefaultUserID}, m.AddedUsers)
+	case <-time.After(time.Second):
+		a.FailNow("timed out waiting for subteam_members_changed")
+	}
+	client.WaitForListeners()
+	cached, hasUserGroup := client.State().UserGroup(userGroup.ID)
+	a.True(hasUserGroup)
+	a.Equal([]string{DefaultUserID}, cached.Users)
+	a.Equal("@oncall", client.PlainText("<!subteam^"+userGroup.ID+">"))
+
+	updated, err := client.UsergroupsUpdate(userGroup.ID, nil, slack.OptionalString("primary"), nil, nil)
+	a.Nil(err)
+	a.Equal("On-call", updated.Name)
+	a.Equal("primary", updated.Handle)
+
+	_, err = client.UsergroupsDisable(userGroup.ID)
+	a.Nil(err)
+	userGroups, err := client.UsergroupsList(false, false, false)
+	a.Nil(err)
+	a.Empty(userGroups)
+	userGroups, err = client.UsergroupsList(true, true, true)
+	a.Nil(err)
+	a.Len(userGroups, 1)
+	a.False(userGroups[0].IsEnabled())
+	a.Equal("1", userGroups[0].UserCount.String())
+
+	_, err = client.UsergroupsEnable(userGroup.ID)
+	a.Nil(err)
+	users, err := client.UsergroupsUsersList(userGroup.ID, false)
+	a.Nil(err)
+	a.Equal([]string{DefaultUserID}, users)
+}
+
+func TestServerTeamAndDnd(t *testing.T) {
+	a := assert.New(t)
+	server := New()
+	defer server.Close()
+	client := server.Client()
+	server.Workspace.Bots["B0BOT"] = &slack.Bot{ID: "B0BOT", Name: "bot", UserID: DefaultBotID}
+
+	team, err := client.TeamInfo()
+	a.Nil(err)
+	a.Equal(DefaultTeamID, team.ID)
+	billable, err := client.TeamBillableInfo("")
+	a.Nil(err)
+	a.True(billable[DefaultUserID].BillingActive)
+	a.False(billable[DefaultBotID].BillingActive)
+	bot, err := client.BotsInfo("B0BOT")
+	a.Nil(err)
+	a.Equal(DefaultBotID, bot.UserID)
+
+	status, err := client.DndSetSnooze(10)
+	a.Nil(err)
+	a.Equal(600, status.SnoozeRemaining)
+	status, err = client.DndInfo("")
+	a.Nil(err)
+	a.True(status.SnoozeEnabled)
+	cached, hasDND := client.State().SelfDND()
+	a.True(hasDND)
+	a.True(cached.SnoozeEnabled)
+
+	status, err = client.DndEndSnooze()
+	a.Nil(err)
+	a.False(status.SnoozeEnabled)
+	_, err = client.DndEndSnooze()
+	a.NotNil(err)
+	a.Nil(client.DndEndDnd())
+
+	users, err := client.DndTeamInfo([]string{DefaultUserID})
+	a.Nil(err)
+	a.False(users[DefaultUserID].DNDEnabled)
+}
+
+func TestServerSearch(t *testing.T) {
+	a := assert.New(t)
+	server := New()
+	defer server.Close()
+	client := server.Client()
+
+	for _, text := range []string{"pages are firing", "db outage, failing over", "outage resolved", "see <https://example.com/postmortem> for the outage"} {
+		server.Workspace.AddMessage(DefaultChannelID, slack.Message{User: DefaultUserID, Text: text})
+	}
+
+	res, err := client.SearchMessages(slack.NewSearchQuery("outage").In("general").From("user").String(), &slack.SearchParameters{Count: 2})
+	a.Nil(err)
+	a.Equal(3, res.Messages.Total)
+	a.Len(res.Messages.Matches, 2)
+	a.Equal(2, res.Messages.Pagination.PageCount)
+	a.Equal("see <https://example.com/postmortem> for the outage", res.Messages.Matches[0].Text)
+
+	var texts []string
+	err = client.SearchMessagesEach("outage", &slack.SearchParameters{Count: 2, SortDir: slack.SearchSortAsc}, func(m slack.SearchMessage) bool {
+		texts = append(texts, m.Text)
+		return true
+	})
+	a.Nil(err)
+	a.Equal([]string{"db outage, failing over", "outage resolved", "see <https://example.com/postmortem> for the outage"}, texts)
+	res, err = client.SearchMessages(slack.NewSearchQuery("outage").Has(slack.SearchHasLink).Exclude("resolved").String(), nil)
+	a.Nil(err)
+	a.Equal(1, res.Messages.Total)
+	a.Equal("outage resolved", res.Messages.Matches[0].ContextBefore()[0].Text)
+
+	texts = nil
+	err = client.SearchMessagesEach("outage", &slack.SearchParameters{Count: 1}, func(m slack.SearchMessage) bool {
+		texts = append(texts, m.Text)
+		return len(texts) < 2
+	})
+	a.Nil(err)
+	a.Len(texts, 2)
+}
+
+func TestServerConversations(t *testing.T) {
+	a := assert.New(t)
+	server := New()
+	defer server.Close()
+
+	client := server.Client()
+	_, err := client.Connect()
+	a.Nil(err)
+	defer client.Stop()
+
+	a.Nil(client.DirectMessage(DefaultUserID, "hello"))
+	im, hasIM := client.State().IMForUser(DefaultUserID)
+	a.True(hasIM)
+	history, err := client.ImHistory(im.ID, nil, nil, 10, false)
+	a.Nil(err)
+	a.Len(history.Messages, 1)
+	a.Equal("hello", history.Messages[0].Text)
+
+	a.Nil(client.DirectMessage(DefaultUserID, "again"))
+	ims, err := client.ImList()
+	a.Nil(err)
+	a.Len(ims, 1)
+	a.Len(server.Workspace.History(im.ID), 2)
+
+	group, err := client.GroupsCreate("secretplans")
+	a.Nil(err)
+	_, err = client.GroupsCreate("general")
+	a.NotNil(err)
+	group, err = client.GroupsInvite(group.ID, DefaultUserID)
+	a.Nil(err)
+	a.Equal([]string{DefaultBotID, DefaultUserID}, group.Members)
+	a.Nil(client.GroupsSetTopic(group.ID, "plans"))
+	a.Nil(client.GroupsRename(group.ID, "classified"))
+	a.Nil(client.GroupsArchive(group.ID))
+	a.NotNil(client.GroupsArchive(group.ID))
+	groups, err := client.GroupsList(true)
+	a.Nil(err)
+	a.Empty(groups)
+	a.Nil(client.GroupsUnarchive(group.ID))
+	group, err = client.GroupsInfo(group.ID)
+	a.Nil(err)
+	a.Equal("classified", group.Name)
+	a.Equal("plans", group.Topic.Value)
+	client.WaitForListeners()
+	a.Equal("classified", client.State().ChannelName(group.ID))
+
+	_, err = client.MpimOpen([]string{DefaultUserID})
+	a.NotNil(err)
+	server.Workspace.AddUser(slack.User{ID: "U0OTHER", Name: "other"})
+	mpim, err := client.MpimOpen([]string{DefaultUserID, "U0OTHER"})
+	a.Nil(err)
+	a.True(mpim.IsMPIM)
+	reopened, err := client.MpimOpen([]string{"U0OTHER", DefaultUserID})
+	a.Nil(err)
+	a.Equal(mpim.ID, reopened.ID)
+	mpims, err := client.MpimList()
+	a.Nil(err)
+	a.Len(mpims, 1)
+	groups, err = client.GroupsList(false)
+	a.Nil(err)
+	a.Len(groups, 1)
+
+	a.Nil(client.GroupsKick(group.ID, DefaultUserID))
+	a.NotNil(client.GroupsLeave(group.ID))
+}
+
+func TestServerChannelsAdmin(t *testing.T) {
+	a := assert.New(t)
+	server := New()
+	defer server.Close()
+	server.Workspace.AddUser(slack.User{ID: "U0OTHER", Name: "other"})
+
+	client := server.Client()
+	_, err := client.Connect()
+	a.Nil(err)
+	defer client.Stop()
+	client.WaitForListeners()
+
+	channel, err := client.ChannelsCreate("inc-1234")
+	a.Nil(err)
+	_, err = client.ChannelsCreate("inc-1234")
+	a.True(slack.IsAPIError(err, slack.ErrorNameTaken))
+	a.True(containsString(client.ActiveChannelIDs(), channel.ID))
+
+	channel, err = client.InviteUsers(channel.ID, DefaultBotID, DefaultUserID, "U0OTHER", DefaultUserID)
+	a.Nil(err)
+	a.Equal([]string{DefaultBotID, DefaultUserID, "U0OTHER"}, channel.Members)
+	_, err = client.InviteUsers(channel.ID, "U0MISSING")
+	a.True(slack.IsAPIError(err, slack.ErrorUserNotFound))
+
+	a.Nil(client.ChannelsKick(channel.ID, "U0OTHER"))
+	a.NotNil(client.ChannelsKick(channel.ID, "U0OTHER"))
+	renamed, err := client.ChannelsRename(channel.ID, "inc-1234-resolved")
+	a.Nil(err)
+	a.Equal("inc-1234-resolved", renamed.Name)
+
+	a.Nil(client.ChannelsArchive(channel.ID))
+	a.True(slack.IsAPIError(client.ChannelsArchive(channel.ID), slack.ErrorAlreadyArchived))
+	client.WaitForListeners()
+	a.False(containsString(client.ActiveChannelIDs(), channel.ID))
+	_, err = client.ChannelsJoin("inc-1234-resolved")
+	a.True(slack.IsAPIError(err, slack.ErrorChannelIsArchived))
+
+	a.Nil(client.ChannelsUnarchive(channel.ID))
+	a.True(containsString(client.ActiveChannelIDs(), channel.ID))
+
+	server.Workspace.AddChannel(slack.Channel{ID: "C0RANDOM", Name: "random", Members: []string{DefaultUserID}})
+	joined, err := client.ChannelsJoin("#random")
+	a.Nil(err)
+	a.Equal([]string{DefaultUserID, DefaultBotID}, joined.Members)
+	a.True(containsString(client.ActiveChannelIDs(), "C0RANDOM"))
+	a.Equal("random", client.State().ChannelName("C0RANDOM"))
+	a.Nil(client.ChannelsLeave("C0RANDOM"))
+	a.False(containsString(client.ActiveChannelIDs(), "C0RANDOM"))
+}
@@ -0,0 +1,457 @@
+package slacktest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	slack "github.com/wcharczuk/go-slack"
+)
+
+// Default workspace ids.
+const (
+	// DefaultToken is the token the server accepts by default.
+	DefaultToken = "xoxb-slacktest"
+	// DefaultTeamID is the id of the default team.
+	DefaultTeamID = "T0TEST"
+	// DefaultBotID is the user id of the bot the client is authenticated as.
+	DefaultBotID = "U0BOT"
+	// DefaultUserID is the user id of a regular user in the default workspace.
+	DefaultUserID = "U0USER"
+	// DefaultChannelID is the id of the default `#general` channel.
+	DefaultChannelID = "C0GENERAL"
+)
+
+// NewWorkspace returns a workspace with a team, a bot user, a regular user and a `#general` channel.
+func NewWorkspace() *Workspace {
+	w := &Workspace{
+		Team:              slack.Team{ID: DefaultTeamID, Name: "slacktest", EmailDomain: "example.com"},
+		Self:              slack.Self{ID: DefaultBotID, Name: "bot"},
+		Users:             map[string]*slack.User{},
+		Channels:          map[string]*slack.Channel{},
+		Groups:            map[string]*slack.Group{},
+		IMs:               map[string]*slack.InstantMessage{},
+		Messages:          map[string][]slack.Message{},
+		Emoji:             map[string]string{"shipit": "https://emoji.example.com/shipit.png"},
+		ScheduledMessages: map[string]slack.ScheduledMessage{},
+		Pins:              map[string][]slack.Item{},
+		Reminders:         map[string]slack.Reminder{},
+		UserGroups:        map[string]*slack.UserGroup{},
+		Bots:              map[string]*slack.Bot{},
+		DND:               map[string]slack.DNDStatus{},
+		Files:             map[string]slack.File{},
+		now:               time.Now,
+	}
+	w.AddUser(slack.User{ID: DefaultBotID, Name: "bot", IsBot: true, Profile: &slack.UserProfile{RealName: "Bot"}})
+	w.AddUser(slack.User{ID: DefaultUserID, Name: "user", Profile: &slack.UserProfile{RealName: "Test User", Email: "user@example.com"}})
+	w.AddChannel(slack.Channel{ID: DefaultChannelID, Name: "general", IsGeneral: true, Members: []string{DefaultBotID, DefaultUserID}})
+	return w
+}
+
+// Workspace is the in memory model the fake server reads from and writes to.
+type Workspace struct {
+	sync.Mutex
+
+	Team              slack.Team
+	Self              slack.Self
+	Users             map[string]*slack.User
+	Channels          map[string]*slack.Channel
+	Groups            map[string]*slack.Group
+	IMs               map[string]*slack.InstantMessage
+	Messages          map[string][]slack.Message
+	Emoji             map[string]string
+	ScheduledMessages map[string]slack.ScheduledMessage
+	Pins              map[string][]slack.Item
+	Stars             []slack.Item
+	Reminders         map[string]slack.Reminder
+	UserGroups        map[string]*slack.UserGroup
+	Bots              map[string]*slack.Bot
+	DND               map[string]slack.DNDStatus
+	Files             map[string]slack.File
+
+	sequence int64
+	now      func() time.Time
+}
+
+// AddUser adds (or replaces) a user.
+func (w *Workspace) AddUser(user slack.User) {
+	w.Lock()
+	defer w.Unlock()
+	w.Users[user.ID] = &user
+}
+
+// AddChannel adds (or replaces) a channel; channels are created as channels the bot is a member of.
+func (w *Workspace) AddChannel(channel slack.Channel) {
+	w.Lock()
+	defer w.Unlock()
+
+	channel.IsChannel = true
+	if channel.Created.Time().IsZero() {
+		channel.Created = slack.NewTimestamp(w.now())
+	}
+	if !channel.IsMember {
+		for _, member := range channel.Members {
+			if member == w.Self.ID {
+				channel.IsMember = true
+			}
+		}
+	}
+	w.Channels[channel.ID] = &channel
+}
+
+// AddGroup adds (or replaces) a private channel, or a multiparty direct message if `IsMPIM` is set.
+func (w *Workspace) AddGroup(group slack.Group) {
+	w.Lock()
+	defer w.Unlock()
+
+	group.IsGroup = true
+	if group.Created.Time().IsZero() {
+		group.Created = slack.NewTimestamp(w.now())
+	}
+	w.Groups[group.ID] = &group
+}
+
+// AddIM opens a direct message channel between the bot and a user, returning the existing one if there is one.
+func (w *Workspace) AddIM(userID string) slack.InstantMessage {
+	w.Lock()
+	defer w.Unlock()
+
+	for _, im := range w.IMs {
+		if im.User == userID {
+			return *im
+		}
+	}
+	return w.addIM(userID)
+}
+
+// AddMessage appends a message to a channel's history, assigning it a timestamp if it doesn't have one.
+func (w *Workspace) AddMessage(channelID string, m slack.Message) slack.Message {
+	w.Lock()
+	defer w.Unlock()
+	return w.addMessage(channelID, m)
+}
+
+// History returns a copy of a channel's messages, oldest first.
+func (w *Workspace) History(channelID string) []slack.Message {
+	w.Lock()
+	defer w.Unlock()
+	history := make([]slack.Message, len(w.Messages[channelID]))
+	copy(history, w.Messages[channelID])
+	return history
+}
+
+// NextTimestamp returns a new, unique message timestamp.
+func (w *Workspace) NextTimestamp() slack.Timestamp {
+	w.Lock()
+	defer w.Unlock()
+	return w.nextTimestamp()
+}
+
+//--------------------------------------------------------------------------------
+// INTERNAL METHODS
+//--------------------------------------------------------------------------------
+
+// session returns the rtm.start payload; the caller must hold the lock.
+func (w *Workspace) session(url string) slack.Session {
+	session := slack.Session{
+		OK:   true,
+		URL:  url,
+		Self: &slack.Self{ID: w.Self.ID, Name: w.Self.Name, Created: w.Self.Created},
+		Team: &slack.Team{ID: w.Team.ID, Name: w.Team.Name, EmailDomain: w.Team.EmailDomain},
+	}
+	for _, id := range w.userIDs() {
+		session.Users = append(session.Users, *w.Users[id])
+	}
+	for _, id := range w.channelIDs() {
+		session.Channels = append(session.Channels, *w.Channels[id])
+	}
+	session.Groups = w.memberGroups(false)
+	for _, id := range w.imIDs() {
+		session.IMs = append(session.IMs, *w.IMs[id])
+	}
+	return session
+}
+
+// addChannel creates a channel with the bot as its only member; the caller must hold the lock.
+func (w *Workspace) addChannel(name string) slack.Channel {
+	w.sequence++
+	channel := &slack.Channel{
+		ID:        fmt.Sprintf("C%08d", w.sequence),
+		Name:      name,
+		IsChannel: true,
+		Created:   slack.NewTimestamp(w.now()),
+		Creator:   w.Self.ID,
+		IsMember:  true,
+		Members:   []string{w.Self.ID},
+	}
+	w.Channels[channel.ID] = channel
+	return *channel
+}
+
+// addIM adds a direct message channel with a user; the caller must hold the lock.
+func (w *Workspace) addIM(userID string) slack.InstantMessage {
+	w.sequence++
+	im := &slack.InstantMessage{
+		ID:      fmt.Sprintf("D%08d", w.sequence),
+		IsIM:    true,
+		User:    userID,
+		Created: slack.NewTimestamp(w.now()),
+		IsOpen:  true,
+	}
+	w.IMs[im.ID] = im
+	return *im
+}
+
+// hasConversation returns if a channel, private channel or direct message exists; the caller must hold the lock.
+func (w *Workspace) hasConversation(channelID string) bool {
+	_, hasChannel := w.Channels[channelID]
+	_, hasGroup := w.Groups[channelID]
+	_, hasIM := w.IMs[channelID]
+	return hasChannel || hasGroup || hasIM
+}
+
+// conversationNamed returns if a channel or private channel has a name; the caller must hold the lock.
+func (w *Workspace) conversationNamed(name string) bool {
+	for _, channel := range w.Channels {
+		if channel.Name == name {
+			return true
+		}
+	}
+	for _, group := range w.Groups {
+		if group.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// group returns a private channel the bot is a member of; the caller must hold the lock.
+func (w *Workspace) group(channelID string) (*slack.Group, bool) {
+	group, hasGroup := w.Groups[channelID]
+	if !hasGroup || group.IsMPIM || !containsString(group.Members, w.Self.ID) {
+		return nil, false
+	}
+	return group, true
+}
+
+// mpim returns a multiparty direct message the bot is a member of; the caller must hold the lock.
+func (w *Workspace) mpim(channelID string) (*slack.Group, bool) {
+	group, hasGroup := w.Groups[channelID]
+	if !hasGroup || !group.IsMPIM || !containsString(group.Members, w.Self.ID) {
+		return nil, false
+	}
+	return group, true
+}
+
+// memberGroups returns the private channels (or multiparty direct messages) the bot is a member of, by id.
+func (w *Workspace) memberGroups(mpim bool) []slack.Group {
+	var ids []string
+	for id, group := range w.Groups {
+		if group.IsMPIM == mpim && containsString(group.Members, w.Self.ID) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	groups := []slack.Group{}
+	for _, id := range ids {
+		groups = append(groups, *w.Groups[id])
+	}
+	return groups
+}
+
+// history returns a page of a conversation's messages, newest first, for the `*.history` calls.
+func (w *Workspace) history(form url.Values) slack.ChannelsHistoryResponse {
+	count := 100
+	if parsed, err := strconv.Atoi(form.Get("count")); err == nil && parsed > 0 {
+		count = parsed
+	}
+	latest, oldest := parseFloat(form.Get("latest")), parseFloat(form.Get("oldest"))
+
+	messages := []slack.Message{}
+	channelID := form.Get("channel")
+	all := w.Messages[channelID]
+	hasMore := false
+	for index := len(all) - 1; index >= 0; index-- {
+		m := all[index]
+		ts := parseFloat(m.Timestamp.String())
+		if (latest > 0 && ts >= latest) || (oldest > 0 && ts <= oldest) {
+			continue
+		}
+		// like slack, thread replies are only in history if they were also sent to the channel.
+		if isReply(m) && m.SubType != "thread_broadcast" {
+			continue
+		}
+		if len(messages) == count {
+			hasMore = true
+			break
+		}
+		m.ReplyCount = len(w.threadReplies(channelID, m.Timestamp.String()))
+		messages = append(messages, m)
+	}
+	return slack.ChannelsHistoryResponse{OK: true, Messages: messages, HasMore: hasMore}
+}
+
+// threadReplies returns the replies to a message, oldest first; the caller must hold the lock.
+func (w *Workspace) threadReplies(channelID, threadTS string) []slack.Message {
+	var replies []slack.Message
+	for _, m := range w.Messages[channelID] {
+		if isReply(m) && m.ThreadTimestamp.String() == threadTS {
+			replies = append(replies, m)
+		}
+	}
+	return replies
+}
+
+// replies returns a thread, the parent message followed by its replies, for the `*.replies` calls.
+func (w *Workspace) replies(form url.Values) interface{} {
+	channelID, threadTS := form.Get("channel"), form.Get("thread_ts")
+	index, found := w.findMessage(channelID, threadTS)
+	if !found {
+		return errorResponse("thread_not_found")
+	}
+	parent := w.Messages[channelID][index]
+	replies := w.threadReplies(channelID, threadTS)
+	parent.ReplyCount = len(replies)
+	return response{"ok": true, "has_more": false, "messages": append([]slack.Message{parent}, replies...)}
+}
+
+func isReply(m slack.Message) bool {
+	return m.ThreadTimestamp != nil && m.Timestamp != nil && m.ThreadTimestamp.String() != m.Timestamp.String()
+}
+
+func (w *Workspace) addMessage(channelID string, m slack.Message) slack.Message {
+	if m.Timestamp == nil {
+		ts := w.nextTimestamp()
+		m.Timestamp = &ts
+	}
+	if len(m.Type) == 0 {
+		m.Type = slack.EventMessage
+	}
+	m.Channel = channelID
+	w.Messages[channelID] = append(w.Messages[channelID], m)
+	return m
+}
+
+func (w *Workspace) findMessage(channelID, ts string) (int, bool) {
+	for index, m := range w.Messages[channelID] {
+		if m.Timestamp != nil && m.Timestamp.String() == ts {
+			return index, true
+		}
+	}
+	return -1, false
+}
+
+// item returns the item a pins / stars call refers to, or an error code; the caller must hold the lock.
+func (w *Workspace) item(form url.Values) (slack.Item, string) {
+	switch {
+	case len(form.Get("file")) > 0:
+		return slack.Item{Type: slack.ItemTypeFile, File: &slack.File{ID: form.Get("file")}}, ""
+	case len(form.Get("file_comment")) > 0:
+		return slack.Item{Type: slack.ItemTypeFileComment, Comment: &slack.FileComment{ID: form.Get("file_comment")}}, ""
+	case len(form.Get("timestamp")) > 0:
+		channelID := form.Get("channel")
+		index, found := w.findMessage(channelID, form.Get("timestamp"))
+		if !found {
+			return slack.Item{}, slack.ErrorMessageNotFound
+		}
+		m := w.Messages[channelID][index]
+		return slack.Item{Type: slack.ItemTypeMessage, Channel: channelID, Message: &m}, ""
+	case len(form.Get("channel")) > 0:
+		if _, hasChannel := w.Channels[form.Get("channel")]; !hasChannel {
+			return slack.Item{}, slack.ErrorChannelNotFound
+		}
+		return slack.Item{Type: slack.ItemTypeChannel, Channel: form.Get("channel")}, ""
+	default:
+		return slack.Item{}, slack.ErrorNoItemSpecified
+	}
+}
+
+func (w *Workspace) nextTimestamp() slack.Timestamp {
+	w.sequence++
+	var ts slack.Timestamp
+	json.Unmarshal([]byte(fmt.Sprintf(`"%d.%06d"`, w.now().Unix(), w.sequence)), &ts)
+	return ts
+}
+
+func (w *Workspace) userIDs() []string {
+	var ids []string
+	for id := range w.Users {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func (w *Workspace) imIDs() []string {
+	var ids []string
+	for id := range w.IMs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func (w *Workspace) channelIDs() []string {
+	var ids []string
+	for id := range w.Channels {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// searchMatches returns if a message matches a search query.
+func (w *Workspace) searchMatches(query string, channel *slack.Channel, m slack.Message) bool {
+	text := strings.ToLower(m.Text)
+	for _, part := range strings.Fields(query) {
+		switch {
+		case strings.HasPrefix(part, "in:#"):
+			if channel == nil || channel.Name != strings.TrimPrefix(part, "in:#") {
+				return false
+			}
+		case strings.HasPrefix(part, "from:@"):
+			if user, hasUser := w.Users[m.User]; !hasUser || user.Name != strings.TrimPrefix(part, "from:@") {
+				return false
+			}
+		case strings.HasPrefix(part, "before:"), strings.HasPrefix(part, "after:"):
+			day, err := time.ParseInLocation("2006-01-02", part[strings.Index(part, ":")+1:], time.Local)
+			if err != nil {
+				return false
+			}
+			if strings.HasPrefix(part, "before:") && !m.Timestamp.Time().Before(day) {
+				return false
+			}
+			if strings.HasPrefix(part, "after:") && m.Timestamp.Time().Before(day.AddDate(0, 0, 1)) {
+				return false
+			}
+		case part == "has:link":
+			if !strings.Contains(m.Text, "<http") {
+				return false
+			}
+		case strings.HasPrefix(part, "-"):
+			if strings.Contains(text, strings.ToLower(part[1:])) {
+				return false
+			}
+		default:
+			if !strings.Contains(text, strings.ToLower(part)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// searchContext returns a message as the context of a search match.
+func (w *Workspace) searchContext(m slack.Message) *slack.SearchContextMessage {
+	context := &slack.SearchContextMessage{Type: "message", User: m.User, Timestamp: m.Timestamp, Text: m.Text}
+	if user, hasUser := w.Users[m.User]; hasUser {
+		context.Username = user.Name
+	}
+	return context
+}
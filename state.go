@@ -0,0 +1,430 @@
+package slack
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// NewState returns an empty state cache.
+func NewState() *State {
+	return &State{
+		users:    map[string]User{},
+		channels: map[string]Channel{},
+		groups:   map[string]Group{},
+		ims:      map[string]InstantMessage{},
+
+		userGroups: map[string]UserGroup{},
+		dnd:        map[string]DNDStatus{},
+	}
+}
+
+// State is a cache of the workspace the client is connected to.
+// It is loaded from the `rtm.start` session on connect and kept current from rtm events.
+type State struct {
+	lock     sync.RWMutex
+	self     *Self
+	team     *Team
+	users    map[string]User
+	channels map[string]Channel
+	groups   map[string]Group
+	ims      map[string]InstantMessage
+
+	userGroups map[string]UserGroup
+	dnd        map[string]DNDStatus
+	selfDND    *DNDStatus
+}
+
+// Self returns the bot user the client is connected as, or nil before connecting.
+func (s *State) Self() *Self {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.self
+}
+
+// Team returns the team the client is connected to, or nil before connecting.
+func (s *State) Team() *Team {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.team
+}
+
+// User returns a cached user.
+func (s *State) User(userID string) (User, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	user, hasUser := s.users[userID]
+	return user, hasUser
+}
+
+// Users returns every cached user.
+func (s *State) Users() []User {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	users := make([]User, 0, len(s.users))
+	for _, user := range s.users {
+		users = append(users, user)
+	}
+	return users
+}
+
+// Channel returns a cached channel.
+func (s *State) Channel(channelID string) (Channel, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	channel, hasChannel := s.channels[channelID]
+	return channel, hasChannel
+}
+
+// Channels returns every cached channel.
+func (s *State) Channels() []Channel {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	channels := make([]Channel, 0, len(s.channels))
+	for _, channel := range s.channels {
+		channels = append(channels, channel)
+	}
+	return channels
+}
+
+// Group returns a cached private channel.
+func (s *State) Group(groupID string) (Group, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	group, hasGroup := s.groups[groupID]
+	return group, hasGroup
+}
+
+// IM returns a cached direct message channel.
+func (s *State) IM(channelID string) (InstantMessage, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	im, hasIM := s.ims[channelID]
+	return im, hasIM
+}
+
+// IMForUser returns the cached direct message channel with a user.
+func (s *State) IMForUser(userID string) (InstantMessage, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	for _, im := range s.ims {
+		if im.User == userID {
+			return im, true
+		}
+	}
+	return InstantMessage{}, false
+}
+
+// UserName returns the name of a cached user, or an empty string.
+func (s *State) UserName(userID string) string {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.users[userID].Name
+}
+
+// ChannelName returns the name of a cached channel or private channel, or an empty string.
+func (s *State) ChannelName(channelID string) string {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	if channel, hasChannel := s.channels[channelID]; hasChannel {
+		return channel.Name
+	}
+	return s.groups[channelID].Name
+}
+
+// UserGroup returns a cached user group.
+func (s *State) UserGroup(userGroupID string) (UserGroup, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	userGroup, hasUserGroup := s.userGroups[userGroupID]
+	return userGroup, hasUserGroup
+}
+
+// UserGroupName returns the handle of a cached user group, or an empty string.
+func (s *State) UserGroupName(userGroupID string) string {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.userGroups[userGroupID].Handle
+}
+
+// SelfDND returns the do not disturb status of the bot user, if it's known.
+// It's set by `dnd_updated` events and the client's Dnd calls.
+func (s *State) SelfDND() (DNDStatus, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	if s.selfDND == nil {
+		return DNDStatus{}, false
+	}
+	return *s.selfDND, true
+}
+
+// DND returns the cached do not disturb status of a user.
+func (s *State) DND(userID string) (DNDStatus, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	if s.self != nil && s.self.ID == userID && s.selfDND != nil {
+		return *s.selfDND, true
+	}
+	status, hasStatus := s.dnd[userID]
+	return status, hasStatus
+}
+
+// Load replaces the cache with the contents of an `rtm.start` session.
+// User groups and do not disturb statuses aren't part of the session, so they're kept.
+func (s *State) Load(session *Session) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.self = session.Self
+	s.team = session.Team
+	s.users = map[string]User{}
+	for _, user := range session.Users {
+		s.users[user.ID] = user
+	}
+	s.channels = map[string]Channel{}
+	for _, channel := range session.Channels {
+		s.channels[channel.ID] = channel
+	}
+	s.groups = map[string]Group{}
+	for _, group := range session.Groups {
+		s.groups[group.ID] = group
+	}
+	s.ims = map[string]InstantMessage{}
+	for _, im := range session.IMs {
+		s.ims[im.ID] = im
+	}
+}
+
+// SetUser adds or replaces a cached user.
+func (s *State) SetUser(user User) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.users[user.ID] = user
+}
+
+// SetChannel adds or replaces a cached channel.
+func (s *State) SetChannel(channel Channel) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.channels[channel.ID] = channel
+}
+
+// SetGroup adds or replaces a cached private channel.
+func (s *State) SetGroup(group Group) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.groups[group.ID] = group
+}
+
+// SetIM adds or replaces a cached direct message channel.
+func (s *State) SetIM(im InstantMessage) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.ims[im.ID] = im
+}
+
+// SetUserGroups adds or replaces cached user groups, i.e. from UsergroupsList.
+func (s *State) SetUserGroups(userGroups ...UserGroup) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, userGroup := range userGroups {
+		s.userGroups[userGroup.ID] = userGroup
+	}
+}
+
+// SetDND sets the cached do not disturb status of a user.
+func (s *State) SetDND(userID string, status DNDStatus) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.self != nil && s.self.ID == userID {
+		s.selfDND = &status
+		return
+	}
+	s.dnd[userID] = status
+}
+
+// setSelfDND changes the cached do not disturb status of the bot user.
+func (s *State) setSelfDND(change func(*DNDStatus)) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.selfDND == nil {
+		s.selfDND = &DNDStatus{}
+	}
+	change(s.selfDND)
+}
+
+// RemoveChannel removes a cached channel.
+func (s *State) RemoveChannel(channelID string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.channels, channelID)
+}
+
+// RemoveGroup removes a cached private channel.
+func (s *State) RemoveGroup(groupID string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.groups, groupID)
+}
+
+// stateEvent is the shape of the events that carry objects instead of ids.
+type stateEvent struct {
+	Type    Event           `json:"type"`
+	User    json.RawMessage `json:"user"`
+	Channel json.RawMessage `json:"channel"`
+}
+
+// update applies an rtm frame to the cache.
+func (s *State) update(eventType Event, frame []byte) error {
+	switch eventType {
+	case EventUserChange, EventTeamJoin:
+		var event stateEvent
+		if err := json.Unmarshal(frame, &event); err != nil {
+			return err
+		}
+		var user User
+		if err := json.Unmarshal(event.User, &user); err != nil {
+			return err
+		}
+		s.SetUser(user)
+	case EventChannelCreated, EventChannelRename:
+		var event stateEvent
+		if err := json.Unmarshal(frame, &event); err != nil {
+			return err
+		}
+		var update Channel
+		if err := json.Unmarshal(event.Channel, &update); err != nil {
+			return err
+		}
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		if channel, hasChannel := s.channels[update.ID]; hasChannel {
+			channel.Name = update.Name
+			s.channels[update.ID] = channel
+		} else {
+			s.channels[update.ID] = update
+		}
+	case EventIMCreated:
+		var event stateEvent
+		if err := json.Unmarshal(frame, &event); err != nil {
+			return err
+		}
+		var im InstantMessage
+		if err := json.Unmarshal(event.Channel, &im); err != nil {
+			return err
+		}
+		if IsEmpty(im.User) && len(event.User) > 0 {
+			if err := json.Unmarshal(event.User, &im.User); err != nil {
+				return err
+			}
+		}
+		s.SetIM(im)
+	case EventGroupJoined:
+		var event stateEvent
+		if err := json.Unmarshal(frame, &event); err != nil {
+			return err
+		}
+		var group Group
+		if err := json.Unmarshal(event.Channel, &group); err != nil {
+			return err
+		}
+		s.SetGroup(group)
+	case EventGroupRename:
+		var event stateEvent
+		if err := json.Unmarshal(frame, &event); err != nil {
+			return err
+		}
+		var update Group
+		if err := json.Unmarshal(event.Channel, &update); err != nil {
+			return err
+		}
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		if group, hasGroup := s.groups[update.ID]; hasGroup {
+			group.Name = update.Name
+			s.groups[update.ID] = group
+		}
+	case EventGroupLeft:
+		var m Message
+		if err := json.Unmarshal(frame, &m); err != nil {
+			return err
+		}
+		s.RemoveGroup(m.Channel)
+	case EventGroupArchive, EventGroupUnarchive:
+		var m Message
+		if err := json.Unmarshal(frame, &m); err != nil {
+			return err
+		}
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		if group, hasGroup := s.groups[m.Channel]; hasGroup {
+			group.IsArchived = eventType == EventGroupArchive
+			s.groups[m.Channel] = group
+		}
+	case EventChannelDeleted:
+		var m Message
+		if err := json.Unmarshal(frame, &m); err != nil {
+			return err
+		}
+		s.RemoveChannel(m.Channel)
+	case EventSubteamCreated, EventSubteamUpdated:
+		var m Message
+		if err := json.Unmarshal(frame, &m); err != nil {
+			return err
+		}
+		if m.Subteam != nil {
+			s.SetUserGroups(*m.Subteam)
+		}
+	case EventSubteamMembersChanged:
+		var m Message
+		if err := json.Unmarshal(frame, &m); err != nil {
+			return err
+		}
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		if userGroup, hasUserGroup := s.userGroups[m.SubteamID]; hasUserGroup {
+			var users []string
+			for _, userID := range userGroup.Users {
+				if !containsString(m.RemovedUsers, userID) {
+					users = append(users, userID)
+				}
+			}
+			for _, userID := range m.AddedUsers {
+				if !containsString(users, userID) {
+					users = append(users, userID)
+				}
+			}
+			userGroup.Users = users
+			s.userGroups[m.SubteamID] = userGroup
+		}
+	case EventDNDUpdated:
+		var m Message
+		if err := json.Unmarshal(frame, &m); err != nil {
+			return err
+		}
+		if m.DNDStatus != nil {
+			s.setSelfDND(func(cached *DNDStatus) {
+				*cached = *m.DNDStatus
+			})
+		}
+	case EventDNDUpdatedUser:
+		var m Message
+		if err := json.Unmarshal(frame, &m); err != nil {
+			return err
+		}
+		if m.DNDStatus != nil {
+			s.SetDND(m.User, *m.DNDStatus)
+		}
+	case EventChannelArchive, EventChannelUnArchive:
+		var m Message
+		if err := json.Unmarshal(frame, &m); err != nil {
+			return err
+		}
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		if channel, hasChannel := s.channels[m.Channel]; hasChannel {
+			channel.IsArchived = eventType == EventChannelArchive
+			s.channels[m.Channel] = channel
+		}
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package slack
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blendlabs/go-assert"
+)
+
+func TestStateLoad(t *testing.T) {
+	a := assert.New(t)
+
+	s := NewState()
+	s.Load(&Session{
+		Self:     &Self{ID: "U0BOT", Name: "bot"},
+		Team:     &Team{ID: "T0TEST"},
+		Users:    []User{{ID: "U1", Name: "bob"}},
+		Channels: []Channel{{ID: "C1", Name: "general"}},
+		Groups:   []Group{{ID: "G1", Name: "secret"}},
+	})
+
+	a.Equal("U0BOT", s.Self().ID)
+	a.Equal("T0TEST", s.Team().ID)
+	a.Equal("bob", s.UserName("U1"))
+	a.Empty(s.UserName("U2"))
+	a.Equal("general", s.ChannelName("C1"))
+	a.Equal("secret", s.ChannelName("G1"))
+	a.Len(s.Users(), 1)
+	a.Len(s.Channels(), 1)
+}
+
+func TestStateUpdate(t *testing.T) {
+	a := assert.New(t)
+
+	c := NewClient("test_token")
+	c.SetDebug(false)
+	c.State().Load(&Session{Channels: []Channel{{ID: "C1", Name: "general", IsMember: true}}})
+
+	c.handleFrame([]byte(`{"type":"team_join","user":{"id":"U1","name":"bob"}}`))
+	c.handleFrame([]byte(`{"type":"user_change","user":{"id":"U1","name":"robert"}}`))
+	c.handleFrame([]byte(`{"type":"channel_created","channel":{"id":"C2","name":"random","created":1360782804,"creator":"U1"}}`))
+	c.handleFrame([]byte(`{"type":"channel_rename","channel":{"id":"C1","name":"everyone","created":1360782804}}`))
+	c.handleFrame([]byte(`{"type":"channel_archive","channel":"C2","user":"U1"}`))
+	c.WaitForListeners()
+
+	a.Equal("robert", c.State().UserName("U1"))
+	a.Equal("everyone", c.State().ChannelName("C1"))
+	channel, hasChannel := c.State().Channel("C1")
+	a.True(hasChannel)
+	a.True(channel.IsMember)
+	channel, _ = c.State().Channel("C2")
+	a.True(channel.IsArchived)
+
+	c.handleFrame([]byte(`{"type":"channel_deleted","channel":"C2"}`))
+	c.WaitForListeners()
+	_, hasChannel = c.State().Channel("C2")
+	a.False(hasChannel)
+
+	a.Equal("@robert renamed #everyone", c.PlainText("<@U1> renamed <#C1>"))
+}
+
+func TestStateUserGroups(t *testing.T) {
+	a := assert.New(t)
+
+	c := NewClient("test_token")
+	c.SetDebug(false)
+	c.State().SetUserGroups(UserGroup{ID: "S1", Handle: "oncall", Users: []string{"U1", "U2"}})
+
+	c.handleFrame([]byte(`{"type":"subteam_created","subteam":{"id":"S2","team_id":"T0TEST","is_usergroup":true,"name":"Database Admins","handle":"dba","date_create":1446746793,"date_update":1446746793,"date_delete":0,"created_by":"U1","user_count":"0"}}`))
+	c.handleFrame([]byte(`{"type":"subteam_members_changed","subteam_id":"S1","team_id":"T0TEST","date_previous_update":1446670362,"date_update":1492906952,"added_users":["U3"],"added_users_count":"1","removed_users":["U1"],"removed_users_count":"1"}`))
+	c.WaitForListeners()
+
+	userGroup, hasUserGroup := c.State().UserGroup("S1")
+	a.True(hasUserGroup)
+	a.Equal([]string{"U2", "U3"}, userGroup.Users)
+	userGroup, _ = c.State().UserGroup("S2")
+	a.True(userGroup.IsEnabled())
+	a.Equal("0", userGroup.UserCount.String())
+
+	c.State().Load(&Session{Users: []User{{ID: "U1", Name: "bob"}}})
+	a.Equal("@bob paging @dba", c.PlainText("<@U1> paging <!subteam^S2>"))
+}
+
+func TestStateDND(t *testing.T) {
+	a := assert.New(t)
+
+	c := NewClient("test_token")
+	c.SetDebug(false)
+	c.State().Load(&Session{Self: &Self{ID: "U0BOT", Name: "bot"}})
+
+	c.handleFrame([]byte(`{"type":"dnd_updated","user":"U0BOT","dnd_status":{"dnd_enabled":true,"next_dnd_start_ts":1450416600,"next_dnd_end_ts":1450452600,"snooze_enabled":true,"snooze_endtime":1450416600}}`))
+	c.handleFrame([]byte(`{"type":"dnd_updated_user","user":"U1","dnd_status":{"dnd_enabled":true,"next_dnd_start_ts":1450416600,"next_dnd_end_ts":1450452600}}`))
+	c.WaitForListeners()
+
+	status, hasDND := c.State().SelfDND()
+	a.True(hasDND)
+	a.True(status.SnoozeEnabled)
+	status, hasDND = c.State().DND("U0BOT")
+	a.True(hasDND)
+	a.True(status.SnoozeEnabled)
+	status, hasDND = c.State().DND("U1")
+	a.True(hasDND)
+	a.False(status.SnoozeEnabled)
+	a.True(status.IsActive(time.Unix(1450416600, 0)))
+	_, hasDND = c.State().DND("U2")
+	a.False(hasDND)
+}
+
+func TestStateConversations(t *testing.T) {
+	a := assert.New(t)
+
+	c := NewClient("test_token")
+	c.SetDebug(false)
+	c.State().Load(&Session{
+		Groups: []Group{{ID: "G1", Name: "secret"}},
+		IMs:    []InstantMessage{{ID: "D1", User: "U1", IsIM: true}},
+	})
+
+	c.handleFrame([]byte(`{"type":"im_created","user":"U2","channel":{"id":"D2","is_im":true,"created":1360782804}}`))
+	c.handleFrame([]byte(`{"type":"group_joined","channel":{"id":"G2","name":"plans","is_group":true,"created":1360782804}}`))
+	c.handleFrame([]byte(`{"type":"group_rename","channel":{"id":"G1","name":"classified","created":1360782804}}`))
+	c.handleFrame([]byte(`{"type":"group_archive","channel":"G2","user":"U1"}`))
+	c.WaitForListeners()
+
+	im, hasIM := c.State().IMForUser("U1")
+	a.True(hasIM)
+	a.Equal("D1", im.ID)
+	im, hasIM = c.State().IM("D2")
+	a.True(hasIM)
+	a.Equal("U2", im.User)
+	_, hasIM = c.State().IMForUser("U3")
+	a.False(hasIM)
+
+	a.Equal("classified", c.State().ChannelName("G1"))
+	group, hasGroup := c.State().Group("G2")
+	a.True(hasGroup)
+	a.True(group.IsArchived)
+
+	c.handleFrame([]byte(`{"type":"group_left","channel":"G2"}`))
+	c.WaitForListeners()
+	_, hasGroup = c.State().Group("G2")
+	a.False(hasGroup)
+}
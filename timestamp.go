@@ -13,6 +13,11 @@ type Timestamp struct {
 	uuid string
 }
 
+// NewTimestamp returns a Timestamp for a given time.
+func NewTimestamp(t time.Time) Timestamp {
+	return Timestamp{time: t}
+}
+
 func (t Timestamp) String() string {
 	if len(t.uuid) != 0 {
 		return fmt.Sprintf("%d.%s", t.time.Unix(), t.uuid)
@@ -39,9 +44,13 @@ func (t *Timestamp) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
-// MarshalJSON returns the object as json.
+// MarshalJSON returns the object as json, the way slack sends it: message timestamps (with a uuid portion)
+// are strings, so the uuid isn't mangled as a float, and unix times (i.e. `created`) are integers.
 func (t Timestamp) MarshalJSON() ([]byte, error) {
-	return []byte(t.String()), nil
+	if len(t.uuid) == 0 {
+		return []byte(t.String()), nil
+	}
+	return []byte(strconv.Quote(t.String())), nil
 }
 
 // Time returns a regular golang time.Time for the Timestamp instance.
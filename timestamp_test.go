@@ -3,6 +3,7 @@ package slack
 import (
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/blendlabs/go-assert"
 )
@@ -37,3 +38,27 @@ func TestTimestampUnmarshal(t *testing.T) {
 	a.NotNil(m.Timestamp)
 	a.Equal("1456540738.000017", m.Timestamp.String())
 }
+
+func TestTimestampMarshal(t *testing.T) {
+	a := assert.New(t)
+
+	m := Message{Type: EventMessage, Channel: "C1", Text: "reply"}
+	ts := &Timestamp{}
+	a.Nil(ts.UnmarshalJSON([]byte(`"1456540738.000017"`)))
+	m.ThreadTimestamp = ts
+
+	contents, err := json.Marshal(m)
+	a.Nil(err)
+
+	var verify Message
+	a.Nil(json.Unmarshal(contents, &verify))
+	a.Equal("1456540738.000017", verify.ThreadTimestamp.String())
+
+	// unix times are integers, like slack sends them.
+	contents, err = json.Marshal(NewTimestamp(time.Unix(1449252889, 0)))
+	a.Nil(err)
+	a.Equal("1449252889", string(contents))
+	contents, err = json.Marshal(ts)
+	a.Nil(err)
+	a.Equal(`"1456540738.000017"`, string(contents))
+}
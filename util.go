@@ -86,3 +86,18 @@ func OptionalTimestamp(value Timestamp) *Timestamp {
 func IsEmpty(s string) bool {
 	return len(s) == 0
 }
+
+// IsAPIError returns if an error from a web api call is a given slack error code, i.e. `ErrorAlreadyReacted`.
+func IsAPIError(err error, code string) bool {
+	return err != nil && err.Error() == code
+}
+
+// containsString returns if a slice contains a value.
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package slack
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	exception "github.com/blendlabs/go-exception"
+	request "github.com/blendlabs/go-request"
+)
+
+// NewWebhook returns a new Webhook for a given incoming webhook url.
+func NewWebhook(url string) *Webhook {
+	return &Webhook{
+		URL:         url,
+		RetryPolicy: DefaultRetryPolicy(),
+	}
+}
+
+// Webhook posts messages to a Slack incoming webhook.
+// It is useful for services that only need to send notifications and don't have a bot token.
+type Webhook struct {
+	// URL is the incoming webhook url, i.e. `https://hooks.slack.com/services/T000/B000/XXXX`.
+	URL string
+
+	// RetryPolicy governs how rate limited posts are retried.
+	RetryPolicy RetryPolicy
+}
+
+// Say posts a basic text message to the webhook.
+func (wh *Webhook) Say(messageComponents ...interface{}) error {
+	return wh.Post(&ChatMessage{Text: fmt.Sprint(messageComponents...)})
+}
+
+// Sayf is an overload that uses Printf style replacements for a basic message to the webhook.
+func (wh *Webhook) Sayf(format string, messageComponents ...interface{}) error {
+	return wh.Post(&ChatMessage{Text: fmt.Sprintf(format, messageComponents...)})
+}
+
+// Post posts a chat message to the webhook.
+// The `Channel`, `Username`, `IconURL` and `IconEmoji` fields override the webhook defaults if they're set.
+func (wh *Webhook) Post(m *ChatMessage) error {
+	if m == nil {
+		return exception.New("`m` cannot be nil.")
+	}
+	if IsEmpty(wh.URL) {
+		return exception.New("webhook `URL` cannot be empty.")
+	}
+
+	var body string
+	meta, err := wh.RetryPolicy.Do(func() (*request.ResponseMeta, error) {
+		var meta *request.ResponseMeta
+		var err error
+		body, meta, err = NewExternalRequest().
+			AsPost().
+			WithURL(wh.URL).
+			WithPostBodyAsJSON(m).
+			StringWithMeta()
+		return meta, err
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if IsRateLimited(meta) {
+		return &WebhookError{StatusCode: meta.StatusCode, Code: ErrorRateLimited}
+	}
+
+	if meta.StatusCode != http.StatusOK {
+		return &WebhookError{StatusCode: meta.StatusCode, Code: strings.TrimSpace(body)}
+	}
+	return nil
+}
+
+// WebhookError is an error returned by an incoming webhook.
+// Unlike the web api, webhooks respond with a non-200 status code and a plain text error code.
+type WebhookError struct {
+	StatusCode int
+	Code       string
+}
+
+// Error implements error.
+func (we *WebhookError) Error() string {
+	return fmt.Sprintf("slack webhook error (%d): %s", we.StatusCode, we.Code)
+}
+
+// IsWebhookError returns if an error is a WebhookError with a given code, i.e. `ErrorChannelIsArchived`.
+func IsWebhookError(err error, code string) bool {
+	if typed, isTyped := err.(*WebhookError); isTyped {
+		return typed.Code == code
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package slack
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/blendlabs/go-assert"
+	request "github.com/blendlabs/go-request"
+)
+
+const testWebhookURL = "https://hooks.slack.com/services/TTEST/BTEST/XXXX"
+
+func TestWebhookPost(t *testing.T) {
+	a := assert.New(t)
+	defer ClearMockedResponses()
+	MockResponseFromFile("POST", testWebhookURL, 200, "testdata/webhook.ok.txt")
+
+	wh := NewWebhook(testWebhookURL)
+	a.Nil(wh.Post(&ChatMessage{Text: "test", Username: OptionalString("test-bot")}))
+	a.Nil(wh.Sayf("test %d", 1))
+}
+
+func TestWebhookPostErrors(t *testing.T) {
+	a := assert.New(t)
+	defer ClearMockedResponses()
+
+	wh := NewWebhook(testWebhookURL)
+	a.NotNil(wh.Post(nil))
+
+	MockResponseFromFile("POST", testWebhookURL, 400, "testdata/webhook.invalid_payload.txt")
+	err := wh.Say("test")
+	a.NotNil(err)
+	a.True(IsWebhookError(err, ErrorInvalidPayload))
+	a.Equal(400, err.(*WebhookError).StatusCode)
+
+	MockResponseFromFile("POST", testWebhookURL, 410, "testdata/webhook.channel_is_archived.txt")
+	err = wh.Say("test")
+	a.True(IsWebhookError(err, ErrorChannelIsArchived))
+	a.False(IsWebhookError(err, ErrorNoText))
+}
+
+func TestRetryPolicyDo(t *testing.T) {
+	a := assert.New(t)
+
+	var slept []time.Duration
+	rp := DefaultRetryPolicy()
+	rp.sleep = func(d time.Duration) { slept = append(slept, d) }
+
+	attempts := 0
+	meta, err := rp.Do(func() (*request.ResponseMeta, error) {
+		attempts++
+		if attempts < 2 {
+			return &request.ResponseMeta{StatusCode: http.StatusTooManyRequests, Headers: http.Header{"Retry-After": []string{"2"}}}, nil
+		}
+		return &request.ResponseMeta{StatusCode: http.StatusOK}, nil
+	})
+	a.Nil(err)
+	a.Equal(http.StatusOK, meta.StatusCode)
+	a.Equal(2, attempts)
+	a.Equal([]time.Duration{2 * time.Second}, slept)
+
+	attempts = 0
+	meta, err = rp.Do(func() (*request.ResponseMeta, error) {
+		attempts++
+		return &request.ResponseMeta{StatusCode: http.StatusTooManyRequests}, nil
+	})
+	a.Nil(err)
+	a.True(IsRateLimited(meta))
+	a.Equal(DefaultRetryMaxAttempts, attempts)
+}